@@ -0,0 +1,192 @@
+package apperror
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultLang dipakai jika Accept-Language kosong atau tidak ada bahasa yang didukung
+const DefaultLang = "id"
+
+// catalogs: bahasa -> kode -> pesan. Kode baru wajib ditambahkan di kedua bahasa.
+var catalogs = map[string]map[Code]string{
+	"id": {
+		CodeBadRequest:         "Permintaan tidak valid",
+		CodeInvalidBody:        "Format body request tidak valid",
+		CodeInvalidQuery:       "Parameter query tidak valid",
+		CodeInvalidID:          "ID tidak valid",
+		CodeValidation:         "Data yang dikirim tidak valid",
+		CodeNotFound:           "Data tidak ditemukan",
+		CodeMethodNotAllowed:   "Method tidak diizinkan",
+		CodeConflict:           "Data bertabrakan dengan data yang sudah ada",
+		CodePayloadTooLarge:    "Body request terlalu besar",
+		CodeUnsupportedMedia:   "Content-Type tidak didukung",
+		CodeTooManyRequests:    "Terlalu banyak permintaan, coba lagi nanti",
+		CodeInternal:           "Terjadi kesalahan pada server",
+		CodeServiceUnavailable: "Layanan sedang tidak tersedia",
+
+		CodePreconditionFailed:   "Data sudah diubah oleh pihak lain, muat ulang lalu coba lagi",
+		CodePreconditionRequired: "Header If-Match wajib dikirim untuk mengubah data ini",
+
+		CodeUnauthorized:          "Silakan login terlebih dahulu",
+		CodeTokenMissing:          "Token tidak ditemukan",
+		CodeTokenMalformed:        "Format token tidak valid",
+		CodeTokenInvalid:          "Token tidak valid atau sudah kedaluwarsa",
+		CodeTokenRevoked:          "Token sudah dicabut",
+		CodeSessionRevoked:        "Sesi sudah dicabut, silakan login ulang",
+		CodeForbidden:             "Akses ditolak: Anda tidak diizinkan mengakses resource ini",
+		CodeEmailNotVerified:      "Email belum diverifikasi",
+		CodeVerifiedLoginRequired: "Silakan login dengan akun yang emailnya sudah diverifikasi",
+		CodeMFARequired:           "Masjid ini mewajibkan 2FA untuk pengurus. Aktifkan 2FA lalu login ulang",
+		CodeInvalidCredentials:    "Email, username, atau password salah",
+
+		CodeUserNotFound:    "User tidak ditemukan",
+		CodeProfileNotFound: "Profil user tidak ditemukan",
+		CodeMasjidRequired:  "Masjid tidak disebutkan di URL",
+		CodeMasjidNotFound:  "Masjid tidak ditemukan",
+		CodeEmailTaken:      "Email sudah terdaftar",
+
+		CodeAccountNotRestorable: "Akun tidak bisa dipulihkan karena masa tenggang penghapusan sudah lewat",
+		CodeAccountSuspended:     "Akun Anda sedang disuspend",
+		CodeAccountBanned:        "Akun Anda diblokir",
+		CodeCannotModerateSelf:   "Tidak bisa mengubah role atau memblokir akun sendiri",
+		CodeRoleNotFound:         "Role tidak ditemukan",
+
+		CodeImpersonationForbidden: "Aksi ini tidak bisa dilakukan saat login sebagai user lain",
+		CodeCannotImpersonate:      "User ini tidak bisa di-impersonate",
+
+		CodeTokenReused:              "Refresh token sudah pernah dipakai, silakan login ulang",
+		CodeSessionNotFound:          "Sesi tidak ditemukan",
+		CodeSessionUnknown:           "Sesi saat ini tidak dikenali, silakan login ulang",
+		CodeInvalidPassword:          "Password salah",
+		CodePasswordUnchanged:        "Password baru harus berbeda dari password lama",
+		CodeResetTokenInvalid:        "Token reset password tidak valid atau sudah kedaluwarsa",
+		CodeVerificationTokenInvalid: "Token verifikasi tidak valid atau sudah kedaluwarsa",
+		CodeLoginCodeInvalid:         "Kode login tidak valid atau sudah kedaluwarsa",
+		CodeMFAChallengeInvalid:      "Sesi verifikasi 2FA tidak valid atau sudah kedaluwarsa, silakan login ulang",
+		CodeMFACodeInvalid:           "Kode autentikasi salah",
+		CodeMFAAlreadyEnabled:        "2FA sudah aktif",
+		CodeMFASetupRequired:         "Mulai setup 2FA terlebih dahulu",
+
+		CodeMemberNotFound:     "Anggota masjid tidak ditemukan",
+		CodeInvitationNotFound: "Undangan tidak ditemukan",
+		CodeAlreadyMember:      "User sudah menjadi anggota masjid ini",
+		CodeAlreadyInvited:     "User sudah diundang ke masjid ini",
+		CodeInvalidMasjidRole:  "Role tidak dikenal atau tidak bisa diberikan kepada anggota masjid",
+		CodeLastOwner:          "Masjid harus memiliki minimal satu owner",
+
+		CodePrayerTimesUnavailable: "Jadwal sholat tidak bisa dihitung untuk masjid ini",
+
+		CodeDonationNotFound: "Donasi tidak ditemukan",
+		CodeInvalidSignature: "Signature tidak valid",
+		CodeAmountMismatch:   "Nominal pembayaran tidak sesuai dengan donasi",
+		CodePaymentGateway:   "Gagal membuat transaksi pembayaran, coba lagi nanti",
+	},
+	"en": {
+		CodeBadRequest:         "Bad request",
+		CodeInvalidBody:        "Invalid request body",
+		CodeInvalidQuery:       "Invalid query parameter",
+		CodeInvalidID:          "Invalid ID",
+		CodeValidation:         "Validation failed",
+		CodeNotFound:           "Resource not found",
+		CodeMethodNotAllowed:   "Method not allowed",
+		CodeConflict:           "Conflict with existing data",
+		CodePayloadTooLarge:    "Request body too large",
+		CodeUnsupportedMedia:   "Unsupported Content-Type",
+		CodeTooManyRequests:    "Too many requests, try again later",
+		CodeInternal:           "Internal Server Error",
+		CodeServiceUnavailable: "Service unavailable",
+
+		CodePreconditionFailed:   "The resource has been modified, reload it and try again",
+		CodePreconditionRequired: "The If-Match header is required to modify this resource",
+
+		CodeUnauthorized:          "Unauthorized",
+		CodeTokenMissing:          "Unauthorized - No token provided",
+		CodeTokenMalformed:        "Unauthorized - Invalid token format",
+		CodeTokenInvalid:          "Unauthorized - Invalid token",
+		CodeTokenRevoked:          "Unauthorized - Token has been revoked",
+		CodeSessionRevoked:        "Unauthorized - Session has been revoked",
+		CodeForbidden:             "Access denied: you are not allowed to access this resource",
+		CodeEmailNotVerified:      "Email has not been verified",
+		CodeVerifiedLoginRequired: "Please log in with an account whose email has been verified",
+		CodeMFARequired:           "This masjid requires 2FA for its staff. Enable 2FA and log in again",
+		CodeInvalidCredentials:    "Invalid email, username, or password",
+
+		CodeUserNotFound:    "User not found",
+		CodeProfileNotFound: "User profile not found",
+		CodeMasjidRequired:  "Masjid is not specified in the URL",
+		CodeMasjidNotFound:  "Masjid not found",
+		CodeEmailTaken:      "Email already registered",
+
+		CodeAccountNotRestorable: "The account can no longer be restored because its deletion grace period has ended",
+		CodeAccountSuspended:     "Your account is suspended",
+		CodeAccountBanned:        "Your account has been banned",
+		CodeCannotModerateSelf:   "You cannot change the role of or block your own account",
+		CodeRoleNotFound:         "Role not found",
+
+		CodeImpersonationForbidden: "This action is not allowed while impersonating another user",
+		CodeCannotImpersonate:      "This user cannot be impersonated",
+
+		CodeTokenReused:              "Refresh token reuse detected, please log in again",
+		CodeSessionNotFound:          "Session not found",
+		CodeSessionUnknown:           "Current session is unknown, please log in again",
+		CodeInvalidPassword:          "Invalid password",
+		CodePasswordUnchanged:        "New password must be different from old password",
+		CodeResetTokenInvalid:        "Invalid or expired password reset token",
+		CodeVerificationTokenInvalid: "Invalid or expired verification token",
+		CodeLoginCodeInvalid:         "Invalid or expired login code",
+		CodeMFAChallengeInvalid:      "Invalid or expired MFA challenge, please log in again",
+		CodeMFACodeInvalid:           "Invalid authentication code",
+		CodeMFAAlreadyEnabled:        "Two-factor authentication is already enabled",
+		CodeMFASetupRequired:         "Start the two-factor authentication setup first",
+
+		CodeMemberNotFound:     "Member not found",
+		CodeInvitationNotFound: "Invitation not found",
+		CodeAlreadyMember:      "User is already a member of this masjid",
+		CodeAlreadyInvited:     "User has already been invited to this masjid",
+		CodeInvalidMasjidRole:  "Unknown role or role cannot be assigned to masjid members",
+		CodeLastOwner:          "A masjid must have at least one owner",
+
+		CodePrayerTimesUnavailable: "Prayer times cannot be calculated for this masjid",
+
+		CodeDonationNotFound: "Donation not found",
+		CodeInvalidSignature: "Invalid signature",
+		CodeAmountMismatch:   "Gross amount does not match the donation",
+		CodePaymentGateway:   "Failed to create payment transaction, try again later",
+	},
+}
+
+// Message mengembalikan pesan kode dalam bahasa lang (fallback: DefaultLang, lalu teks status HTTP)
+func Message(lang string, code Code, status int) string {
+	if msg, ok := catalogs[lang][code]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[DefaultLang][code]; ok {
+		return msg
+	}
+	return http.StatusText(status)
+}
+
+// Lang memilih bahasa dari Accept-Language (mis. "en-US,en;q=0.9,id;q=0.8" -> "en").
+// Tag regional dicocokkan ke bahasa dasarnya; bobot q tertinggi yang didukung menang.
+func Lang(c *fiber.Ctx) string {
+	best, bestQ := DefaultLang, 0.0
+	for _, part := range strings.Split(c.Get(fiber.HeaderAcceptLanguage), ",") {
+		tag, q := strings.TrimSpace(part), 1.0
+		if i := strings.IndexByte(tag, ';'); i >= 0 {
+			param := strings.TrimSpace(tag[i+1:])
+			if v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				q = v
+			}
+			tag = strings.TrimSpace(tag[:i])
+		}
+		base := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := catalogs[base]; ok && q > bestQ {
+			best, bestQ = base, q
+		}
+	}
+	return best
+}
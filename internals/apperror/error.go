@@ -0,0 +1,227 @@
+// Package apperror adalah error aplikasi bertipe: setiap error membawa kode yang stabil
+// (untuk client), HTTP status dan detail per field. Handler cukup `return apperror.X(...)`;
+// ErrorHandler mengubahnya menjadi response RFC 7807 (application/problem+json) dengan
+// pesan dari katalog bahasa sesuai Accept-Language (id / en).
+package apperror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Code adalah kode error yang stabil; client memakai ini, bukan teks pesan
+type Code string
+
+const (
+	// Umum
+	CodeBadRequest         Code = "bad_request"
+	CodeInvalidBody        Code = "invalid_body"
+	CodeInvalidQuery       Code = "invalid_query"
+	CodeInvalidID          Code = "invalid_id"
+	CodeValidation         Code = "validation_failed"
+	CodeNotFound           Code = "not_found"
+	CodeMethodNotAllowed   Code = "method_not_allowed"
+	CodeConflict           Code = "conflict"
+	CodePayloadTooLarge    Code = "payload_too_large"
+	CodeUnsupportedMedia   Code = "unsupported_media_type"
+	CodeTooManyRequests    Code = "too_many_requests"
+	CodeInternal           Code = "internal_error"
+	CodeServiceUnavailable Code = "service_unavailable"
+
+	// Conditional request (ETag / If-Match)
+	CodePreconditionFailed   Code = "precondition_failed"
+	CodePreconditionRequired Code = "precondition_required"
+
+	// Autentikasi & otorisasi
+	CodeUnauthorized          Code = "unauthorized"
+	CodeTokenMissing          Code = "token_missing"
+	CodeTokenMalformed        Code = "token_malformed"
+	CodeTokenInvalid          Code = "token_invalid"
+	CodeTokenRevoked          Code = "token_revoked"
+	CodeSessionRevoked        Code = "session_revoked"
+	CodeForbidden             Code = "forbidden"
+	CodeEmailNotVerified      Code = "email_not_verified"
+	CodeVerifiedLoginRequired Code = "verified_login_required"
+	CodeMFARequired           Code = "mfa_required"
+	CodeInvalidCredentials    Code = "invalid_credentials"
+
+	// Resource
+	CodeUserNotFound    Code = "user_not_found"
+	CodeProfileNotFound Code = "profile_not_found"
+	CodeMasjidRequired  Code = "masjid_required"
+	CodeMasjidNotFound  Code = "masjid_not_found"
+	CodeEmailTaken      Code = "email_taken"
+
+	// Siklus akun (hapus / pulihkan)
+	CodeAccountNotRestorable Code = "account_not_restorable"
+	CodeAccountSuspended     Code = "account_suspended"
+	CodeAccountBanned        Code = "account_banned"
+	CodeCannotModerateSelf   Code = "cannot_moderate_self"
+	CodeRoleNotFound         Code = "role_not_found"
+
+	// Impersonation (admin login sebagai user)
+	CodeImpersonationForbidden Code = "impersonation_forbidden"
+	CodeCannotImpersonate      Code = "cannot_impersonate"
+
+	// Login, sesi & 2FA
+	CodeTokenReused              Code = "token_reused"
+	CodeSessionNotFound          Code = "session_not_found"
+	CodeSessionUnknown           Code = "session_unknown"
+	CodeInvalidPassword          Code = "invalid_password"
+	CodePasswordUnchanged        Code = "password_unchanged"
+	CodeResetTokenInvalid        Code = "reset_token_invalid"
+	CodeVerificationTokenInvalid Code = "verification_token_invalid"
+	CodeLoginCodeInvalid         Code = "login_code_invalid"
+	CodeMFAChallengeInvalid      Code = "mfa_challenge_invalid"
+	CodeMFACodeInvalid           Code = "mfa_code_invalid"
+	CodeMFAAlreadyEnabled        Code = "mfa_already_enabled"
+	CodeMFASetupRequired         Code = "mfa_setup_required"
+
+	// Masjid & pengurus
+	CodeMemberNotFound     Code = "member_not_found"
+	CodeInvitationNotFound Code = "invitation_not_found"
+	CodeAlreadyMember      Code = "already_member"
+	CodeAlreadyInvited     Code = "already_invited"
+	CodeInvalidMasjidRole  Code = "invalid_masjid_role"
+	CodeLastOwner          Code = "last_owner"
+
+	// Jadwal sholat
+	CodePrayerTimesUnavailable Code = "prayer_times_unavailable"
+
+	// Donasi & pembayaran
+	CodeDonationNotFound Code = "donation_not_found"
+	CodeInvalidSignature Code = "invalid_signature"
+	CodeAmountMismatch   Code = "amount_mismatch"
+	CodePaymentGateway   Code = "payment_gateway_error"
+)
+
+// FieldError adalah kesalahan pada satu field input
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"` // tag validator (required, email, min, ...) atau kode bebas
+	Message string `json:"message"`
+}
+
+// Error adalah error aplikasi. Err (penyebab internal) hanya dicatat di log, tidak pernah dikirim ke client.
+type Error struct {
+	Status int
+	Code   Code
+	Detail string                 // pesan spesifik; kosong = pesan katalog untuk Code
+	Fields []FieldError           // kesalahan per field (selain hasil validator)
+	Extra  map[string]interface{} // member tambahan problem+json, mis. retry_after
+	Err    error
+
+	validation validator.ValidationErrors // diterjemahkan saat render sesuai bahasa request
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New membuat error dengan status dan kode tertentu
+func New(status int, code Code) *Error {
+	return &Error{Status: status, Code: code}
+}
+
+func BadRequest(code Code) *Error      { return New(fiber.StatusBadRequest, code) }
+func Unauthorized(code Code) *Error    { return New(fiber.StatusUnauthorized, code) }
+func Forbidden(code Code) *Error       { return New(fiber.StatusForbidden, code) }
+func NotFound(code Code) *Error        { return New(fiber.StatusNotFound, code) }
+func Conflict(code Code) *Error        { return New(fiber.StatusConflict, code) }
+func TooManyRequests(code Code) *Error { return New(fiber.StatusTooManyRequests, code) }
+
+// Internal membungkus error tak terduga (500); detailnya hanya masuk log
+func Internal(err error) *Error {
+	return &Error{Status: fiber.StatusInternalServerError, Code: CodeInternal, Err: err}
+}
+
+// WithDetail mengganti pesan katalog dengan pesan yang lebih spesifik
+func (e *Error) WithDetail(format string, args ...interface{}) *Error {
+	e.Detail = fmt.Sprintf(format, args...)
+	return e
+}
+
+// WithCause menyimpan penyebab internal (untuk log & errors.Is / errors.As)
+func (e *Error) WithCause(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// WithField menambahkan kesalahan pada satu field
+func (e *Error) WithField(field, code, message string) *Error {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+	return e
+}
+
+// With menambahkan member tambahan pada body problem+json
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Extra == nil {
+		e.Extra = map[string]interface{}{}
+	}
+	e.Extra[key] = value
+	return e
+}
+
+// From mengubah error apa pun menjadi *Error: *Error apa adanya, *fiber.Error sesuai status,
+// validator.ValidationErrors menjadi validation_failed, selain itu 500
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return New(fiberErr.Code, codeForStatus(fiberErr.Code)).WithDetail("%s", fiberErr.Message)
+	}
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return Validation(validationErrs)
+	}
+	return Internal(err)
+}
+
+// codeForStatus dipakai untuk error bawaan Fiber (route tidak ada, body terlalu besar, ...)
+func codeForStatus(status int) Code {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeBadRequest
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case fiber.StatusUnsupportedMediaType:
+		return CodeUnsupportedMedia
+	case fiber.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case fiber.StatusPreconditionRequired:
+		return CodePreconditionRequired
+	case fiber.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case fiber.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
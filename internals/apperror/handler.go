@@ -0,0 +1,86 @@
+package apperror
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEProblemJSON adalah content type response error (RFC 7807)
+const MIMEProblemJSON = "application/problem+json"
+
+// typePrefix + kode = member "type" problem; URN agar tidak perlu halaman dokumentasi yang bisa diakses
+const typePrefix = "urn:masjidku:problem:"
+
+// Problem adalah body response error (RFC 7807) ditambah member ekstensi:
+// code, errors (per field), request_id, dan error (pesan, kompatibel dengan format lama)
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Message   string       `json:"error"`
+}
+
+// ErrorHandler adalah fiber.Config.ErrorHandler: semua error yang dikembalikan handler /
+// middleware ditulis sebagai problem+json dalam bahasa dari Accept-Language.
+// Error 5xx dicatat di log beserta penyebabnya (Err); client hanya menerima pesan katalog / Detail.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	e := From(err)
+	if e.Status >= fiber.StatusInternalServerError {
+		log.Printf("[ERROR] %s %s: %v", c.Method(), c.Path(), err)
+	}
+
+	lang := Lang(c)
+	title := Message(lang, e.Code, e.Status)
+	detail := title
+	if e.Detail != "" {
+		detail = e.Detail
+	}
+	problem := Problem{
+		Type:     typePrefix + string(e.Code),
+		Title:    title,
+		Status:   e.Status,
+		Detail:   detail,
+		Instance: c.OriginalURL(),
+		Code:     e.Code,
+		Errors:   e.fields(lang),
+		Message:  detail,
+	}
+	if rid, ok := c.Locals("requestid").(string); ok {
+		problem.RequestID = rid
+	}
+
+	body, err := marshal(problem, e.Extra)
+	if err != nil {
+		log.Println("[ERROR] Gagal menulis response error:", err)
+		return c.Status(fiber.StatusInternalServerError).SendString(fiber.ErrInternalServerError.Message)
+	}
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.Set(fiber.HeaderContentLanguage, lang)
+	c.Set(fiber.HeaderContentType, MIMEProblemJSON)
+	return c.Status(e.Status).Send(body)
+}
+
+// marshal menggabungkan member tambahan (Extra) ke body; member standar tidak bisa ditimpa
+func marshal(problem Problem, extra map[string]interface{}) ([]byte, error) {
+	body, err := json.Marshal(problem)
+	if err != nil || len(extra) == 0 {
+		return body, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		if _, exists := doc[k]; !exists {
+			doc[k] = v
+		}
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,111 @@
+package apperror
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	enLocale "github.com/go-playground/locales/en"
+	idLocale "github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+var (
+	validate = validator.New()
+	uni      *ut.UniversalTranslator
+)
+
+func init() {
+	// Nama field di pesan error memakai nama JSON (user_name), bukan nama struct (UserName)
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	id := idLocale.New()
+	uni = ut.New(id, id, enLocale.New())
+	register := map[string]func(*validator.Validate, ut.Translator) error{
+		"id": idTranslations.RegisterDefaultTranslations,
+		"en": enTranslations.RegisterDefaultTranslations,
+	}
+	for lang, fn := range register {
+		trans, _ := uni.GetTranslator(lang)
+		if err := fn(validate, trans); err != nil {
+			log.Printf("[ERROR] Gagal mendaftarkan terjemahan validator %s: %v", lang, err)
+		}
+	}
+}
+
+// Validator mengembalikan validator bersama (nama field JSON + terjemahan id/en).
+// Tag custom didaftarkan lewat RegisterValidation agar pesannya ikut diterjemahkan.
+func Validator() *validator.Validate {
+	return validate
+}
+
+// RegisterValidation mendaftarkan tag custom beserta pesannya per bahasa
+// ({0} = nama field), mis. dari init() package fitur
+func RegisterValidation(tag string, fn validator.Func, messages map[string]string) error {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	for lang, msg := range messages {
+		trans, found := uni.GetTranslator(lang)
+		if !found {
+			return fmt.Errorf("apperror: bahasa %s tidak didukung", lang)
+		}
+		msg := msg
+		err := validate.RegisterTranslation(tag, trans,
+			func(t ut.Translator) error { return t.Add(tag, msg, true) },
+			func(t ut.Translator, fe validator.FieldError) string {
+				out, _ := t.T(tag, fe.Field())
+				return out
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate memvalidasi struct dengan validator bersama; hasilnya nil atau *Error validation_failed
+func Validate(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		return Validation(errs)
+	}
+	return Internal(err)
+}
+
+// Validation membungkus hasil validator menjadi error 400 validation_failed;
+// pesan per field diterjemahkan saat response ditulis
+func Validation(errs validator.ValidationErrors) *Error {
+	e := BadRequest(CodeValidation)
+	e.Err, e.validation = errs, errs
+	return e
+}
+
+// fields menggabungkan kesalahan validator (diterjemahkan ke lang) dan Fields manual
+func (e *Error) fields(lang string) []FieldError {
+	if len(e.validation) == 0 {
+		return e.Fields
+	}
+	trans, _ := uni.GetTranslator(lang)
+	out := make([]FieldError, 0, len(e.validation)+len(e.Fields))
+	for _, fe := range e.validation {
+		out = append(out, FieldError{Field: fe.Field(), Code: fe.Tag(), Message: fe.Translate(trans)})
+	}
+	return append(out, e.Fields...)
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const auditUsage = `Usage: masjidku audit <command>
+
+Commands:
+  verify             Periksa rantai hash audit_logs (deteksi baris yang diubah / dihapus)`
+
+// RunCommand menjalankan subcommand `masjidku audit ...`
+func RunCommand(r *Recorder, args []string) error {
+	if len(args) == 0 {
+		return errors.New(auditUsage)
+	}
+
+	switch args[0] {
+	case "verify":
+		res, err := r.Verify(context.Background())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Rantai hash audit log utuh (%d baris diperiksa)\n", res.Checked)
+		return nil
+	default:
+		return errors.New(auditUsage)
+	}
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Change adalah nilai lama dan baru satu field
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// redacted menggantikan nilai field sensitif; perubahannya tetap tercatat, isinya tidak
+const redacted = "[REDACTED]"
+
+// sensitiveFields: field JSON yang mengandung salah satu kata ini tidak pernah disimpan apa adanya
+var sensitiveFields = []string{"password", "secret", "token", "security_answer", "recovery"}
+
+// Diff membandingkan dua snapshot (struct / map, lewat representasi JSON-nya) dan
+// mengembalikan field yang berubah. before nil = objek baru, after nil = objek dihapus.
+func Diff(before, after interface{}) (map[string]Change, error) {
+	old, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]Change{}
+	for key, o := range old {
+		if n, ok := cur[key]; !ok || !reflect.DeepEqual(o, n) {
+			changes[key] = Change{Old: o, New: n}
+		}
+	}
+	for key, n := range cur {
+		if _, ok := old[key]; !ok {
+			changes[key] = Change{New: n}
+		}
+	}
+
+	for key, change := range changes {
+		if isSensitive(key) {
+			if change.Old != nil {
+				change.Old = redacted
+			}
+			if change.New != nil {
+				change.New = redacted
+			}
+			changes[key] = change
+		}
+	}
+	return changes, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		// Bukan objek JSON (mis. string / angka): simpan sebagai satu field
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": value}, nil
+	}
+	return m, nil
+}
+
+func isSensitive(field string) bool {
+	field = strings.ToLower(field)
+	for _, s := range sensitiveFields {
+		if strings.Contains(field, s) {
+			return true
+		}
+	}
+	return false
+}
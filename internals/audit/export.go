@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/dataexport"
+)
+
+func init() {
+	dataexport.Register("audit", exportAudit)
+}
+
+// auditExport adalah satu entri audit log tanpa kolom internal hash chain
+type auditExport struct {
+	ActorID    *uuid.UUID `json:"actor_id,omitempty"`
+	Action     string     `json:"action"`
+	TargetType string     `json:"target_type,omitempty"`
+	TargetID   string     `json:"target_id,omitempty"`
+	Changes    JSON       `json:"changes,omitempty"`
+	Metadata   JSON       `json:"metadata,omitempty"`
+	IPAddress  string     `json:"ip_address,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// exportAudit: aksi yang dilakukan user, aksi admin atas nama user (impersonation)
+// dan aksi siapa pun terhadap akun user
+func exportAudit(ctx context.Context, db *gorm.DB, userID uuid.UUID, archive *dataexport.Archive) error {
+	var logs []AuditLog
+	err := db.Where("actor_id = ? OR metadata->>'on_behalf_of' = ? OR (target_type = ? AND target_id = ?)",
+		userID, userID.String(), TargetUser, userID.String()).
+		Order("id").Find(&logs).Error
+	if err != nil {
+		return err
+	}
+
+	out := make([]auditExport, 0, len(logs))
+	for _, l := range logs {
+		out = append(out, auditExport{
+			ActorID: l.ActorID, Action: l.Action, TargetType: l.TargetType, TargetID: l.TargetID,
+			Changes: l.Changes, Metadata: l.Metadata, IPAddress: l.IPAddress, UserAgent: l.UserAgent,
+			CreatedAt: l.CreatedAt,
+		})
+	}
+	return archive.JSON("activity.json", out)
+}
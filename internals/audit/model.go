@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog merepresentasikan tabel audit_logs. Baris tidak pernah diubah atau dihapus
+// (dijaga trigger di database); pada mode hash chain setiap baris menyimpan hash baris sebelumnya.
+type AuditLog struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`
+	ActorID    *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"` // nil untuk aksi sistem / tamu
+	Action     string     `gorm:"size:64;not null" json:"action"`
+	TargetType string     `gorm:"size:50" json:"target_type,omitempty"`
+	TargetID   string     `gorm:"size:64" json:"target_id,omitempty"`
+	Changes    JSON       `gorm:"type:jsonb" json:"changes,omitempty"` // {"field": {"old": ..., "new": ...}}
+	Metadata   JSON       `gorm:"type:jsonb" json:"metadata,omitempty"`
+	IPAddress  string     `gorm:"size:45" json:"ip_address,omitempty"`
+	UserAgent  string     `gorm:"size:255" json:"user_agent,omitempty"`
+	RequestID  string     `gorm:"size:64" json:"request_id,omitempty"`
+	PrevHash   *string    `gorm:"size:64" json:"prev_hash,omitempty"`
+	Hash       *string    `gorm:"size:64" json:"hash,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// JSON menyimpan dokumen JSON apa adanya ke kolom jsonb (dikirim sebagai teks, bukan bytea)
+type JSON json.RawMessage
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("audit: tipe %T tidak bisa dibaca sebagai JSON", value)
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[:0], data...)
+	return nil
+}
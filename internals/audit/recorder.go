@@ -0,0 +1,237 @@
+// Package audit mencatat event keamanan dan administratif (siapa melakukan apa, terhadap
+// siapa, dari mana) ke tabel audit_logs. Controller memanggil Recorder; pada mode hash chain
+// setiap baris terikat ke baris sebelumnya sehingga perubahan / penghapusan bisa dideteksi.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/configs"
+	authMw "masjidku/internals/middlewares/auth"
+)
+
+// Action yang dicatat. Format "<domain>.<aksi>" agar mudah difilter per prefix.
+const (
+	ActionLogin          = "auth.login"
+	ActionAccountLocked  = "auth.account_locked"
+	ActionPasswordChange = "auth.password_change"
+	ActionPasswordReset  = "auth.password_reset"
+	ActionMFAEnable      = "auth.mfa_enable"
+	ActionMFADisable     = "auth.mfa_disable"
+	ActionGoogleLink     = "auth.google_link"
+
+	ActionImpersonationStart   = "auth.impersonation_start"
+	ActionImpersonationEnd     = "auth.impersonation_end"
+	ActionImpersonationRequest = "auth.impersonation_request" // setiap request yang memakai token impersonation
+
+	ActionUserCreate  = "user.create"
+	ActionUserDelete  = "user.delete"  // akun dinonaktifkan, dihapus permanen setelah masa tenggang
+	ActionUserRestore = "user.restore" // dipulihkan dalam masa tenggang
+	ActionUserPurge   = "user.purge"   // dihapus permanen oleh scheduler
+	ActionUserExport  = "user.export"  // user meminta ekspor datanya
+
+	ActionUserRoleChange = "user.role_change"
+	ActionUserSuspend    = "user.suspend"
+	ActionUserBan        = "user.ban"
+	ActionUserReinstate  = "user.reinstate" // suspend / ban dicabut
+
+	ActionRoleCreate = "role.create"
+	ActionRoleUpdate = "role.update"
+	ActionRoleDelete = "role.delete"
+)
+
+// Target type
+const (
+	TargetUser = "user"
+	TargetRole = "role"
+)
+
+// Entry adalah satu event yang akan dicatat. Before/After boleh struct, map atau nil;
+// yang disimpan hanya selisihnya (field sensitif disamarkan).
+// Saat impersonation ActorID adalah admin dan OnBehalfOf user yang di-impersonate
+// (disimpan di metadata "on_behalf_of").
+type Entry struct {
+	ActorID    *uuid.UUID
+	OnBehalfOf *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     interface{}
+	After      interface{}
+	Metadata   map[string]interface{}
+	IP         string
+	UserAgent  string
+	RequestID  string
+}
+
+// Recorder menulis Entry ke audit_logs
+type Recorder struct {
+	db    *gorm.DB
+	chain bool
+}
+
+func NewRecorder(db *gorm.DB, cfg configs.AuditConfig) *Recorder {
+	return &Recorder{db: db, chain: cfg.HashChain}
+}
+
+// FromRequest membuat Entry berisi actor (user yang login, jika ada), IP, user agent dan request ID.
+// Pada token impersonation actor adalah admin yang sebenarnya, bukan user yang di-impersonate.
+func FromRequest(c *fiber.Ctx) Entry {
+	e := Entry{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+	if p, ok := authMw.PrincipalFrom(c); ok {
+		actorID := p.RealUserID()
+		e.ActorID = &actorID
+		if p.Impersonated() {
+			userID := p.UserID
+			e.OnBehalfOf = &userID
+		}
+	}
+	if rid, ok := c.Locals("requestid").(string); ok {
+		e.RequestID = rid
+	}
+	return e
+}
+
+// Record mencatat event di luar transaksi
+func (r *Recorder) Record(ctx context.Context, e Entry) error {
+	return r.RecordTx(r.db.WithContext(ctx), e)
+}
+
+// RecordTx mencatat event di dalam transaksi tx, sehingga log ikut di-rollback
+// bersama perubahan yang dicatat
+func (r *Recorder) RecordTx(tx *gorm.DB, e Entry) error {
+	row, err := e.toLog()
+	if err != nil {
+		return err
+	}
+	if !r.chain {
+		return tx.Create(row).Error
+	}
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		// 📌 Satu penulis pada satu waktu agar rantai hash tidak bercabang
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLockKey).Error; err != nil {
+			return err
+		}
+		var prev AuditLog
+		if err := tx.Select("hash").Where("hash IS NOT NULL").Order("id DESC").Limit(1).Find(&prev).Error; err != nil {
+			return err
+		}
+		row.PrevHash = prev.Hash
+		hash := computeHash(row)
+		row.Hash = &hash
+		return tx.Create(row).Error
+	})
+}
+
+// Log mencatat event dari request secara best effort: kegagalan hanya ditulis ke log
+// dan tidak menggagalkan request. Field yang kosong di e diisi dari FromRequest.
+func (r *Recorder) Log(c *fiber.Ctx, e Entry) {
+	base := FromRequest(c)
+	if e.ActorID == nil {
+		e.ActorID = base.ActorID
+	}
+	if e.OnBehalfOf == nil {
+		e.OnBehalfOf = base.OnBehalfOf
+	}
+	if e.IP == "" {
+		e.IP = base.IP
+	}
+	if e.UserAgent == "" {
+		e.UserAgent = base.UserAgent
+	}
+	if e.RequestID == "" {
+		e.RequestID = base.RequestID
+	}
+	if err := r.Record(c.UserContext(), e); err != nil {
+		log.Printf("[ERROR] Failed to record audit log %s: %v", e.Action, err)
+	}
+}
+
+// ImpersonationTrail mencatat setiap request yang dilakukan admin dengan token impersonation
+// (method, path, status) setelah handler selesai. Dipasang global lewat app.Use sebelum route,
+// sehingga Principal dari AuthMiddleware route sudah tersedia saat dicatat.
+func (r *Recorder) ImpersonationTrail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		p, ok := authMw.PrincipalFrom(c)
+		if !ok || !p.Impersonated() {
+			return err
+		}
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = apperror.From(err).Status
+		}
+		r.Log(c, Entry{
+			Action:     ActionImpersonationRequest,
+			TargetType: TargetUser,
+			TargetID:   p.UserID.String(),
+			Metadata: map[string]interface{}{
+				"method": c.Method(),
+				"path":   c.Path(),
+				"status": status,
+			},
+		})
+		return err
+	}
+}
+
+func (e Entry) toLog() (*AuditLog, error) {
+	row := &AuditLog{
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		IPAddress:  truncate(e.IP, 45),
+		UserAgent:  truncate(e.UserAgent, 255),
+		RequestID:  truncate(e.RequestID, 64),
+		// Dibulatkan ke mikrodetik (presisi kolom) agar hash bisa dihitung ulang dari database
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	changes, err := Diff(e.Before, e.After)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		raw, err := json.Marshal(changes)
+		if err != nil {
+			return nil, err
+		}
+		row.Changes = raw
+	}
+	metadata := e.Metadata
+	if e.OnBehalfOf != nil {
+		metadata = make(map[string]interface{}, len(e.Metadata)+1)
+		for k, v := range e.Metadata {
+			metadata[k] = v
+		}
+		metadata["on_behalf_of"] = e.OnBehalfOf.String()
+	}
+	if len(metadata) > 0 {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, err
+		}
+		row.Metadata = raw
+	}
+	return row, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,35 @@
+package constants
+
+// Permission yang dicek oleh auth.RequirePermission. Daftar ini harus sama dengan
+// seed di migrasi create-table-rbac; role -> permission diatur di database.
+const (
+	// Masjid (dicek terhadap role user di masjid pada URL)
+	PermMasjidsUpdate        = "masjids:update"
+	PermMasjidsDelete        = "masjids:delete"
+	PermMasjidsSecurity      = "masjids:security"
+	PermMembersRead          = "members:read"
+	PermMembersManage        = "members:manage"
+	PermMembersManageRoles   = "members:manage_roles"
+	PermDonationsRead        = "donations:read"
+	PermPrayerSettingsUpdate = "prayer_settings:update"
+	PermEventsPublish        = "events:publish"
+
+	// Platform (dicek terhadap role global user)
+	PermUsersRead   = "users:read"
+	PermUsersManage = "users:manage"
+	PermUsersDelete = "users:delete"
+	PermUsersRoles  = "users:roles"
+	PermUsersBan    = "users:ban" // suspend, ban & reinstate
+	PermRolesManage = "roles:manage"
+	PermAuditRead   = "audit:read"
+
+	// Login sebagai user lain; token impersonation tidak bisa dipakai untuk aksi sensitif
+	PermUsersImpersonate = "users:impersonate"
+)
+
+// PlatformPermissions adalah permission admin platform. Role yang memilikinya (langsung
+// atau lewat pewarisan) tidak boleh diberikan kepada anggota masjid.
+var PlatformPermissions = []string{
+	PermUsersRead, PermUsersManage, PermUsersDelete, PermUsersRoles, PermUsersBan,
+	PermRolesManage, PermAuditRead, PermUsersImpersonate,
+}
@@ -1,8 +1,11 @@
 package constants
 
+// Role bawaan (di-seed ke tabel roles). Role selain ini bisa dibuat lewat /api/admin/roles.
 const (
-	RoleUser    = "user"
-	RoleTeacher = "teacher"
-	RoleStaff   = "staff"
-	RoleOwner   = "owner"
+	RoleUser      = "user"
+	RoleTeacher   = "teacher"
+	RoleTreasurer = "treasurer"
+	RoleStaff     = "staff"
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin" // admin platform, hanya sebagai role global (users.role)
 )
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS masjids (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       VARCHAR(100) NOT NULL,
+    slug       VARCHAR(120) NOT NULL,
+    address    VARCHAR(255),
+    city       VARCHAR(100),
+    latitude   DECIMAL(9, 6),
+    longitude  DECIMAL(9, 6),
+    timezone   VARCHAR(50)  NOT NULL DEFAULT 'Asia/Jakarta',
+    phone      VARCHAR(20),
+    email      VARCHAR(255),
+    website    VARCHAR(255),
+    created_by UUID         NOT NULL REFERENCES users (id),
+    created_at TIMESTAMP  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_masjids_slug ON masjids (slug);
+CREATE INDEX IF NOT EXISTS idx_masjids_deleted_at ON masjids (deleted_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS masjid_members (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    masjid_id   UUID        NOT NULL REFERENCES masjids (id) ON DELETE CASCADE,
+    user_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role        VARCHAR(20) NOT NULL DEFAULT 'user',
+    status      VARCHAR(20) NOT NULL DEFAULT 'invited',
+    invited_by  UUID REFERENCES users (id) ON DELETE SET NULL,
+    accepted_at TIMESTAMP,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_masjid_members_masjid_user ON masjid_members (masjid_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_masjid_members_user_id ON masjid_members (user_id);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS donations (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id       VARCHAR(64)  NOT NULL,
+    masjid_id      UUID         NOT NULL REFERENCES masjids (id),
+    user_id        UUID REFERENCES users (id) ON DELETE SET NULL,
+    donor_name     VARCHAR(100) NOT NULL,
+    donor_email    VARCHAR(255),
+    is_anonymous   BOOLEAN      NOT NULL DEFAULT FALSE,
+    amount         BIGINT       NOT NULL CHECK (amount > 0),
+    message        VARCHAR(500),
+    status         VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    payment_type   VARCHAR(50),
+    transaction_id VARCHAR(100),
+    snap_token     VARCHAR(255),
+    redirect_url   VARCHAR(500),
+    paid_at        TIMESTAMP,
+    created_at     TIMESTAMP  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_donations_order_id ON donations (order_id);
+CREATE INDEX IF NOT EXISTS idx_donations_masjid_id ON donations (masjid_id);
+CREATE INDEX IF NOT EXISTS idx_donations_user_id ON donations (user_id);
+CREATE INDEX IF NOT EXISTS idx_donations_status ON donations (status);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS masjid_prayer_settings (
+    masjid_id   UUID PRIMARY KEY REFERENCES masjids (id) ON DELETE CASCADE,
+    method      VARCHAR(20) NOT NULL DEFAULT 'kemenag',
+    asr_method  VARCHAR(10) NOT NULL DEFAULT 'shafii',
+    elevation   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    adj_imsak   INTEGER     NOT NULL DEFAULT 0,
+    adj_fajr    INTEGER     NOT NULL DEFAULT 0,
+    adj_sunrise INTEGER     NOT NULL DEFAULT 0,
+    adj_dhuhr   INTEGER     NOT NULL DEFAULT 0,
+    adj_asr     INTEGER     NOT NULL DEFAULT 0,
+    adj_maghrib INTEGER     NOT NULL DEFAULT 0,
+    adj_isha    INTEGER     NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
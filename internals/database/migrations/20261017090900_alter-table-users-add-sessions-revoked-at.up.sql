@@ -0,0 +1,2 @@
+-- Access token dengan iat sebelum waktu ini ditolak (dipakai saat reset password)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMP;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Akun Google sudah diverifikasi oleh Google
+UPDATE users SET email_verified_at = created_at WHERE google_id IS NOT NULL AND email_verified_at IS NULL;
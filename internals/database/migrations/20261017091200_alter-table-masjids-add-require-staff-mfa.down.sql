@@ -0,0 +1 @@
+ALTER TABLE masjids DROP COLUMN IF EXISTS require_staff_mfa;
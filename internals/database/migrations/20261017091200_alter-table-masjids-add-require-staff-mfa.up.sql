@@ -0,0 +1 @@
+ALTER TABLE masjids ADD COLUMN IF NOT EXISTS require_staff_mfa BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS token_blacklist (
+    id SERIAL PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    expired_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_blacklist_expired_at ON token_blacklist (expired_at);
+
+DROP TABLE IF EXISTS revoked_tokens;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    key        VARCHAR(100) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+-- token_blacklist menyimpan JWT utuh tanpa jti; token lama tersebut sudah tidak lolos
+-- verifikasi claim baru (sub/jti/iss/aud), jadi tabelnya bisa dibuang
+DROP TABLE IF EXISTS token_blacklist;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+    kid             VARCHAR(64) PRIMARY KEY,
+    algorithm       VARCHAR(10) NOT NULL,
+    public_key_pem  TEXT        NOT NULL,
+    private_key_enc TEXT        NOT NULL,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    retired_at      TIMESTAMP,
+    verify_until    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jwt_signing_keys_verify_until ON jwt_signing_keys (verify_until);
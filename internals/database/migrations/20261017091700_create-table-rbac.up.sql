@@ -0,0 +1,75 @@
+CREATE TABLE IF NOT EXISTS permissions (
+    name        VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    name        VARCHAR(20)  PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    builtin     BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_name       VARCHAR(20)  NOT NULL REFERENCES roles (name) ON DELETE CASCADE ON UPDATE CASCADE,
+    permission_name VARCHAR(100) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role_name, permission_name)
+);
+
+-- role_name mewarisi semua permission milik parent_name (rekursif)
+CREATE TABLE IF NOT EXISTS role_parents (
+    role_name   VARCHAR(20) NOT NULL REFERENCES roles (name) ON DELETE CASCADE ON UPDATE CASCADE,
+    parent_name VARCHAR(20) NOT NULL REFERENCES roles (name) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (role_name, parent_name),
+    CHECK (role_name <> parent_name)
+);
+
+-- 🌱 Seed permission & role bawaan
+INSERT INTO permissions (name, description) VALUES
+    ('masjids:update',         'Ubah profil masjid'),
+    ('masjids:delete',         'Hapus masjid'),
+    ('masjids:security',       'Ubah pengaturan keamanan masjid (wajib 2FA pengurus)'),
+    ('members:read',           'Lihat anggota masjid'),
+    ('members:manage',         'Undang anggota masjid'),
+    ('members:manage_roles',   'Ubah role dan keluarkan anggota masjid, termasuk mengangkat owner'),
+    ('donations:read',         'Lihat donasi masjid'),
+    ('prayer_settings:update', 'Ubah pengaturan jadwal sholat'),
+    ('events:publish',         'Publikasikan kegiatan masjid'),
+    ('users:read',             'Lihat semua user (admin platform)'),
+    ('users:manage',           'Kelola user (admin platform)'),
+    ('roles:manage',           'Kelola definisi role dan permission (admin platform)')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO roles (name, description, builtin) VALUES
+    ('user',      'Jamaah / anggota biasa',               TRUE),
+    ('teacher',   'Ustadz / pengajar',                     TRUE),
+    ('treasurer', 'Bendahara masjid',                      TRUE),
+    ('staff',     'Pengurus masjid',                       TRUE),
+    ('owner',     'Pemilik / ketua pengurus masjid',       TRUE),
+    ('admin',     'Admin platform (role global saja)',     TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_parents (role_name, parent_name) VALUES
+    ('teacher',   'user'),
+    ('treasurer', 'user'),
+    ('staff',     'teacher'),
+    ('staff',     'treasurer'),
+    ('owner',     'staff')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('teacher',   'events:publish'),
+    ('treasurer', 'donations:read'),
+    ('staff',     'masjids:update'),
+    ('staff',     'members:read'),
+    ('staff',     'members:manage'),
+    ('staff',     'prayer_settings:update'),
+    ('owner',     'masjids:delete'),
+    ('owner',     'masjids:security'),
+    ('owner',     'members:manage_roles'),
+    ('admin',     'users:read'),
+    ('admin',     'users:manage'),
+    ('admin',     'roles:manage')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id        UUID REFERENCES users (id) ON DELETE CASCADE,
+    identifier     VARCHAR(255) NOT NULL,
+    ip_address     VARCHAR(45),
+    user_agent     VARCHAR(255),
+    success        BOOLEAN      NOT NULL,
+    failure_reason VARCHAR(30),
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id_created_at ON login_attempts (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_identifier_created_at ON login_attempts (identifier, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_created_at ON login_attempts (created_at);
+
+-- Akun dikunci sementara setelah terlalu banyak login gagal
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
@@ -0,0 +1,47 @@
+-- created_at memakai TIMESTAMPTZ agar nilai yang dibaca ulang sama persis dengan yang di-hash
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    UUID,
+    action      VARCHAR(64)  NOT NULL,
+    target_type VARCHAR(50),
+    target_id   VARCHAR(64),
+    changes     JSONB,
+    metadata    JSONB,
+    ip_address  VARCHAR(45),
+    user_agent  VARCHAR(255),
+    request_id  VARCHAR(64),
+    prev_hash   VARCHAR(64),
+    hash        VARCHAR(64),
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- actor_id sengaja tanpa foreign key: log harus tetap ada walaupun user dihapus
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id_created_at ON audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_created_at ON audit_logs (action, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+-- 🔒 Append-only: baris audit tidak bisa diubah atau dihapus lewat aplikasi
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs bersifat append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+CREATE TRIGGER trg_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+DROP TRIGGER IF EXISTS trg_audit_logs_no_truncate ON audit_logs;
+CREATE TRIGGER trg_audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Lihat audit log (admin platform)')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'audit:read')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_profile_created_at;
+DROP INDEX IF EXISTS idx_users_created_at;
+DROP INDEX IF EXISTS idx_users_profile_full_name_fts;
+DROP INDEX IF EXISTS idx_users_user_name_fts;
@@ -0,0 +1,9 @@
+-- Pencarian ?q= di listing user & profil (ekspresi harus sama dengan query.applySearch)
+CREATE INDEX IF NOT EXISTS idx_users_user_name_fts
+    ON users USING GIN (to_tsvector('simple', coalesce(user_name, '')));
+CREATE INDEX IF NOT EXISTS idx_users_profile_full_name_fts
+    ON users_profile USING GIN (to_tsvector('simple', coalesce(full_name, '')));
+
+-- Sort default ?sort=-created_at
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_users_profile_created_at ON users_profile (created_at, id);
@@ -0,0 +1,2 @@
+ALTER TABLE users_profile DROP COLUMN IF EXISTS version;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
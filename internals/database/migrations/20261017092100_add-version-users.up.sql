@@ -0,0 +1,3 @@
+-- Optimistic locking (ETag / If-Match): dinaikkan setiap update lewat API
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE users_profile ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
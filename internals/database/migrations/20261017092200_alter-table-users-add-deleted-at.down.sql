@@ -0,0 +1,12 @@
+DELETE FROM role_permissions WHERE permission_name = 'users:delete';
+DELETE FROM permissions WHERE name = 'users:delete';
+
+-- Gagal jika sudah ada masjid yang pembuatnya dihapus permanen (created_by NULL)
+ALTER TABLE masjids DROP CONSTRAINT IF EXISTS masjids_created_by_fkey;
+ALTER TABLE masjids ADD CONSTRAINT masjids_created_by_fkey
+    FOREIGN KEY (created_by) REFERENCES users (id);
+ALTER TABLE masjids ALTER COLUMN created_by SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_purge_at;
+ALTER TABLE users DROP COLUMN IF EXISTS purge_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
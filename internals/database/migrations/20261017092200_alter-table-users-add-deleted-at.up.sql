@@ -0,0 +1,18 @@
+-- Hapus akun: soft delete (deleted_at) lalu dihapus permanen oleh scheduler setelah purge_at
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS purge_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_users_purge_at ON users (purge_at) WHERE deleted_at IS NOT NULL;
+
+-- Masjid tetap ada walaupun pembuatnya sudah dihapus permanen
+ALTER TABLE masjids ALTER COLUMN created_by DROP NOT NULL;
+ALTER TABLE masjids DROP CONSTRAINT IF EXISTS masjids_created_by_fkey;
+ALTER TABLE masjids ADD CONSTRAINT masjids_created_by_fkey
+    FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:delete', 'Hapus & pulihkan akun user (admin platform)')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'users:delete')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+-- Permintaan ekspor data user (portabilitas data); arsip ZIP disimpan di storage sampai expires_at
+CREATE TABLE IF NOT EXISTS user_data_exports (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id        UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status         VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                   CHECK (status IN ('pending', 'ready', 'failed', 'expired')),
+    file_key       VARCHAR(255),
+    size_bytes     BIGINT       NOT NULL DEFAULT 0,
+    failure_reason VARCHAR(255),
+    completed_at   TIMESTAMP,
+    expires_at     TIMESTAMP,
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_user_id_created_at ON user_data_exports (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_expires_at ON user_data_exports (expires_at) WHERE status = 'ready';
@@ -0,0 +1,7 @@
+DELETE FROM role_permissions WHERE permission_name IN ('users:roles', 'users:ban');
+DELETE FROM permissions WHERE name IN ('users:roles', 'users:ban');
+
+ALTER TABLE users DROP COLUMN IF EXISTS ban_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_at;
+ALTER TABLE users DROP COLUMN IF EXISTS suspension_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_until;
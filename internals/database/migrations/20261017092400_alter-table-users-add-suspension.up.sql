@@ -0,0 +1,15 @@
+-- Admin bisa menyuspend akun sampai waktu tertentu atau mem-ban tanpa batas waktu
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspension_reason VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS ban_reason VARCHAR(255);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:roles', 'Ubah role global user (admin platform)'),
+    ('users:ban',   'Suspend, ban & pulihkan akses akun user (admin platform)')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'users:roles'),
+    ('admin', 'users:ban')
+ON CONFLICT DO NOTHING;
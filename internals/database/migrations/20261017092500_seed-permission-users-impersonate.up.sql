@@ -0,0 +1,8 @@
+-- Login sebagai user lain (impersonation) untuk admin platform; setiap pemakaian dicatat di audit_logs
+INSERT INTO permissions (name, description) VALUES
+    ('users:impersonate', 'Login sebagai user lain untuk membantu / menelusuri masalah (admin platform)')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'users:impersonate')
+ON CONFLICT DO NOTHING;
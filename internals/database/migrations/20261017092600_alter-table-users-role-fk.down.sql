@@ -0,0 +1,4 @@
+-- Gagal jika masih ada user dengan role di luar daftar lama (mis. staff)
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('owner', 'user', 'teacher', 'treasurer', 'admin'));
@@ -0,0 +1,6 @@
+-- Role global user mengikuti tabel roles (termasuk staff dan role custom dari RBAC API),
+-- bukan daftar tetap di CHECK. Role yang masih dipakai user tidak bisa dihapus (ErrRoleInUse).
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users ADD CONSTRAINT users_role_fkey
+    FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE;
@@ -0,0 +1,2 @@
+-- Profil duplikat yang di-soft-delete saat up tidak dipulihkan
+DROP INDEX IF EXISTS ux_users_profile_user_id;
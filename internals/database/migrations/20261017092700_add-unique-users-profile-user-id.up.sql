@@ -0,0 +1,12 @@
+-- Satu profil aktif per user. Duplikat lama (hasil race PUT/PATCH pertama) di-soft-delete,
+-- yang dipertahankan id terkecil (sama dengan yang dibaca myProfile).
+UPDATE users_profile p
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE p.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM users_profile o
+    WHERE o.user_id = p.user_id AND o.deleted_at IS NULL AND o.id < p.id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_profile_user_id
+    ON users_profile (user_id) WHERE deleted_at IS NULL;
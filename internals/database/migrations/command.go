@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const usage = `Usage: masjidku migrate <command>
+
+Commands:
+  up                 Jalankan semua migrasi yang belum diterapkan
+  down [N]           Rollback N migrasi terakhir (default 1)
+  status             Tampilkan versi saat ini dan migrasi yang pending
+  force <version>    Set versi secara paksa dan hapus status dirty`
+
+// RunCommand menjalankan subcommand `masjidku migrate ...`
+func RunCommand(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	runner, err := NewRunner(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		err := runner.Up(ctx)
+		if errors.Is(err, ErrNoChange) {
+			fmt.Println("✅ Database sudah versi terbaru")
+			return nil
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("jumlah langkah tidak valid: %q", args[1])
+			}
+		}
+		err := runner.Down(ctx, steps)
+		if errors.Is(err, ErrNoChange) {
+			fmt.Println("✅ Tidak ada migrasi untuk di-rollback")
+			return nil
+		}
+		return err
+
+	case "status":
+		st, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Version: %d (dirty: %t)\n", st.Version, st.Dirty)
+		for _, m := range st.Applied {
+			fmt.Printf("  [x] %d_%s\n", m.Version, m.Name)
+		}
+		for _, m := range st.Pending {
+			fmt.Printf("  [ ] %d_%s\n", m.Version, m.Name)
+		}
+		return nil
+
+	case "force":
+		if len(args) < 2 {
+			return errors.New("usage: masjidku migrate force <version>")
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("versi tidak valid: %q", args[1])
+		}
+		if err := runner.Force(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Versi dipaksa ke %d\n", version)
+		return nil
+
+	default:
+		return errors.New(usage)
+	}
+}
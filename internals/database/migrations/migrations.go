@@ -0,0 +1,315 @@
+// Package migrations berisi file SQL migrasi yang di-embed ke dalam binary
+// beserta runner-nya. Format tabel schema_migrations (version, dirty) sengaja
+// dibuat sama dengan golang-migrate agar database lama yang dimigrasi dengan
+// CLI `migrate` bisa langsung dilanjutkan oleh runner ini.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// advisoryLockKey dipakai pg_advisory_lock agar dua replica tidak migrasi bersamaan
+const advisoryLockKey int64 = 7_204_731_208_551_013
+
+var (
+	ErrDirty     = errors.New("database dalam keadaan dirty, perbaiki manual lalu jalankan `migrate force <version>`")
+	ErrNoChange  = errors.New("tidak ada migrasi yang perlu dijalankan")
+	fileNameExpr = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+)
+
+// Migration adalah satu versi migrasi (pasangan file up & down)
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status adalah kondisi migrasi saat ini
+type Status struct {
+	Version uint64
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Load membaca seluruh migrasi yang di-embed, urut berdasarkan versi
+func Load() ([]Migration, error) {
+	return load(files)
+}
+
+// load membaca migrasi dari fsys. Setiap versi wajib punya tepat satu file up dan satu
+// file down dengan nama yang sama; versi ganda atau pasangan yang tidak lengkap adalah error.
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, e := range entries {
+		m := fileNameExpr.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("versi migrasi tidak valid %q: %w", e.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("versi migrasi %d ganda: %q dan %q", version, mig.Name, m[2])
+		}
+		target := &mig.Down
+		if m[3] == "up" {
+			target = &mig.Up
+		}
+		if *target != "" {
+			return nil, fmt.Errorf("migrasi %d_%s.%s.sql ganda", version, m[2], m[3])
+		}
+		*target = string(body)
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		switch {
+		case m.Up == "":
+			return nil, fmt.Errorf("migrasi %d_%s tidak punya file .up.sql (atau kosong)", m.Version, m.Name)
+		case m.Down == "":
+			return nil, fmt.Errorf("migrasi %d_%s tidak punya file .down.sql (atau kosong)", m.Version, m.Name)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// Runner menjalankan migrasi di atas satu koneksi database yang sama,
+// sehingga advisory lock (session level) tetap dipegang selama proses migrasi.
+type Runner struct {
+	DB         *sql.DB
+	migrations []Migration
+}
+
+func NewRunner(db *sql.DB) (*Runner, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{DB: db, migrations: migrations}, nil
+}
+
+// Up menjalankan seluruh migrasi yang belum diterapkan
+func (r *Runner) Up(ctx context.Context) error {
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w (version %d)", ErrDirty, current)
+		}
+
+		applied := 0
+		for _, m := range r.migrations {
+			if m.Version <= current {
+				continue
+			}
+			log.Printf("[MIGRATE] ⬆️  %d_%s", m.Version, m.Name)
+			if err := apply(ctx, conn, m.Version, m.Up, m.Version); err != nil {
+				return fmt.Errorf("migrasi %d_%s gagal: %w", m.Version, m.Name, err)
+			}
+			applied++
+		}
+
+		if applied == 0 {
+			return ErrNoChange
+		}
+		log.Printf("[MIGRATE] ✅ %d migrasi diterapkan", applied)
+		return nil
+	})
+}
+
+// Down me-rollback `steps` migrasi terakhir
+func (r *Runner) Down(ctx context.Context, steps int) error {
+	if steps < 1 {
+		steps = 1
+	}
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w (version %d)", ErrDirty, current)
+		}
+
+		for i := 0; i < steps; i++ {
+			idx := r.indexOf(current)
+			if idx < 0 {
+				if i == 0 {
+					return ErrNoChange
+				}
+				return nil
+			}
+			m := r.migrations[idx]
+			var previous uint64
+			if idx > 0 {
+				previous = r.migrations[idx-1].Version
+			}
+
+			log.Printf("[MIGRATE] ⬇️  %d_%s", m.Version, m.Name)
+			if err := apply(ctx, conn, m.Version, m.Down, previous); err != nil {
+				return fmt.Errorf("rollback %d_%s gagal: %w", m.Version, m.Name, err)
+			}
+			current = previous
+		}
+		return nil
+	})
+}
+
+// Force menandai versi tertentu sebagai versi saat ini dan membersihkan status dirty
+func (r *Runner) Force(ctx context.Context, version uint64) error {
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		return writeVersion(ctx, conn, version, false)
+	})
+}
+
+// Status mengembalikan versi saat ini beserta daftar migrasi applied / pending
+func (r *Runner) Status(ctx context.Context) (*Status, error) {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &Status{Version: current, Dirty: dirty}
+	for _, m := range r.migrations {
+		if m.Version <= current {
+			st.Applied = append(st.Applied, m)
+		} else {
+			st.Pending = append(st.Pending, m)
+		}
+	}
+	return st, nil
+}
+
+func (r *Runner) indexOf(version uint64) int {
+	for i, m := range r.migrations {
+		if m.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// withLock mengambil satu koneksi, memegang advisory lock, lalu menjalankan fn
+func (r *Runner) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	log.Println("[MIGRATE] Menunggu advisory lock...")
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey); err != nil {
+		return fmt.Errorf("gagal mengambil advisory lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey); err != nil {
+			log.Printf("[MIGRATE] Gagal melepas advisory lock: %v", err)
+		}
+	}()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// apply menandai versi sebagai dirty, menjalankan SQL dalam transaksi, lalu menandai
+// `nextVersion` sebagai bersih. Jika proses gagal di tengah, status dirty tetap tersimpan.
+func apply(ctx context.Context, conn *sql.Conn, version uint64, query string, nextVersion uint64) error {
+	if err := writeVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return writeVersion(ctx, conn, nextVersion, false)
+}
+
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+	return err
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(version), dirty, nil
+}
+
+// writeVersion menyimpan satu baris (version, dirty); version 0 berarti belum ada migrasi
+func writeVersion(ctx context.Context, conn *sql.Conn, version uint64, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, int64(version), dirty); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
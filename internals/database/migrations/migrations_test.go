@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestLoadEmbedded: semua migrasi yang ikut di binary bisa dimuat, urut naik tanpa versi
+// ganda, dan setiap versi punya pasangan up & down
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("tidak ada migrasi yang di-embed")
+	}
+	for i, m := range migrations {
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			t.Errorf("urutan versi salah: %d setelah %d", m.Version, migrations[i-1].Version)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migrasi %d_%s harus punya up & down", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	t.Run("urut berdasarkan versi", func(t *testing.T) {
+		migrations, err := load(fstest.MapFS{
+			"20250101000002_b.up.sql":   file("up b"),
+			"20250101000002_b.down.sql": file("down b"),
+			"20250101000001_a.down.sql": file("down a"),
+			"20250101000001_a.up.sql":   file("up a"),
+			"README.md":                 file("bukan migrasi"),
+			"20250101000003_c.sql":      file("bukan migrasi"),
+		})
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		want := []Migration{
+			{Version: 20250101000001, Name: "a", Up: "up a", Down: "down a"},
+			{Version: 20250101000002, Name: "b", Up: "up b", Down: "down b"},
+		}
+		if len(migrations) != len(want) {
+			t.Fatalf("load = %+v", migrations)
+		}
+		for i := range want {
+			if migrations[i] != want[i] {
+				t.Errorf("migrasi %d = %+v, want %+v", i, migrations[i], want[i])
+			}
+		}
+	})
+
+	cases := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{"tanpa down", fstest.MapFS{
+			"20250101000001_a.up.sql": file("up"),
+		}, ".down.sql"},
+		{"tanpa up", fstest.MapFS{
+			"20250101000001_a.down.sql": file("down"),
+		}, ".up.sql"},
+		{"down kosong", fstest.MapFS{
+			"20250101000001_a.up.sql":   file("up"),
+			"20250101000001_a.down.sql": file(""),
+		}, ".down.sql"},
+		{"versi ganda", fstest.MapFS{
+			"20250101000001_a.up.sql":   file("up a"),
+			"20250101000001_a.down.sql": file("down a"),
+			"20250101000001_b.up.sql":   file("up b"),
+			"20250101000001_b.down.sql": file("down b"),
+		}, "ganda"},
+		{"versi ganda dengan nol di depan", fstest.MapFS{
+			"20250101000001_a.up.sql":    file("up a"),
+			"20250101000001_a.down.sql":  file("down a"),
+			"020250101000001_a.up.sql":   file("up a lagi"),
+			"020250101000001_a.down.sql": file("down a lagi"),
+		}, "ganda"},
+		{"versi terlalu besar", fstest.MapFS{
+			"99999999999999999999_a.up.sql":   file("up"),
+			"99999999999999999999_a.down.sql": file("down"),
+		}, "tidak valid"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			migrations, err := load(tc.fsys)
+			if err == nil {
+				t.Fatalf("load harus gagal, dapat %+v", migrations)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error = %v, want mengandung %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package dataexport menyusun arsip ZIP berisi seluruh data milik satu user (hak portabilitas data).
+// Setiap modul fitur mendaftarkan exporter-nya sendiri lewat Register di init(), sehingga modul
+// baru otomatis ikut diekspor tanpa mengubah endpoint ekspor.
+package dataexport
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Exporter menulis data milik userID ke arsip. File ditulis lewat Archive dan otomatis
+// diletakkan di folder bernama sesuai nama exporter.
+type Exporter func(ctx context.Context, db *gorm.DB, userID uuid.UUID, archive *Archive) error
+
+var (
+	mu        sync.RWMutex
+	exporters = map[string]Exporter{}
+)
+
+// Register mendaftarkan exporter sebuah modul. Nama dipakai sebagai folder di arsip
+// dan harus unik (panic jika terdaftar dua kali, seperti database/sql.Register).
+func Register(name string, exporter Exporter) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := exporters[name]; dup {
+		panic("dataexport: exporter " + name + " sudah terdaftar")
+	}
+	exporters[name] = exporter
+}
+
+// Names mengembalikan nama exporter yang terdaftar, terurut
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Manifest ditulis sebagai manifest.json di akar arsip
+type Manifest struct {
+	UserID      uuid.UUID `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Modules     []string  `json:"modules"`
+}
+
+// Build menjalankan semua exporter untuk userID dan menulis arsip ZIP ke w.
+// Satu exporter gagal = seluruh ekspor gagal, agar user tidak menerima arsip yang tidak lengkap.
+func Build(ctx context.Context, db *gorm.DB, userID uuid.UUID, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	names := Names()
+	for _, name := range names {
+		mu.RLock()
+		exporter := exporters[name]
+		mu.RUnlock()
+
+		if err := exporter(ctx, db.WithContext(ctx), userID, &Archive{zip: zw, dir: name}); err != nil {
+			return fmt.Errorf("dataexport: exporter %s: %w", name, err)
+		}
+	}
+
+	manifest := Manifest{UserID: userID, GeneratedAt: time.Now().UTC(), Modules: names}
+	if err := (&Archive{zip: zw}).JSON("manifest.json", manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Archive adalah arsip ZIP yang sedang ditulis, dilihat dari folder satu exporter
+type Archive struct {
+	zip *zip.Writer
+	dir string
+}
+
+// JSON menulis v sebagai file JSON (terindentasi agar mudah dibaca user)
+func (a *Archive) JSON(name string, v interface{}) error {
+	f, err := a.create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// CSV menulis header dan rows sebagai file CSV (untuk dibuka di spreadsheet)
+func (a *Archive) CSV(name string, header []string, rows [][]string) error {
+	f, err := a.create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func (a *Archive) create(name string) (io.Writer, error) {
+	if a.dir != "" {
+		name = a.dir + "/" + name
+	}
+	return a.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
+
+// FormatTime memformat waktu untuk kolom CSV (kosong jika nil)
+func FormatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
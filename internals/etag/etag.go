@@ -0,0 +1,112 @@
+// Package etag menangani optimistic concurrency lewat kolom version:
+// ETag dibentuk dari id + version, GET mendukung If-None-Match (304) dan
+// PUT / PATCH / DELETE memeriksa If-Match (428 jika wajib tapi tidak dikirim, 412 jika beda).
+package etag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+)
+
+// ErrStale: baris sudah diubah request lain sejak dibaca (version berbeda)
+var ErrStale = errors.New("etag: data sudah diubah request lain")
+
+// Tag membentuk ETag kuat dari id dan version, mis. "6f1c...-3"
+func Tag(id interface{}, version int64) string {
+	return fmt.Sprintf(`"%v-%d"`, id, version)
+}
+
+// NotModified memasang header ETag lalu mengembalikan true jika If-None-Match cocok;
+// handler cukup membalas c.SendStatus(fiber.StatusNotModified)
+func NotModified(c *fiber.Ctx, tag string) bool {
+	c.Set(fiber.HeaderETag, tag)
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && matches(header, tag, true)
+}
+
+// Preconditions memeriksa If-Match sebelum resource diubah / dihapus
+type Preconditions struct {
+	// Strict: request tanpa If-Match ditolak 428. Jika false, If-Match hanya dicek kalau dikirim.
+	Strict bool
+}
+
+// Check membandingkan If-Match dengan ETag resource saat ini
+func (p Preconditions) Check(c *fiber.Ctx, tag string) error {
+	header := c.Get(fiber.HeaderIfMatch)
+	if header == "" {
+		if p.Strict {
+			return apperror.New(fiber.StatusPreconditionRequired, apperror.CodePreconditionRequired)
+		}
+		return nil
+	}
+	if !matches(header, tag, false) {
+		return PreconditionFailed()
+	}
+	return nil
+}
+
+// CheckNew dipakai saat resource belum ada (PUT yang membuat resource):
+// If-Match apa pun gagal karena belum ada representasi yang bisa dicocokkan
+func (p Preconditions) CheckNew(c *fiber.Ctx) error {
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		return PreconditionFailed()
+	}
+	return nil
+}
+
+// PreconditionFailed adalah error 412 (If-Match tidak cocok / data sudah berubah)
+func PreconditionFailed() *apperror.Error {
+	return apperror.New(fiber.StatusPreconditionFailed, apperror.CodePreconditionFailed)
+}
+
+// Update menyimpan kolom model hanya jika version di database masih sama dengan *version,
+// lalu menaikkan *version. ErrStale jika baris sudah diubah request lain di antaranya.
+func Update(db *gorm.DB, model interface{}, version *int64, columns ...string) error {
+	current := *version
+	*version = current + 1
+	res := db.Model(model).Where("version = ?", current).
+		Select(append(columns, "version")).Updates(model)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = ErrStale
+	}
+	if res.Error != nil {
+		*version = current
+	}
+	return res.Error
+}
+
+// Delete menghapus model (soft delete jika model mendukungnya) hanya jika version masih sama
+func Delete(db *gorm.DB, model interface{}, version int64) error {
+	res := db.Where("version = ?", version).Delete(model)
+	if res.Error == nil && res.RowsAffected == 0 {
+		return ErrStale
+	}
+	return res.Error
+}
+
+// matches membandingkan daftar ETag di header ("*" atau "a", W/"b") dengan tag.
+// If-None-Match memakai perbandingan lemah (awalan W/ diabaikan), If-Match perbandingan kuat.
+func matches(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package etag
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"masjidku/internals/apperror"
+)
+
+func TestTag(t *testing.T) {
+	if got := Tag(42, 3); got != `"42-3"` {
+		t.Errorf("Tag = %s", got)
+	}
+}
+
+// run menjalankan fn di handler Fiber dengan header request tersebut dan mengembalikan
+// status respons (error ditulis apperror.ErrorHandler) beserta header ETag
+func run(t *testing.T, headers map[string]string, fn func(c *fiber.Ctx) error) (int, string) {
+	t.Helper()
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.ErrorHandler})
+	app.Put("/", func(c *fiber.Ctx) error {
+		if err := fn(c); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	req := httptest.NewRequest("PUT", "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderETag)
+}
+
+func TestPreconditionsCheck(t *testing.T) {
+	current := Tag(7, 2)
+	cases := []struct {
+		name    string
+		strict  bool
+		ifMatch string
+		want    int
+	}{
+		{"tanpa If-Match, strict", true, "", fiber.StatusPreconditionRequired},
+		{"tanpa If-Match, tidak strict", false, "", fiber.StatusNoContent},
+		{"cocok", true, `"7-2"`, fiber.StatusNoContent},
+		{"version lama", true, `"7-1"`, fiber.StatusPreconditionFailed},
+		{"version lama, tidak strict", false, `"7-1"`, fiber.StatusPreconditionFailed},
+		{"id lain", true, `"8-2"`, fiber.StatusPreconditionFailed},
+		{"tanpa tanda kutip", true, `7-2`, fiber.StatusPreconditionFailed},
+		{"ETag lemah ditolak (perbandingan kuat)", true, `W/"7-2"`, fiber.StatusPreconditionFailed},
+		{"salah satu dari daftar", true, `"7-1", "7-2"`, fiber.StatusNoContent},
+		{"daftar dengan ETag lemah", true, `W/"7-2", "7-1"`, fiber.StatusPreconditionFailed},
+		{"wildcard", true, `*`, fiber.StatusNoContent},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tc.ifMatch != "" {
+				headers[fiber.HeaderIfMatch] = tc.ifMatch
+			}
+			p := Preconditions{Strict: tc.strict}
+			status, _ := run(t, headers, func(c *fiber.Ctx) error { return p.Check(c, current) })
+			if status != tc.want {
+				t.Errorf("status = %d, want %d", status, tc.want)
+			}
+		})
+	}
+}
+
+func TestPreconditionsCheckNew(t *testing.T) {
+	cases := []struct {
+		ifMatch string
+		want    int
+	}{
+		{"", fiber.StatusNoContent},
+		{`"7-1"`, fiber.StatusPreconditionFailed},
+		{`*`, fiber.StatusPreconditionFailed},
+	}
+	for _, tc := range cases {
+		headers := map[string]string{}
+		if tc.ifMatch != "" {
+			headers[fiber.HeaderIfMatch] = tc.ifMatch
+		}
+		p := Preconditions{Strict: true}
+		if status, _ := run(t, headers, p.CheckNew); status != tc.want {
+			t.Errorf("If-Match %q: status = %d, want %d", tc.ifMatch, status, tc.want)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	current := Tag(7, 2)
+	cases := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"tanpa header", "", false},
+		{"cocok", `"7-2"`, true},
+		{"ETag lemah cocok (perbandingan lemah)", `W/"7-2"`, true},
+		{"version lama", `"7-1"`, false},
+		{"salah satu dari daftar", `"7-1", W/"7-2"`, true},
+		{"wildcard", `*`, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tc.ifNoneMatch != "" {
+				headers[fiber.HeaderIfNoneMatch] = tc.ifNoneMatch
+			}
+			var got bool
+			_, etag := run(t, headers, func(c *fiber.Ctx) error {
+				got = NotModified(c, current)
+				return nil
+			})
+			if got != tc.want {
+				t.Errorf("NotModified = %v, want %v", got, tc.want)
+			}
+			if etag != current {
+				t.Errorf("header ETag = %q, want %q", etag, current)
+			}
+		})
+	}
+}
+
+// ============================ Update / Delete ============================
+
+// versionStore adalah driver database/sql minimal: setiap UPDATE mengembalikan rowsAffected
+// dan mencatat query beserta argumennya
+type versionStore struct {
+	rowsAffected int64
+	err          error
+	queries      []string
+	args         [][]driver.NamedValue
+}
+
+func (s *versionStore) Connect(context.Context) (driver.Conn, error) { return s, nil }
+func (s *versionStore) Driver() driver.Driver                        { return nil }
+func (s *versionStore) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare tidak didukung")
+}
+func (s *versionStore) Close() error              { return nil }
+func (s *versionStore) Begin() (driver.Tx, error) { return s, nil }
+func (s *versionStore) Commit() error             { return nil }
+func (s *versionStore) Rollback() error           { return nil }
+
+func (s *versionStore) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s.queries = append(s.queries, query)
+	s.args = append(s.args, args)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return driver.RowsAffected(s.rowsAffected), nil
+}
+
+type widget struct {
+	ID        int64
+	Name      string
+	Version   int64
+	DeletedAt gorm.DeletedAt
+}
+
+func newVersionDB(t *testing.T, store *versionStore) *gorm.DB {
+	t.Helper()
+	sqlDB := sql.OpenDB(store)
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db
+}
+
+func TestUpdate(t *testing.T) {
+	dbErr := errors.New("koneksi putus")
+	cases := []struct {
+		name        string
+		rows        int64
+		err         error
+		wantErr     error
+		wantVersion int64
+	}{
+		{"version sama", 1, nil, nil, 4},
+		{"version lama", 0, nil, ErrStale, 3},
+		{"error database", 0, dbErr, dbErr, 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &versionStore{rowsAffected: tc.rows, err: tc.err}
+			w := widget{ID: 7, Name: "baru", Version: 3}
+
+			err := Update(newVersionDB(t, store), &w, &w.Version, "name")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Update = %v, want %v", err, tc.wantErr)
+			}
+			if w.Version != tc.wantVersion {
+				t.Errorf("version = %d, want %d", w.Version, tc.wantVersion)
+			}
+
+			// UPDATE hanya kolom yang dipilih + version, dengan syarat version lama
+			if len(store.queries) != 1 {
+				t.Fatalf("queries = %v", store.queries)
+			}
+			q := store.queries[0]
+			if !strings.Contains(q, `"name"=$1`) || !strings.Contains(q, `"version"=$2`) || !strings.Contains(q, "version = $3") {
+				t.Errorf("query = %s", q)
+			}
+			if args := store.args[0]; args[1].Value != int64(4) || args[2].Value != int64(3) {
+				t.Errorf("args = %v", args)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := []struct {
+		name    string
+		rows    int64
+		wantErr error
+	}{
+		{"version sama", 1, nil},
+		{"version lama", 0, ErrStale},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &versionStore{rowsAffected: tc.rows}
+			err := Delete(newVersionDB(t, store), &widget{ID: 7, Version: 3}, 3)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Delete = %v, want %v", err, tc.wantErr)
+			}
+			if q := store.queries[0]; !strings.HasPrefix(q, `UPDATE "widgets" SET "deleted_at"`) || !strings.Contains(q, "version = ") {
+				t.Errorf("harus soft delete dengan syarat version: %s", q)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/features/donations/donation/models"
+	"masjidku/internals/features/donations/donation/service"
+	modelMasjid "masjidku/internals/features/masjids/masjid/models"
+	modelUser "masjidku/internals/features/users/user/models"
+	authMw "masjidku/internals/middlewares/auth"
+)
+
+type DonationController struct {
+	DB      *gorm.DB
+	Gateway service.PaymentGateway
+}
+
+func NewDonationController(db *gorm.DB, gateway service.PaymentGateway) *DonationController {
+	return &DonationController{DB: db, Gateway: gateway}
+}
+
+type CreateDonationInput struct {
+	MasjidID    uuid.UUID `json:"masjid_id" validate:"required"`
+	Amount      int64     `json:"amount" validate:"required,min=1000"`
+	DonorName   string    `json:"donor_name" validate:"max=100"`
+	DonorEmail  string    `json:"donor_email" validate:"omitempty,email"`
+	IsAnonymous bool      `json:"is_anonymous"`
+	Message     string    `json:"message" validate:"max=500"`
+}
+
+// POST buat donasi baru + transaksi Midtrans Snap.
+// Bisa dipanggil oleh user yang login (/api/donations) maupun tamu (/donations).
+func (dc *DonationController) CreateDonation(c *fiber.Ctx) error {
+	var input CreateDonationInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	var masjid modelMasjid.MasjidModel
+	if err := dc.DB.First(&masjid, "id = ?", input.MasjidID).Error; err != nil {
+		return apperror.NotFound(apperror.CodeMasjidNotFound)
+	}
+
+	donation := models.DonationModel{
+		MasjidID:    masjid.ID,
+		DonorName:   strings.TrimSpace(input.DonorName),
+		DonorEmail:  input.DonorEmail,
+		IsAnonymous: input.IsAnonymous,
+		Amount:      input.Amount,
+		Message:     input.Message,
+		Status:      models.StatusPending,
+	}
+
+	// Jika login, donasi dikaitkan ke user dan nama donatur diambil dari profil bila kosong
+	if userID, ok := authMw.UserID(c); ok {
+		var user modelUser.UserModel
+		if err := dc.DB.First(&user, "id = ?", userID).Error; err != nil {
+			return apperror.NotFound(apperror.CodeUserNotFound)
+		}
+		donation.UserID = &user.ID
+		if donation.DonorName == "" {
+			donation.DonorName = user.UserName
+			if user.DonationName != nil && *user.DonationName != "" {
+				donation.DonorName = *user.DonationName
+			}
+		}
+		if donation.DonorEmail == "" {
+			donation.DonorEmail = user.Email
+		}
+	}
+
+	if donation.DonorName == "" {
+		if !donation.IsAnonymous {
+			return apperror.BadRequest(apperror.CodeValidation).
+				WithField("donor_name", "required", "donor_name wajib diisi untuk donasi non-anonim")
+		}
+		donation.DonorName = "Hamba Allah"
+	}
+
+	orderID, err := generateOrderID()
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("generate order ID: %w", err))
+	}
+	donation.OrderID = orderID
+
+	snap, err := dc.Gateway.CreateTransaction(c.UserContext(), service.SnapRequest{
+		OrderID:     donation.OrderID,
+		GrossAmount: donation.Amount,
+		DonorName:   donation.DonorName,
+		DonorEmail:  donation.DonorEmail,
+		ItemName:    truncate("Donasi "+masjid.Name, 50),
+	})
+	if err != nil {
+		return apperror.New(fiber.StatusBadGateway, apperror.CodePaymentGateway).
+			WithCause(fmt.Errorf("buat transaksi Midtrans: %w", err))
+	}
+	donation.SnapToken = snap.Token
+	donation.RedirectURL = snap.RedirectURL
+
+	if err := dc.DB.Create(&donation).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("simpan donasi: %w", err))
+	}
+
+	log.Printf("[SUCCESS] Donation created: OrderID=%s, Amount=%d", donation.OrderID, donation.Amount)
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Donation created successfully",
+		"data": fiber.Map{
+			"order_id":     donation.OrderID,
+			"amount":       donation.Amount,
+			"status":       donation.Status,
+			"snap_token":   donation.SnapToken,
+			"redirect_url": donation.RedirectURL,
+		},
+	})
+}
+
+// POST /api/donations/notification - HTTP notification dari Midtrans.
+// Aman dipanggil berulang kali: status final tidak akan berubah mundur.
+func (dc *DonationController) HandleNotification(c *fiber.Ctx) error {
+	var notif service.Notification
+	if err := c.BodyParser(&notif); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody).WithCause(err)
+	}
+
+	if !dc.Gateway.VerifyNotification(notif) {
+		log.Printf("[WARNING] Invalid Midtrans signature for order %s", notif.OrderID)
+		return apperror.Forbidden(apperror.CodeInvalidSignature)
+	}
+
+	nextStatus := mapTransactionStatus(notif.TransactionStatus, notif.FraudStatus)
+	if nextStatus == "" {
+		log.Printf("[WARNING] Unknown Midtrans transaction_status %q for order %s", notif.TransactionStatus, notif.OrderID)
+		return c.JSON(fiber.Map{"message": "Notification ignored"})
+	}
+
+	var donation models.DonationModel
+	err := dc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ?", notif.OrderID).First(&donation).Error; err != nil {
+			return err
+		}
+
+		if !amountMatches(notif.GrossAmount, donation.Amount) {
+			return errAmountMismatch
+		}
+
+		if donation.Status == nextStatus || !donation.CanTransitionTo(nextStatus) {
+			log.Printf("[INFO] Notification for order %s ignored (%s -> %s)", donation.OrderID, donation.Status, nextStatus)
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"status":         nextStatus,
+			"payment_type":   notif.PaymentType,
+			"transaction_id": notif.TransactionID,
+		}
+		if nextStatus == models.StatusSettlement {
+			paidAt := time.Now()
+			if t, err := time.ParseInLocation("2006-01-02 15:04:05", notif.SettlementTime, jakarta); err == nil {
+				paidAt = t
+			}
+			updates["paid_at"] = paidAt
+		}
+
+		log.Printf("[INFO] Donation %s: %s -> %s", donation.OrderID, donation.Status, nextStatus)
+		return tx.Model(&donation).Updates(updates).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound(apperror.CodeDonationNotFound)
+		}
+		if errors.Is(err, errAmountMismatch) {
+			log.Printf("[WARNING] Gross amount mismatch for order %s: %s", notif.OrderID, notif.GrossAmount)
+			return apperror.BadRequest(apperror.CodeAmountMismatch)
+		}
+		return apperror.Internal(fmt.Errorf("proses notifikasi Midtrans: %w", err))
+	}
+
+	return c.JSON(fiber.Map{"message": "Notification processed"})
+}
+
+// GET donasi milik user yang sedang login
+func (dc *DonationController) GetMyDonations(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var donations []models.DonationModel
+	if err := dc.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&donations).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("ambil donasi user: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Donations fetched successfully",
+		"total":   len(donations),
+		"data":    donations,
+	})
+}
+
+// GET donasi untuk satu masjid (pengurus masjid)
+func (dc *DonationController) GetMasjidDonations(c *fiber.Ctx) error {
+	query := dc.DB.Where("masjid_id = ?", c.Locals("masjid_id"))
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var donations []models.DonationModel
+	if err := query.Order("created_at DESC").Find(&donations).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("ambil donasi masjid: %w", err))
+	}
+
+	var total int64
+	for _, d := range donations {
+		if d.Status == models.StatusSettlement {
+			total += d.Amount
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":          "Donations fetched successfully",
+		"total":            len(donations),
+		"total_settlement": total,
+		"data":             donations,
+	})
+}
+
+var (
+	errAmountMismatch = errors.New("gross amount mismatch")
+	jakarta           = time.FixedZone("WIB", 7*60*60)
+)
+
+// mapTransactionStatus menormalisasi transaction_status Midtrans ke status donasi
+func mapTransactionStatus(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "capture":
+		if fraudStatus == "challenge" {
+			return models.StatusPending
+		}
+		if fraudStatus == "" || fraudStatus == "accept" {
+			return models.StatusSettlement
+		}
+		return models.StatusCancel
+	case "settlement":
+		return models.StatusSettlement
+	case "pending":
+		return models.StatusPending
+	case "deny", "cancel", "failure":
+		return models.StatusCancel
+	case "expire":
+		return models.StatusExpire
+	case "refund", "partial_refund":
+		return models.StatusRefund
+	default:
+		return ""
+	}
+}
+
+// amountMatches membandingkan gross_amount Midtrans ("10000.00") dengan nominal donasi (rupiah bulat).
+// Dibaca sebagai desimal, bukan float: harus sama persis dan pecahannya harus .00 ("10000.99" ditolak).
+func amountMatches(grossAmount string, amount int64) bool {
+	whole, frac, hasFrac := strings.Cut(grossAmount, ".")
+	if whole == "" || strings.TrimLeft(whole, "0123456789") != "" {
+		return false
+	}
+	if hasFrac && (frac == "" || len(frac) > 2 || strings.Trim(frac, "0") != "") {
+		return false
+	}
+	value, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return false
+	}
+	return value == amount
+}
+
+func generateOrderID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("DON-%d-%s", time.Now().Unix(), strings.ToUpper(hex.EncodeToString(b))), nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/features/donations/donation/models"
+	"masjidku/internals/features/donations/donation/service"
+	"masjidku/internals/features/donations/donation/service/midtranstest"
+)
+
+const serverKey = "SB-Mid-server-test"
+
+// ============================ fake database ============================
+// donationStore adalah driver database/sql minimal di memori: cukup untuk query yang dijalankan
+// HandleNotification (SELECT ... FOR UPDATE per order_id dan UPDATE per id).
+
+type donationStore struct {
+	mu        sync.Mutex
+	donations map[string]*models.DonationModel // key: order_id
+	updates   []map[string]interface{}
+}
+
+var setColumn = regexp.MustCompile(`"(\w+)"=\$(\d+)`)
+
+func (s *donationStore) Connect(context.Context) (driver.Conn, error) { return &storeConn{s}, nil }
+func (s *donationStore) Driver() driver.Driver                        { return nil }
+
+func (s *donationStore) add(orderID string, amount int64, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.donations[orderID] = &models.DonationModel{ID: uuid.New(), OrderID: orderID, Amount: amount, Status: status}
+}
+
+func (s *donationStore) status(orderID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.donations[orderID].Status
+}
+
+func (s *donationStore) updateCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.updates)
+}
+
+type storeConn struct{ s *donationStore }
+
+func (c *storeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare tidak didukung")
+}
+func (c *storeConn) Close() error              { return nil }
+func (c *storeConn) Begin() (driver.Tx, error) { return storeTx{}, nil }
+func (c *storeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return storeTx{}, nil
+}
+
+func (c *storeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.HasPrefix(query, "UPDATE") {
+		_, err := c.ExecContext(context.Background(), query, args)
+		return &storeRows{}, err
+	}
+	if !strings.Contains(query, `FROM "donations"`) || !strings.Contains(query, "order_id") || len(args) == 0 {
+		return nil, errors.New("query tidak didukung: " + query)
+	}
+
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	rows := &storeRows{columns: []string{"id", "order_id", "amount", "status"}}
+	if d, ok := c.s.donations[args[0].Value.(string)]; ok {
+		rows.values = [][]driver.Value{{d.ID.String(), d.OrderID, d.Amount, d.Status}}
+	}
+	return rows, nil
+}
+
+func (c *storeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.HasPrefix(query, `UPDATE "donations"`) {
+		return nil, errors.New("exec tidak didukung: " + query)
+	}
+
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	set := map[string]interface{}{}
+	for _, m := range setColumn.FindAllStringSubmatch(query, -1) {
+		pos, _ := strconv.Atoi(m[2])
+		set[m[1]] = args[pos-1].Value
+	}
+	// argumen terakhir adalah id pada WHERE
+	id := args[len(args)-1].Value
+	for _, d := range c.s.donations {
+		if d.ID.String() == id {
+			if status, ok := set["status"].(string); ok {
+				d.Status = status
+			}
+		}
+	}
+	c.s.updates = append(c.s.updates, set)
+	return driver.RowsAffected(1), nil
+}
+
+type storeTx struct{}
+
+func (storeTx) Commit() error   { return nil }
+func (storeTx) Rollback() error { return nil }
+
+type storeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *storeRows) Columns() []string { return r.columns }
+func (r *storeRows) Close() error      { return nil }
+func (r *storeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// ============================ helpers ============================
+
+func newTestApp(t *testing.T) (*fiber.App, *donationStore, *midtranstest.Server) {
+	t.Helper()
+	stub := midtranstest.NewServer(serverKey)
+	t.Cleanup(stub.Close)
+
+	store := &donationStore{donations: map[string]*models.DonationModel{}}
+	sqlDB := sql.OpenDB(store)
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	dc := NewDonationController(db, service.NewMidtransGateway(serverKey, stub.URL))
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.ErrorHandler})
+	app.Post("/donations/notification", dc.HandleNotification)
+	return app, store, stub
+}
+
+func notify(t *testing.T, app *fiber.App, n service.Notification) int {
+	t.Helper()
+	body, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/donations/notification", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	return resp.StatusCode
+}
+
+// ============================ tests ============================
+
+func TestHandleNotificationSettlement(t *testing.T) {
+	app, store, stub := newTestApp(t)
+	store.add("DON-1", 10000, models.StatusPending)
+
+	n := stub.Notification("DON-1", "settlement", "10000.00")
+	n.SettlementTime = "2026-10-17 09:30:00"
+	if code := notify(t, app, n); code != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if got := store.status("DON-1"); got != models.StatusSettlement {
+		t.Errorf("status donasi = %s, want settlement", got)
+	}
+	if store.updateCount() != 1 || store.updates[0]["paid_at"] == nil || store.updates[0]["transaction_id"] != "trx-DON-1" {
+		t.Errorf("update = %+v", store.updates)
+	}
+}
+
+func TestHandleNotificationSignatureMismatch(t *testing.T) {
+	app, store, stub := newTestApp(t)
+	store.add("DON-1", 10000, models.StatusPending)
+
+	forged := stub.Notification("DON-1", "settlement", "10000.00")
+	forged.SignatureKey = midtranstest.Sign(forged, "server-key-penyerang")
+	if code := notify(t, app, forged); code != fiber.StatusForbidden {
+		t.Errorf("signature palsu: status = %d, want 403", code)
+	}
+
+	tampered := stub.Notification("DON-1", "settlement", "10000.00")
+	tampered.GrossAmount = "500.00"
+	if code := notify(t, app, tampered); code != fiber.StatusForbidden {
+		t.Errorf("nominal diubah: status = %d, want 403", code)
+	}
+
+	if store.updateCount() != 0 || store.status("DON-1") != models.StatusPending {
+		t.Errorf("donasi tidak boleh berubah: status %s, %d update", store.status("DON-1"), store.updateCount())
+	}
+}
+
+func TestHandleNotificationAmountMismatch(t *testing.T) {
+	for _, gross := range []string{"9999.00", "10000.99", "10000.5", "10001", "1e4", ""} {
+		t.Run(gross, func(t *testing.T) {
+			app, store, stub := newTestApp(t)
+			store.add("DON-1", 10000, models.StatusPending)
+
+			// Ditandatangani dengan benar: yang salah nominalnya, bukan signature-nya
+			if code := notify(t, app, stub.Notification("DON-1", "settlement", gross)); code != fiber.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+			if store.updateCount() != 0 {
+				t.Errorf("donasi tidak boleh di-update: %+v", store.updates)
+			}
+		})
+	}
+}
+
+func TestHandleNotificationUnknownOrder(t *testing.T) {
+	app, _, stub := newTestApp(t)
+	if code := notify(t, app, stub.Notification("DON-404", "settlement", "10000.00")); code != fiber.StatusNotFound {
+		t.Errorf("status = %d, want 404", code)
+	}
+}
+
+func TestHandleNotificationDuplicate(t *testing.T) {
+	app, store, stub := newTestApp(t)
+	store.add("DON-1", 10000, models.StatusPending)
+
+	n := stub.Notification("DON-1", "settlement", "10000.00")
+	for i := 0; i < 3; i++ {
+		if code := notify(t, app, n); code != fiber.StatusOK {
+			t.Fatalf("notifikasi ke-%d: status = %d, want 200", i+1, code)
+		}
+	}
+	if got := store.updateCount(); got != 1 {
+		t.Errorf("notifikasi berulang harus di-update sekali, tercatat %d update", got)
+	}
+}
+
+func TestHandleNotificationLate(t *testing.T) {
+	cases := []struct {
+		name    string
+		current string
+		late    string // transaction_status Midtrans yang datang terlambat
+		want    string
+		updates int
+	}{
+		{"pending setelah settlement", models.StatusSettlement, "pending", models.StatusSettlement, 0},
+		{"expire setelah settlement", models.StatusSettlement, "expire", models.StatusSettlement, 0},
+		{"settlement setelah expire", models.StatusExpire, "settlement", models.StatusExpire, 0},
+		{"settlement setelah cancel", models.StatusCancel, "settlement", models.StatusCancel, 0},
+		{"settlement setelah refund", models.StatusRefund, "settlement", models.StatusRefund, 0},
+		{"refund setelah settlement", models.StatusSettlement, "refund", models.StatusRefund, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app, store, stub := newTestApp(t)
+			store.add("DON-1", 10000, tc.current)
+
+			if code := notify(t, app, stub.Notification("DON-1", tc.late, "10000.00")); code != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200", code)
+			}
+			if got := store.status("DON-1"); got != tc.want {
+				t.Errorf("status donasi = %s, want %s", got, tc.want)
+			}
+			if got := store.updateCount(); got != tc.updates {
+				t.Errorf("%d update, want %d", got, tc.updates)
+			}
+		})
+	}
+}
+
+func TestAmountMatches(t *testing.T) {
+	cases := []struct {
+		gross  string
+		amount int64
+		want   bool
+	}{
+		{"10000.00", 10000, true},
+		{"10000", 10000, true},
+		{"10000.0", 10000, true},
+		{"10000.01", 10000, false},
+		{"10000.99", 10000, false},
+		{"10000.000", 10000, false},
+		{"10000.", 10000, false},
+		{".00", 0, false},
+		{"-10000.00", -10000, false},
+		{"+10000.00", 10000, false},
+		{"1e4", 10000, false},
+		{" 10000.00", 10000, false},
+		{"99999999999999999999.00", 10000, false},
+		{"9999.00", 10000, false},
+		{"", 0, false},
+	}
+	for _, tc := range cases {
+		if got := amountMatches(tc.gross, tc.amount); got != tc.want {
+			t.Errorf("amountMatches(%q, %d) = %v, want %v", tc.gross, tc.amount, got, tc.want)
+		}
+	}
+}
+
+func TestMapTransactionStatus(t *testing.T) {
+	cases := []struct {
+		transaction, fraud, want string
+	}{
+		{"capture", "accept", models.StatusSettlement},
+		{"capture", "", models.StatusSettlement},
+		{"capture", "challenge", models.StatusPending},
+		{"capture", "deny", models.StatusCancel},
+		{"settlement", "", models.StatusSettlement},
+		{"pending", "", models.StatusPending},
+		{"deny", "", models.StatusCancel},
+		{"cancel", "", models.StatusCancel},
+		{"failure", "", models.StatusCancel},
+		{"expire", "", models.StatusExpire},
+		{"refund", "", models.StatusRefund},
+		{"partial_refund", "", models.StatusRefund},
+		{"authorize", "", ""},
+	}
+	for _, tc := range cases {
+		if got := mapTransactionStatus(tc.transaction, tc.fraud); got != tc.want {
+			t.Errorf("mapTransactionStatus(%q, %q) = %q, want %q", tc.transaction, tc.fraud, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status donasi, mengikuti transaction_status Midtrans yang sudah dinormalisasi
+const (
+	StatusPending    = "pending"
+	StatusSettlement = "settlement"
+	StatusExpire     = "expire"
+	StatusCancel     = "cancel"
+	StatusRefund     = "refund"
+)
+
+// AnonymousDonorName ditampilkan untuk donasi anonim dan menggantikan nama donatur
+// yang akunnya sudah dihapus permanen
+const AnonymousDonorName = "Hamba Allah"
+
+// DonationModel merepresentasikan tabel donations di database
+type DonationModel struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OrderID       string     `gorm:"size:64;not null;uniqueIndex" json:"order_id"` // payment reference yang dikirim ke Midtrans
+	MasjidID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"masjid_id"`
+	UserID        *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"` // nil untuk donatur tamu
+	DonorName     string     `gorm:"size:100;not null" json:"donor_name"`
+	DonorEmail    string     `gorm:"size:255" json:"donor_email,omitempty"`
+	IsAnonymous   bool       `gorm:"not null;default:false" json:"is_anonymous"`
+	Amount        int64      `gorm:"not null" json:"amount"` // dalam Rupiah
+	Message       string     `gorm:"size:500" json:"message,omitempty"`
+	Status        string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	PaymentType   string     `gorm:"size:50" json:"payment_type,omitempty"`
+	TransactionID string     `gorm:"size:100" json:"transaction_id,omitempty"`
+	SnapToken     string     `gorm:"size:255" json:"snap_token,omitempty"`
+	RedirectURL   string     `gorm:"size:500" json:"redirect_url,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (DonationModel) TableName() string {
+	return "donations"
+}
+
+// PublicDonorName mengembalikan nama yang boleh ditampilkan ke publik
+func (d *DonationModel) PublicDonorName() string {
+	if d.IsAnonymous {
+		return AnonymousDonorName
+	}
+	return d.DonorName
+}
+
+// CanTransitionTo menentukan apakah status boleh berpindah ke status berikutnya.
+// Notifikasi yang datang berulang atau terlambat tidak boleh memundurkan status final.
+func (d *DonationModel) CanTransitionTo(next string) bool {
+	switch d.Status {
+	case StatusPending:
+		return next != StatusPending
+	case StatusSettlement:
+		return next == StatusRefund
+	default:
+		// expire, cancel dan refund adalah status final
+		return false
+	}
+}
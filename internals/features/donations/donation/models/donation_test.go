@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestCanTransitionTo(t *testing.T) {
+	all := []string{StatusPending, StatusSettlement, StatusExpire, StatusCancel, StatusRefund}
+	allowed := map[string]map[string]bool{
+		StatusPending:    {StatusSettlement: true, StatusExpire: true, StatusCancel: true, StatusRefund: true},
+		StatusSettlement: {StatusRefund: true},
+		StatusExpire:     {},
+		StatusCancel:     {},
+		StatusRefund:     {},
+	}
+
+	for _, from := range all {
+		for _, to := range all {
+			d := DonationModel{Status: from}
+			if got, want := d.CanTransitionTo(to), allowed[from][to]; got != want {
+				t.Errorf("%s -> %s = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
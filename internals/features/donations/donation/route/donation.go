@@ -0,0 +1,56 @@
+package route
+
+import (
+	"masjidku/internals/configs"
+	"masjidku/internals/constants"
+	donationController "masjidku/internals/features/donations/donation/controller"
+	"masjidku/internals/features/donations/donation/service"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	authMw "masjidku/internals/middlewares/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func DonationRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy) {
+	gateway := service.NewMidtransGateway(cfg.Midtrans.ServerKey, cfg.Midtrans.BaseURL)
+
+	donationCtrl := donationController.NewDonationController(db, gateway)
+	authMiddleware := authMw.AuthMiddleware(db, tokens, policy)
+	// Donasi di atas ambang hanya untuk akun dengan email terverifikasi (tamu ditolak)
+	verifiedAboveThreshold := authMw.RequireVerifiedEmail(db, amountAtLeast(cfg.Donation.VerifiedEmailThreshold))
+
+	// 🔓 Donasi tamu (tanpa login)
+	app.Post("/donations", verifiedAboveThreshold, donationCtrl.CreateDonation)
+
+	donations := app.Group("/api/donations")
+
+	// 🔔 Webhook Midtrans (tidak memakai AuthMiddleware, diverifikasi lewat signature_key)
+	donations.Post("/notification", donationCtrl.HandleNotification)
+
+	// 🔐 Donasi user yang login; pembayaran tidak bisa dibuat admin yang login sebagai user
+	donations.Post("/", authMiddleware, authMw.DenyImpersonation(), verifiedAboveThreshold, donationCtrl.CreateDonation)
+	donations.Get("/me", authMiddleware, donationCtrl.GetMyDonations)
+	donations.Get("/masjid/:slug", authMiddleware, authMw.MasjidContext(db),
+		authMw.RequirePermission(constants.PermDonationsRead), donationCtrl.GetMasjidDonations)
+}
+
+// amountAtLeast bernilai true jika field "amount" pada body request >= threshold.
+// Body dibaca dengan BodyParser seperti di controller (JSON, form, multipart), jadi ganti
+// Content-Type tidak bisa melewati pengecekan; body yang gagal di-parse dianggap di atas ambang.
+// Threshold <= 0 menonaktifkan pengecekan.
+func amountAtLeast(threshold int64) func(c *fiber.Ctx) bool {
+	return func(c *fiber.Ctx) bool {
+		if threshold <= 0 {
+			return false
+		}
+		var body struct {
+			Amount int64 `json:"amount" form:"amount"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return true
+		}
+		return body.Amount >= threshold
+	}
+}
@@ -0,0 +1,62 @@
+package route
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestAmountAtLeast: ambang verifikasi email harus berlaku untuk semua Content-Type yang
+// diterima BodyParser di controller, bukan hanya JSON
+func TestAmountAtLeast(t *testing.T) {
+	multipartBody := func(amount string) (string, string) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		_ = w.WriteField("amount", amount)
+		_ = w.Close()
+		return buf.String(), w.FormDataContentType()
+	}
+	bigMultipart, multipartType := multipartBody("1000000")
+
+	cases := []struct {
+		name        string
+		threshold   int64
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"json di atas ambang", 500000, fiber.MIMEApplicationJSON, `{"amount":1000000}`, true},
+		{"json tepat ambang", 500000, fiber.MIMEApplicationJSON, `{"amount":500000}`, true},
+		{"json di bawah ambang", 500000, fiber.MIMEApplicationJSON, `{"amount":10000}`, false},
+		{"form di atas ambang", 500000, fiber.MIMEApplicationForm, "amount=1000000&masjid_id=x", true},
+		{"form di bawah ambang", 500000, fiber.MIMEApplicationForm, "amount=10000", false},
+		{"multipart di atas ambang", 500000, multipartType, bigMultipart, true},
+		{"json rusak", 500000, fiber.MIMEApplicationJSON, `{"amount":`, true},
+		{"amount bukan angka", 500000, fiber.MIMEApplicationForm, "amount=banyak", true},
+		{"content-type tidak dikenal", 500000, "text/plain", "amount=1000000", true},
+		{"ambang nonaktif", 0, fiber.MIMEApplicationForm, "amount=1000000", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got bool
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				got = amountAtLeast(tc.threshold)(c)
+				return nil
+			})
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			if _, err := app.Test(req); err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("amountAtLeast = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
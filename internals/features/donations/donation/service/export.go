@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/dataexport"
+	"masjidku/internals/features/donations/donation/models"
+)
+
+func init() {
+	dataexport.Register("donations", exportDonations)
+}
+
+// donationExport adalah donasi user tanpa data internal payment gateway (snap token, redirect URL)
+type donationExport struct {
+	OrderID     string     `json:"order_id"`
+	MasjidID    uuid.UUID  `json:"masjid_id"`
+	DonorName   string     `json:"donor_name"`
+	DonorEmail  string     `json:"donor_email,omitempty"`
+	IsAnonymous bool       `json:"is_anonymous"`
+	Amount      int64      `json:"amount"`
+	Message     string     `json:"message,omitempty"`
+	Status      string     `json:"status"`
+	PaymentType string     `json:"payment_type,omitempty"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func exportDonations(ctx context.Context, db *gorm.DB, userID uuid.UUID, archive *dataexport.Archive) error {
+	var donations []models.DonationModel
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&donations).Error; err != nil {
+		return err
+	}
+
+	out := make([]donationExport, 0, len(donations))
+	rows := make([][]string, 0, len(donations))
+	for _, d := range donations {
+		out = append(out, donationExport{
+			OrderID: d.OrderID, MasjidID: d.MasjidID, DonorName: d.DonorName, DonorEmail: d.DonorEmail,
+			IsAnonymous: d.IsAnonymous, Amount: d.Amount, Message: d.Message, Status: d.Status,
+			PaymentType: d.PaymentType, PaidAt: d.PaidAt, CreatedAt: d.CreatedAt,
+		})
+		rows = append(rows, []string{
+			d.OrderID, d.MasjidID.String(), strconv.FormatInt(d.Amount, 10), d.Status,
+			d.PaymentType, dataexport.FormatTime(d.PaidAt), dataexport.FormatTime(&d.CreatedAt),
+		})
+	}
+	if err := archive.JSON("donations.json", out); err != nil {
+		return err
+	}
+	return archive.CSV("donations.csv", []string{
+		"order_id", "masjid_id", "amount", "status", "payment_type", "paid_at", "created_at",
+	}, rows)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	MidtransSandboxURL    = "https://app.sandbox.midtrans.com"
+	MidtransProductionURL = "https://app.midtrans.com"
+)
+
+// MidtransGateway adalah implementasi PaymentGateway menggunakan Midtrans Snap API
+type MidtransGateway struct {
+	ServerKey  string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewMidtransGateway membuat gateway Midtrans. baseURL bisa diarahkan ke server palsu lokal.
+func NewMidtransGateway(serverKey, baseURL string) *MidtransGateway {
+	return &MidtransGateway{
+		ServerKey:  serverKey,
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type snapTransactionRequest struct {
+	TransactionDetails struct {
+		OrderID     string `json:"order_id"`
+		GrossAmount int64  `json:"gross_amount"`
+	} `json:"transaction_details"`
+	CustomerDetails struct {
+		FirstName string `json:"first_name,omitempty"`
+		Email     string `json:"email,omitempty"`
+	} `json:"customer_details"`
+	ItemDetails []snapItem `json:"item_details"`
+}
+
+type snapItem struct {
+	ID       string `json:"id"`
+	Price    int64  `json:"price"`
+	Quantity int    `json:"quantity"`
+	Name     string `json:"name"`
+}
+
+// CreateTransaction membuat transaksi Snap dan mengembalikan token + redirect URL
+func (m *MidtransGateway) CreateTransaction(ctx context.Context, req SnapRequest) (*SnapResponse, error) {
+	var body snapTransactionRequest
+	body.TransactionDetails.OrderID = req.OrderID
+	body.TransactionDetails.GrossAmount = req.GrossAmount
+	body.CustomerDetails.FirstName = req.DonorName
+	body.CustomerDetails.Email = req.DonorEmail
+	body.ItemDetails = []snapItem{{
+		ID:       "donation",
+		Price:    req.GrossAmount,
+		Quantity: 1,
+		Name:     req.ItemName,
+	}}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snap request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.BaseURL+"/snap/v1/transactions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snap request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.SetBasicAuth(m.ServerKey, "")
+
+	resp, err := m.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute snap request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snap response: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("snap request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var snapResp SnapResponse
+	if err := json.Unmarshal(respBody, &snapResp); err != nil {
+		return nil, fmt.Errorf("failed to parse snap response: %w", err)
+	}
+	if snapResp.Token == "" {
+		return nil, fmt.Errorf("no snap token received from Midtrans")
+	}
+
+	return &snapResp, nil
+}
+
+// VerifyNotification mengecek signature_key = SHA512(order_id + status_code + gross_amount + server_key)
+func (m *MidtransGateway) VerifyNotification(n Notification) bool {
+	return VerifyMidtransSignature(n, m.ServerKey)
+}
+
+// VerifyMidtransSignature dipisah agar bisa dipakai ulang oleh gateway lain / server palsu
+func VerifyMidtransSignature(n Notification, serverKey string) bool {
+	if n.SignatureKey == "" || serverKey == "" {
+		return false
+	}
+	sum := sha512.Sum512([]byte(n.OrderID + n.StatusCode + n.GrossAmount + serverKey))
+	expected := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(n.SignatureKey))) == 1
+}
@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"masjidku/internals/features/donations/donation/service"
+	"masjidku/internals/features/donations/donation/service/midtranstest"
+)
+
+const serverKey = "SB-Mid-server-test"
+
+func TestCreateTransaction(t *testing.T) {
+	stub := midtranstest.NewServer(serverKey)
+	defer stub.Close()
+	gateway := service.NewMidtransGateway(serverKey, stub.URL)
+
+	resp, err := gateway.CreateTransaction(context.Background(), service.SnapRequest{
+		OrderID: "DON-1", GrossAmount: 50000, DonorName: "Fulan", ItemName: "Donasi Masjid",
+	})
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if resp.Token != "snap-DON-1" || !strings.HasPrefix(resp.RedirectURL, stub.URL) {
+		t.Errorf("response = %+v", resp)
+	}
+	got := stub.Transactions()
+	if len(got) != 1 || got[0].OrderID != "DON-1" || got[0].GrossAmount != 50000 {
+		t.Errorf("transaksi di Midtrans = %+v", got)
+	}
+}
+
+func TestCreateTransactionErrors(t *testing.T) {
+	stub := midtranstest.NewServer(serverKey)
+	defer stub.Close()
+
+	t.Run("server key salah", func(t *testing.T) {
+		gateway := service.NewMidtransGateway("server-key-lain", stub.URL)
+		if _, err := gateway.CreateTransaction(context.Background(), service.SnapRequest{OrderID: "DON-2", GrossAmount: 1000}); err == nil {
+			t.Fatal("harus error untuk server key yang ditolak")
+		}
+	})
+	t.Run("Midtrans gagal", func(t *testing.T) {
+		stub.FailNext(http.StatusInternalServerError)
+		gateway := service.NewMidtransGateway(serverKey, stub.URL)
+		if _, err := gateway.CreateTransaction(context.Background(), service.SnapRequest{OrderID: "DON-3", GrossAmount: 1000}); err == nil {
+			t.Fatal("harus error untuk status 500")
+		}
+	})
+	if n := len(stub.Transactions()); n != 0 {
+		t.Errorf("tidak boleh ada transaksi tercatat, ada %d", n)
+	}
+}
+
+func TestVerifyMidtransSignature(t *testing.T) {
+	stub := midtranstest.NewServer(serverKey)
+	defer stub.Close()
+	valid := stub.Notification("DON-1", "settlement", "10000.00")
+
+	tampered := valid
+	tampered.GrossAmount = "1000000.00"
+	upper := valid
+	upper.SignatureKey = strings.ToUpper(valid.SignatureKey)
+	unsigned := valid
+	unsigned.SignatureKey = ""
+
+	cases := []struct {
+		name      string
+		n         service.Notification
+		serverKey string
+		want      bool
+	}{
+		{"valid", valid, serverKey, true},
+		{"huruf besar", upper, serverKey, true},
+		{"nominal diubah", tampered, serverKey, false},
+		{"server key lain", valid, "server-key-lain", false},
+		{"tanpa signature", unsigned, serverKey, false},
+		{"server key kosong", valid, "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := service.VerifyMidtransSignature(tc.n, tc.serverKey); got != tc.want {
+				t.Errorf("VerifyMidtransSignature = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package midtranstest adalah server Midtrans palsu untuk test (seperti net/http/httptest):
+// melayani Snap API /snap/v1/transactions dan membuat HTTP notification yang ditandatangani
+// dengan server key yang sama, sehingga MidtransGateway bisa diuji tanpa sandbox.
+package midtranstest
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"masjidku/internals/features/donations/donation/service"
+)
+
+// Transaction adalah transaksi Snap yang diterima server palsu
+type Transaction struct {
+	OrderID     string
+	GrossAmount int64
+}
+
+// Server adalah Midtrans palsu. Arahkan service.NewMidtransGateway ke Server.URL.
+type Server struct {
+	*httptest.Server
+	ServerKey string
+
+	mu           sync.Mutex
+	transactions []Transaction
+	failNext     int // status HTTP untuk request Snap berikutnya; 0 = sukses
+}
+
+// NewServer menjalankan server palsu; panggil Close setelah selesai
+func NewServer(serverKey string) *Server {
+	s := &Server{ServerKey: serverKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/snap/v1/transactions", s.handleSnap)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// FailNext membuat request Snap berikutnya dibalas dengan status tersebut
+func (s *Server) FailNext(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = status
+}
+
+// Transactions mengembalikan transaksi yang sudah dibuat lewat Snap API
+func (s *Server) Transactions() []Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Transaction(nil), s.transactions...)
+}
+
+// Notification membuat HTTP notification bertanda tangan untuk order tersebut
+func (s *Server) Notification(orderID, transactionStatus, grossAmount string) service.Notification {
+	n := service.Notification{
+		OrderID:           orderID,
+		StatusCode:        "200",
+		GrossAmount:       grossAmount,
+		TransactionStatus: transactionStatus,
+		TransactionID:     "trx-" + orderID,
+		PaymentType:       "bank_transfer",
+	}
+	n.SignatureKey = Sign(n, s.ServerKey)
+	return n
+}
+
+// Sign menghitung signature_key = SHA512(order_id + status_code + gross_amount + server_key)
+func Sign(n service.Notification, serverKey string) string {
+	sum := sha512.Sum512([]byte(n.OrderID + n.StatusCode + n.GrossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Server) handleSnap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if key, _, ok := r.BasicAuth(); !ok || key != s.ServerKey {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error_messages": []string{"Access denied"}})
+		return
+	}
+
+	var body struct {
+		TransactionDetails struct {
+			OrderID     string `json:"order_id"`
+			GrossAmount int64  `json:"gross_amount"`
+		} `json:"transaction_details"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.TransactionDetails.OrderID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error_messages": []string{"invalid request"}})
+		return
+	}
+
+	s.mu.Lock()
+	fail := s.failNext
+	s.failNext = 0
+	if fail == 0 {
+		s.transactions = append(s.transactions, Transaction{
+			OrderID:     body.TransactionDetails.OrderID,
+			GrossAmount: body.TransactionDetails.GrossAmount,
+		})
+	}
+	s.mu.Unlock()
+	if fail != 0 {
+		writeJSON(w, fail, map[string]interface{}{"error_messages": []string{"simulated failure"}})
+		return
+	}
+
+	orderID := body.TransactionDetails.OrderID
+	writeJSON(w, http.StatusCreated, service.SnapResponse{
+		Token:       "snap-" + orderID,
+		RedirectURL: s.URL + "/snap/v2/vtweb/snap-" + orderID,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,38 @@
+package service
+
+import "context"
+
+// SnapRequest adalah data yang dibutuhkan untuk membuat transaksi pembayaran
+type SnapRequest struct {
+	OrderID     string
+	GrossAmount int64
+	DonorName   string
+	DonorEmail  string
+	ItemName    string
+}
+
+// SnapResponse adalah hasil pembuatan transaksi pembayaran
+type SnapResponse struct {
+	Token       string `json:"token"`
+	RedirectURL string `json:"redirect_url"`
+}
+
+// Notification adalah payload HTTP notification (webhook) dari payment gateway
+type Notification struct {
+	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	TransactionStatus string `json:"transaction_status"`
+	TransactionID     string `json:"transaction_id"`
+	FraudStatus       string `json:"fraud_status"`
+	PaymentType       string `json:"payment_type"`
+	SettlementTime    string `json:"settlement_time"`
+}
+
+// PaymentGateway membungkus payment gateway (Midtrans) agar bisa diganti dengan
+// server palsu lokal saat testing, tanpa harus memanggil sandbox.
+type PaymentGateway interface {
+	CreateTransaction(ctx context.Context, req SnapRequest) (*SnapResponse, error)
+	VerifyNotification(n Notification) bool
+}
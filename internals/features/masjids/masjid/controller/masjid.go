@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/constants"
+	"masjidku/internals/features/masjids/masjid/models"
+	authMw "masjidku/internals/middlewares/auth"
+)
+
+type MasjidController struct {
+	DB *gorm.DB
+}
+
+func NewMasjidController(db *gorm.DB) *MasjidController {
+	return &MasjidController{DB: db}
+}
+
+// MasjidInput adalah field yang boleh diisi saat membuat / mengubah masjid
+type MasjidInput struct {
+	Name      string   `json:"name" validate:"required,min=3,max=100"`
+	Address   string   `json:"address" validate:"max=255"`
+	City      string   `json:"city" validate:"max=100"`
+	Latitude  *float64 `json:"latitude" validate:"omitempty,latitude"`
+	Longitude *float64 `json:"longitude" validate:"omitempty,longitude"`
+	Timezone  string   `json:"timezone" validate:"omitempty,timezone"`
+	Phone     string   `json:"phone" validate:"max=20"`
+	Email     string   `json:"email" validate:"omitempty,email"`
+	Website   string   `json:"website" validate:"omitempty,url"`
+}
+
+func (in MasjidInput) apply(m *models.MasjidModel) {
+	m.Name = in.Name
+	m.Address = in.Address
+	m.City = in.City
+	m.Latitude = in.Latitude
+	m.Longitude = in.Longitude
+	if in.Timezone != "" {
+		m.Timezone = in.Timezone
+	}
+	m.Phone = in.Phone
+	m.Email = in.Email
+	m.Website = in.Website
+}
+
+// GET all masjids
+func (mc *MasjidController) GetMasjids(c *fiber.Ctx) error {
+	var masjids []models.MasjidModel
+	if err := mc.DB.Order("name ASC").Find(&masjids).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("ambil masjid: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Masjids fetched successfully",
+		"total":   len(masjids),
+		"data":    masjids,
+	})
+}
+
+// GET masjid by slug (masjid sudah di-resolve oleh auth.MasjidContext)
+func (mc *MasjidController) GetMasjid(c *fiber.Ctx) error {
+	var masjid models.MasjidModel
+	if err := mc.DB.First(&masjid, "id = ?", c.Locals("masjid_id")).Error; err != nil {
+		return apperror.NotFound(apperror.CodeMasjidNotFound)
+	}
+
+	myRole := ""
+	if principal, ok := authMw.PrincipalFrom(c); ok {
+		myRole = principal.CurrentMasjidRole()
+	}
+	return c.JSON(fiber.Map{
+		"message": "Masjid fetched successfully",
+		"data":    masjid,
+		"my_role": myRole,
+	})
+}
+
+// POST create masjid, pembuatnya otomatis menjadi owner masjid tersebut
+func (mc *MasjidController) CreateMasjid(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var input MasjidInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	masjid := models.MasjidModel{CreatedBy: &userID, Timezone: "Asia/Jakarta"}
+	input.apply(&masjid)
+
+	err := mc.DB.Transaction(func(tx *gorm.DB) error {
+		slug, err := uniqueSlug(tx, input.Name)
+		if err != nil {
+			return err
+		}
+		masjid.Slug = slug
+
+		if err := tx.Create(&masjid).Error; err != nil {
+			return err
+		}
+
+		owner := models.MasjidMemberModel{
+			MasjidID:   masjid.ID,
+			UserID:     userID,
+			Role:       constants.RoleOwner,
+			Status:     models.MemberStatusActive,
+			AcceptedAt: &masjid.CreatedAt,
+		}
+		return tx.Create(&owner).Error
+	})
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("buat masjid: %w", err))
+	}
+
+	log.Printf("[SUCCESS] Masjid created: ID=%v, Slug=%s", masjid.ID, masjid.Slug)
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Masjid created successfully",
+		"data":    masjid,
+	})
+}
+
+// PUT update masjid
+func (mc *MasjidController) UpdateMasjid(c *fiber.Ctx) error {
+	var masjid models.MasjidModel
+	if err := mc.DB.First(&masjid, "id = ?", c.Locals("masjid_id")).Error; err != nil {
+		return apperror.NotFound(apperror.CodeMasjidNotFound)
+	}
+
+	var input MasjidInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	// Slug sengaja tidak diubah agar URL yang sudah dibagikan tetap valid
+	input.apply(&masjid)
+
+	if err := mc.DB.Save(&masjid).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("update masjid: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Masjid updated successfully",
+		"data":    masjid,
+	})
+}
+
+// PUT pengaturan keamanan masjid (khusus owner)
+func (mc *MasjidController) UpdateSecurity(c *fiber.Ctx) error {
+	var input struct {
+		RequireStaffMFA *bool `json:"require_staff_mfa" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	// Owner harus sudah login dengan 2FA agar tidak mengunci dirinya sendiri
+	if *input.RequireStaffMFA {
+		if principal, ok := authMw.PrincipalFrom(c); !ok || !principal.MFA {
+			return apperror.Forbidden(apperror.CodeMFARequired).
+				WithDetail("Aktifkan 2FA dan login ulang sebelum mewajibkan 2FA untuk pengurus")
+		}
+	}
+
+	if err := mc.DB.Model(&models.MasjidModel{}).Where("id = ?", c.Locals("masjid_id")).
+		Update("require_staff_mfa", *input.RequireStaffMFA).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("update keamanan masjid: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message":           "Masjid security updated successfully",
+		"require_staff_mfa": *input.RequireStaffMFA,
+	})
+}
+
+// DELETE masjid (soft delete)
+func (mc *MasjidController) DeleteMasjid(c *fiber.Ctx) error {
+	masjidID := c.Locals("masjid_id")
+
+	err := mc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("masjid_id = ?", masjidID).Delete(&models.MasjidMemberModel{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.MasjidModel{}, "id = ?", masjidID).Error
+	})
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("hapus masjid: %w", err))
+	}
+
+	log.Printf("[SUCCESS] Masjid with ID %v deleted\n", masjidID)
+	return c.JSON(fiber.Map{
+		"message": "Masjid deleted successfully",
+	})
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// uniqueSlug membuat slug dari nama masjid dan menambahkan suffix angka jika sudah dipakai
+func uniqueSlug(db *gorm.DB, name string) (string, error) {
+	base := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "masjid"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := db.Unscoped().Model(&models.MasjidModel{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = base + "-" + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/constants"
+	"masjidku/internals/features/masjids/masjid/models"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	modelUser "masjidku/internals/features/users/user/models"
+	authMw "masjidku/internals/middlewares/auth"
+)
+
+type MasjidMemberController struct {
+	DB     *gorm.DB
+	Policy *rbacService.Policy
+}
+
+func NewMasjidMemberController(db *gorm.DB, policy *rbacService.Policy) *MasjidMemberController {
+	return &MasjidMemberController{DB: db, Policy: policy}
+}
+
+// Role anggota harus terdaftar di tabel roles (bawaan atau custom), kecuali role admin platform
+type InviteMemberInput struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,max=20"`
+}
+
+type UpdateMemberRoleInput struct {
+	Role string `json:"role" validate:"required,max=20"`
+}
+
+// assignableRole bernilai true jika role boleh diberikan kepada anggota masjid: role terdaftar
+// dan tidak punya permission platform, termasuk role custom yang mewarisi admin
+func (mc *MasjidMemberController) assignableRole(role string) bool {
+	return role != constants.RoleAdmin && mc.Policy.RoleExists(role) &&
+		!mc.Policy.GrantsAny(role, constants.PlatformPermissions...)
+}
+
+// GET anggota masjid
+func (mc *MasjidMemberController) GetMembers(c *fiber.Ctx) error {
+	var members []models.MasjidMemberModel
+	if err := mc.DB.Where("masjid_id = ?", c.Locals("masjid_id")).Order("created_at ASC").Find(&members).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("ambil anggota masjid: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Members fetched successfully",
+		"total":   len(members),
+		"data":    members,
+	})
+}
+
+// POST undang user (berdasarkan email) menjadi anggota masjid dengan role tertentu
+func (mc *MasjidMemberController) InviteMember(c *fiber.Ctx) error {
+	inviter, _ := authMw.PrincipalFrom(c)
+	inviterID := inviter.UserID
+	masjidID := c.Locals("masjid_id").(uuid.UUID)
+
+	var input InviteMemberInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	if !mc.assignableRole(input.Role) {
+		return apperror.BadRequest(apperror.CodeInvalidMasjidRole)
+	}
+
+	// Hanya yang boleh mengatur role anggota (owner) yang boleh mengangkat owner lain
+	if input.Role == constants.RoleOwner && !inviter.Can(constants.PermMembersManageRoles) {
+		return apperror.Forbidden(apperror.CodeForbidden).WithDetail("Hanya owner yang dapat mengundang owner")
+	}
+
+	var user modelUser.UserModel
+	if err := mc.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		return apperror.NotFound(apperror.CodeUserNotFound)
+	}
+
+	var existing models.MasjidMemberModel
+	err := mc.DB.Where("masjid_id = ? AND user_id = ?", masjidID, user.ID).First(&existing).Error
+	if err == nil {
+		if existing.Status == models.MemberStatusActive {
+			return apperror.Conflict(apperror.CodeAlreadyMember)
+		}
+		return apperror.Conflict(apperror.CodeAlreadyInvited)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Internal(fmt.Errorf("cek keanggotaan: %w", err))
+	}
+
+	member := models.MasjidMemberModel{
+		MasjidID:  masjidID,
+		UserID:    user.ID,
+		Role:      input.Role,
+		Status:    models.MemberStatusInvited,
+		InvitedBy: &inviterID,
+	}
+	if err := mc.DB.Create(&member).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("undang anggota: %w", err))
+	}
+
+	log.Printf("[SUCCESS] User %v invited to masjid %v as %s", user.ID, masjidID, input.Role)
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Invitation sent successfully",
+		"data":    member,
+	})
+}
+
+// PUT ubah role anggota masjid
+func (mc *MasjidMemberController) UpdateMemberRole(c *fiber.Ctx) error {
+	masjidID := c.Locals("masjid_id").(uuid.UUID)
+	memberUserID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidID)
+	}
+
+	var input UpdateMemberRoleInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	if !mc.assignableRole(input.Role) {
+		return apperror.BadRequest(apperror.CodeInvalidMasjidRole)
+	}
+
+	var member models.MasjidMemberModel
+	if err := mc.DB.Where("masjid_id = ? AND user_id = ?", masjidID, memberUserID).First(&member).Error; err != nil {
+		return apperror.NotFound(apperror.CodeMemberNotFound)
+	}
+
+	if member.Role == constants.RoleOwner && input.Role != constants.RoleOwner {
+		if err := mc.ensureAnotherOwner(masjidID, memberUserID); err != nil {
+			return err
+		}
+	}
+
+	member.Role = input.Role
+	if err := mc.DB.Save(&member).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("update role anggota: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Member role updated successfully",
+		"data":    member,
+	})
+}
+
+// DELETE keluarkan anggota dari masjid
+func (mc *MasjidMemberController) RemoveMember(c *fiber.Ctx) error {
+	masjidID := c.Locals("masjid_id").(uuid.UUID)
+	memberUserID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidID)
+	}
+
+	var member models.MasjidMemberModel
+	if err := mc.DB.Where("masjid_id = ? AND user_id = ?", masjidID, memberUserID).First(&member).Error; err != nil {
+		return apperror.NotFound(apperror.CodeMemberNotFound)
+	}
+
+	if member.Role == constants.RoleOwner {
+		if err := mc.ensureAnotherOwner(masjidID, memberUserID); err != nil {
+			return err
+		}
+	}
+
+	if err := mc.DB.Delete(&member).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("keluarkan anggota: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Member removed successfully",
+	})
+}
+
+// GET undangan masjid milik user yang sedang login
+func (mc *MasjidMemberController) GetMyInvitations(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var invitations []models.MasjidMemberModel
+	if err := mc.DB.Preload("Masjid").
+		Where("user_id = ? AND status = ?", userID, models.MemberStatusInvited).
+		Find(&invitations).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("ambil undangan: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Invitations fetched successfully",
+		"total":   len(invitations),
+		"data":    invitations,
+	})
+}
+
+// POST terima undangan masjid
+func (mc *MasjidMemberController) AcceptInvitation(c *fiber.Ctx) error {
+	member, err := mc.findMyInvitation(c)
+	if err != nil {
+		return apperror.NotFound(apperror.CodeInvitationNotFound)
+	}
+
+	now := time.Now()
+	member.Status = models.MemberStatusActive
+	member.AcceptedAt = &now
+	if err := mc.DB.Save(member).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("terima undangan: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Invitation accepted",
+		"data":    member,
+	})
+}
+
+// POST tolak undangan masjid
+func (mc *MasjidMemberController) DeclineInvitation(c *fiber.Ctx) error {
+	member, err := mc.findMyInvitation(c)
+	if err != nil {
+		return apperror.NotFound(apperror.CodeInvitationNotFound)
+	}
+
+	if err := mc.DB.Delete(member).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("tolak undangan: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Invitation declined",
+	})
+}
+
+func (mc *MasjidMemberController) findMyInvitation(c *fiber.Ctx) (*models.MasjidMemberModel, error) {
+	userID, _ := authMw.UserID(c)
+
+	var member models.MasjidMemberModel
+	err := mc.DB.Where("masjid_id = ? AND user_id = ? AND status = ?", c.Locals("masjid_id"), userID, models.MemberStatusInvited).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ensureAnotherOwner memastikan masjid tidak kehilangan owner terakhirnya
+func (mc *MasjidMemberController) ensureAnotherOwner(masjidID, exceptUserID uuid.UUID) error {
+	var count int64
+	if err := mc.DB.Model(&models.MasjidMemberModel{}).
+		Where("masjid_id = ? AND user_id <> ? AND role = ? AND status = ?", masjidID, exceptUserID, constants.RoleOwner, models.MemberStatusActive).
+		Count(&count).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("hitung owner masjid: %w", err))
+	}
+	if count == 0 {
+		return apperror.BadRequest(apperror.CodeLastOwner)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/dataexport"
+)
+
+func init() {
+	dataexport.Register("masjids", exportMemberships)
+}
+
+// exportMemberships: keanggotaan / jabatan user di setiap masjid beserta nama masjidnya
+func exportMemberships(ctx context.Context, db *gorm.DB, userID uuid.UUID, archive *dataexport.Archive) error {
+	var members []MasjidMemberModel
+	if err := db.Preload("Masjid").Where("user_id = ?", userID).Order("created_at").Find(&members).Error; err != nil {
+		return err
+	}
+
+	out := make([]map[string]interface{}, 0, len(members))
+	for _, m := range members {
+		item := map[string]interface{}{
+			"masjid_id":   m.MasjidID,
+			"role":        m.Role,
+			"status":      m.Status,
+			"accepted_at": m.AcceptedAt,
+			"created_at":  m.CreatedAt,
+		}
+		if m.Masjid != nil {
+			item["masjid_name"] = m.Masjid.Name
+		}
+		out = append(out, item)
+	}
+	return archive.JSON("memberships.json", out)
+}
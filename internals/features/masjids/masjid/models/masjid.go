@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MasjidModel merepresentasikan tabel masjids di database
+type MasjidModel struct {
+	ID              uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name            string         `gorm:"size:100;not null" json:"name" validate:"required,min=3,max=100"`
+	Slug            string         `gorm:"size:120;not null;uniqueIndex" json:"slug"`
+	Address         string         `gorm:"size:255" json:"address" validate:"max=255"`
+	City            string         `gorm:"size:100" json:"city" validate:"max=100"`
+	Latitude        *float64       `gorm:"type:decimal(9,6)" json:"latitude" validate:"omitempty,latitude"`
+	Longitude       *float64       `gorm:"type:decimal(9,6)" json:"longitude" validate:"omitempty,longitude"`
+	Timezone        string         `gorm:"size:50;not null;default:'Asia/Jakarta'" json:"timezone"`
+	Phone           string         `gorm:"size:20" json:"phone" validate:"max=20"`
+	Email           string         `gorm:"size:255" json:"email" validate:"omitempty,email"`
+	Website         string         `gorm:"size:255" json:"website" validate:"omitempty,url"`
+	CreatedBy       *uuid.UUID     `gorm:"type:uuid" json:"created_by"`                     // nil (NULL) jika pembuatnya sudah dihapus permanen
+	RequireStaffMFA bool           `gorm:"not null;default:false" json:"require_staff_mfa"` // pengurus wajib login dengan 2FA
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (MasjidModel) TableName() string {
+	return "masjids"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status keanggotaan masjid
+const (
+	MemberStatusInvited = "invited"
+	MemberStatusActive  = "active"
+)
+
+// MasjidMemberModel adalah tabel penghubung user <-> masjid beserta role user di masjid tersebut
+type MasjidMemberModel struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	MasjidID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_masjid_members_masjid_user" json:"masjid_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_masjid_members_masjid_user;index" json:"user_id"`
+	Role       string     `gorm:"type:varchar(20);not null;default:'user'" json:"role"`
+	Status     string     `gorm:"type:varchar(20);not null;default:'invited'" json:"status"`
+	InvitedBy  *uuid.UUID `gorm:"type:uuid" json:"invited_by,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Masjid *MasjidModel `gorm:"foreignKey:MasjidID" json:"masjid,omitempty"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (MasjidMemberModel) TableName() string {
+	return "masjid_members"
+}
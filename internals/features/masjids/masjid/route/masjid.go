@@ -0,0 +1,42 @@
+package route
+
+import (
+	"masjidku/internals/configs"
+	"masjidku/internals/constants"
+	masjidController "masjidku/internals/features/masjids/masjid/controller"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	authMw "masjidku/internals/middlewares/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func MasjidRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy) {
+	masjidCtrl := masjidController.NewMasjidController(db)
+	memberCtrl := masjidController.NewMasjidMemberController(db, policy)
+	inMasjid := authMw.MasjidContext(db)
+	verified := authMw.RequireVerifiedEmail(db)
+
+	// ✅ Group dengan Middleware Auth
+	masjids := app.Group("/api/masjids", authMw.AuthMiddleware(db, tokens, policy))
+
+	// 🔹 Masjid
+	masjids.Get("/", masjidCtrl.GetMasjids)
+	masjids.Post("/", masjidCtrl.CreateMasjid)
+	masjids.Get("/invitations", memberCtrl.GetMyInvitations)
+	masjids.Get("/:slug", inMasjid, masjidCtrl.GetMasjid)
+	masjids.Put("/:slug", inMasjid, authMw.RequirePermission(constants.PermMasjidsUpdate), verified, masjidCtrl.UpdateMasjid)
+	masjids.Put("/:slug/security", inMasjid, authMw.RequirePermission(constants.PermMasjidsSecurity), verified, masjidCtrl.UpdateSecurity)
+	masjids.Delete("/:slug", inMasjid, authMw.RequirePermission(constants.PermMasjidsDelete), verified, masjidCtrl.DeleteMasjid)
+
+	// 🔹 Anggota masjid
+	masjids.Get("/:slug/members", inMasjid, authMw.RequirePermission(constants.PermMembersRead), memberCtrl.GetMembers)
+	masjids.Post("/:slug/members/invite", inMasjid, authMw.RequirePermission(constants.PermMembersManage), verified, memberCtrl.InviteMember)
+	masjids.Put("/:slug/members/:userId", inMasjid, authMw.RequirePermission(constants.PermMembersManageRoles), verified, memberCtrl.UpdateMemberRole)
+	masjids.Delete("/:slug/members/:userId", inMasjid, authMw.RequirePermission(constants.PermMembersManageRoles), verified, memberCtrl.RemoveMember)
+
+	// 🔹 Undangan (untuk user yang diundang)
+	masjids.Post("/:slug/invitations/accept", inMasjid, memberCtrl.AcceptInvitation)
+	masjids.Post("/:slug/invitations/decline", inMasjid, memberCtrl.DeclineInvitation)
+}
@@ -0,0 +1,265 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Location adalah posisi pengamat (masjid)
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	Elevation float64 // meter di atas permukaan laut, boleh 0
+	TimeZone  *time.Location
+}
+
+// Config adalah pengaturan perhitungan
+type Config struct {
+	Method      Method
+	Asr         AsrMethod
+	Adjustments Adjustments // penyesuaian tambahan per masjid (ihtiyat), dalam menit
+}
+
+// Times adalah hasil perhitungan waktu sholat untuk satu hari
+type Times struct {
+	Date    time.Time `json:"-"`
+	Imsak   time.Time `json:"imsak"`
+	Fajr    time.Time `json:"fajr"`
+	Sunrise time.Time `json:"sunrise"`
+	Dhuhr   time.Time `json:"dhuhr"`
+	Asr     time.Time `json:"asr"`
+	Maghrib time.Time `json:"maghrib"`
+	Isha    time.Time `json:"isha"`
+}
+
+// Entry adalah pasangan nama dan waktu, urut sesuai jadwal harian
+type Entry struct {
+	Name string
+	Time time.Time
+}
+
+// Entries mengembalikan seluruh waktu secara berurutan
+func (t Times) Entries() []Entry {
+	return []Entry{
+		{"imsak", t.Imsak},
+		{"fajr", t.Fajr},
+		{"sunrise", t.Sunrise},
+		{"dhuhr", t.Dhuhr},
+		{"asr", t.Asr},
+		{"maghrib", t.Maghrib},
+		{"isha", t.Isha},
+	}
+}
+
+var (
+	ErrInvalidMethod   = errors.New("metode perhitungan tidak dikenal")
+	ErrInvalidAsr      = errors.New("madzhab ashar tidak dikenal")
+	ErrInvalidLocation = errors.New("koordinat tidak valid")
+)
+
+// Calculate menghitung waktu sholat untuk tanggal (y, m, d) di lokasi tertentu.
+// Algoritma mengikuti rumus astronomi standar (lihat PrayTimes.org) sehingga
+// sepenuhnya offline dan deterministik.
+func Calculate(date time.Time, loc Location, cfg Config) (*Times, error) {
+	params, ok := Methods[cfg.Method]
+	if !ok {
+		return nil, ErrInvalidMethod
+	}
+	if cfg.Asr == "" {
+		cfg.Asr = AsrShafii
+	}
+	if !cfg.Asr.IsValid() {
+		return nil, ErrInvalidAsr
+	}
+	if loc.Latitude < -90 || loc.Latitude > 90 || loc.Longitude < -180 || loc.Longitude > 180 {
+		return nil, ErrInvalidLocation
+	}
+	tz := loc.TimeZone
+	if tz == nil {
+		tz = time.UTC
+	}
+
+	year, month, day := date.Date()
+	noonLocal := time.Date(year, month, day, 12, 0, 0, 0, tz)
+	_, offsetSeconds := noonLocal.Zone()
+	tzHours := float64(offsetSeconds) / 3600
+
+	s := solver{
+		lat: loc.Latitude,
+		lng: loc.Longitude,
+		jd:  julianDate(year, int(month), day) - loc.Longitude/(15*24),
+	}
+
+	// Nilai awal (jam) lalu diperhalus dengan satu iterasi
+	fajr, sunrise, dhuhr, asr, sunset, isha := 5.0, 6.0, 12.0, 13.0, 18.0, 18.0
+	riseSetAngle := 0.833 + 0.0347*math.Sqrt(math.Max(loc.Elevation, 0))
+
+	for i := 0; i < 2; i++ {
+		fajr = s.sunAngleTime(params.FajrAngle, fajr/24, true)
+		sunrise = s.sunAngleTime(riseSetAngle, sunrise/24, true)
+		dhuhr = s.midDay(dhuhr / 24)
+		asr = s.asrTime(cfg.Asr.shadowFactor(), asr/24)
+		sunset = s.sunAngleTime(riseSetAngle, sunset/24, false)
+		if params.IshaMinute == 0 {
+			isha = s.sunAngleTime(params.IshaAngle, isha/24, false)
+		}
+	}
+
+	// Koreksi zona waktu dan bujur
+	shift := tzHours - loc.Longitude/15
+	fajr += shift
+	sunrise += shift
+	dhuhr += shift
+	asr += shift
+	sunset += shift
+	if params.IshaMinute > 0 {
+		isha = sunset + float64(params.IshaMinute)/60
+	} else {
+		isha += shift
+	}
+
+	// Penyesuaian untuk lintang tinggi (metode "angle based")
+	night := timeDiff(sunset, sunrise)
+	fajr = adjustHighLatitude(fajr, sunrise, params.FajrAngle, night, true)
+	if params.IshaMinute == 0 {
+		isha = adjustHighLatitude(isha, sunset, params.IshaAngle, night, false)
+	}
+
+	adj := params.Adjustments.Add(cfg.Adjustments)
+	base := time.Date(year, month, day, 0, 0, 0, 0, tz)
+
+	t := &Times{
+		Date:    base,
+		Fajr:    toTime(base, fajr, adj.Fajr),
+		Sunrise: toTime(base, sunrise, adj.Sunrise),
+		Dhuhr:   toTime(base, dhuhr, adj.Dhuhr),
+		Asr:     toTime(base, asr, adj.Asr),
+		Maghrib: toTime(base, sunset, adj.Maghrib),
+		Isha:    toTime(base, isha, adj.Isha),
+	}
+	t.Imsak = t.Fajr.Add(time.Duration(adj.Imsak-params.ImsakMinute) * time.Minute)
+
+	return t, nil
+}
+
+// CalculateMonth menghitung jadwal untuk seluruh hari dalam satu bulan
+func CalculateMonth(year int, month time.Month, loc Location, cfg Config) ([]Times, error) {
+	tz := loc.TimeZone
+	if tz == nil {
+		tz = time.UTC
+	}
+
+	var result []Times
+	for d := time.Date(year, month, 1, 0, 0, 0, 0, tz); d.Month() == month; d = d.AddDate(0, 0, 1) {
+		t, err := Calculate(d, loc, cfg)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *t)
+	}
+	return result, nil
+}
+
+// ---------------------------------------------------------------------------
+// Astronomi
+// ---------------------------------------------------------------------------
+
+type solver struct {
+	lat, lng float64
+	jd       float64
+}
+
+// sunPosition mengembalikan deklinasi matahari dan equation of time
+func sunPosition(jd float64) (declination, equation float64) {
+	d := jd - 2451545.0
+	g := fixAngle(357.529 + 0.98560028*d)
+	q := fixAngle(280.459 + 0.98564736*d)
+	l := fixAngle(q + 1.915*dsin(g) + 0.020*dsin(2*g))
+	e := 23.439 - 0.00000036*d
+
+	ra := darctan2(dcos(e)*dsin(l), dcos(l)) / 15
+	equation = q/15 - fixHour(ra)
+	declination = darcsin(dsin(e) * dsin(l))
+	return declination, equation
+}
+
+func (s solver) midDay(dayPortion float64) float64 {
+	_, eqt := sunPosition(s.jd + dayPortion)
+	return fixHour(12 - eqt)
+}
+
+// sunAngleTime menghitung waktu saat matahari berada `angle` derajat di bawah ufuk
+func (s solver) sunAngleTime(angle, dayPortion float64, beforeNoon bool) float64 {
+	decl, _ := sunPosition(s.jd + dayPortion)
+	noon := s.midDay(dayPortion)
+	t := darccos((-dsin(angle)-dsin(decl)*dsin(s.lat))/(dcos(decl)*dcos(s.lat))) / 15
+	if beforeNoon {
+		return noon - t
+	}
+	return noon + t
+}
+
+// asrTime menghitung waktu ashar berdasarkan panjang bayangan
+func (s solver) asrTime(factor, dayPortion float64) float64 {
+	decl, _ := sunPosition(s.jd + dayPortion)
+	angle := -darccot(factor + dtan(math.Abs(s.lat-decl)))
+	return s.sunAngleTime(angle, dayPortion, false)
+}
+
+func adjustHighLatitude(t, base, angle, night float64, beforeBase bool) float64 {
+	portion := angle / 60 * night
+	var diff float64
+	if beforeBase {
+		diff = timeDiff(t, base)
+	} else {
+		diff = timeDiff(base, t)
+	}
+	if math.IsNaN(t) || diff > portion {
+		if beforeBase {
+			return base - portion
+		}
+		return base + portion
+	}
+	return t
+}
+
+func julianDate(year, month, day int) float64 {
+	if month <= 2 {
+		year--
+		month += 12
+	}
+	a := math.Floor(float64(year) / 100)
+	b := 2 - a + math.Floor(a/4)
+	return math.Floor(365.25*float64(year+4716)) + math.Floor(30.6001*float64(month+1)) + float64(day) + b - 1524.5
+}
+
+// toTime mengubah jam desimal (jam dinding lokal) menjadi time.Time, dibulatkan ke menit terdekat.
+// Dibangun lewat time.Date, bukan base.Add: pada hari pergantian DST durasi sejak tengah malam
+// tidak sama dengan jam dinding. Menit di luar 0..1439 dinormalkan time.Date ke hari sebelum / sesudahnya.
+func toTime(base time.Time, hours float64, adjustMinutes int) time.Time {
+	minutes := int(math.Round(hours*60)) + adjustMinutes
+	year, month, day := base.Date()
+	return time.Date(year, month, day, 0, minutes, 0, 0, base.Location())
+}
+
+func timeDiff(t1, t2 float64) float64 { return fixHour(t2 - t1) }
+
+func dsin(d float64) float64        { return math.Sin(d * math.Pi / 180) }
+func dcos(d float64) float64        { return math.Cos(d * math.Pi / 180) }
+func dtan(d float64) float64        { return math.Tan(d * math.Pi / 180) }
+func darcsin(x float64) float64     { return math.Asin(x) * 180 / math.Pi }
+func darccos(x float64) float64     { return math.Acos(x) * 180 / math.Pi }
+func darctan2(y, x float64) float64 { return math.Atan2(y, x) * 180 / math.Pi }
+func darccot(x float64) float64     { return math.Atan(1/x) * 180 / math.Pi }
+
+func fixAngle(a float64) float64 { return fix(a, 360) }
+func fixHour(a float64) float64  { return fix(a, 24) }
+
+func fix(a, b float64) float64 {
+	a = a - b*math.Floor(a/b)
+	if a < 0 {
+		return a + b
+	}
+	return a
+}
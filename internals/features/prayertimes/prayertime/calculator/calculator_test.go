@@ -0,0 +1,135 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+)
+
+// tolerance: selisih yang diterima terhadap jadwal yang dipublikasikan (pembulatan menit,
+// koordinat titik acuan dan ihtiyat tiap penerbit sedikit berbeda)
+const tolerance = 2 * time.Minute
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("zona waktu %s tidak tersedia: %v", name, err)
+	}
+	return loc
+}
+
+// TestCalculatePublishedTimetables membandingkan hasil perhitungan dengan jadwal yang dipublikasikan
+// (jam lokal, dibulatkan ke menit) untuk setiap metode yang didukung
+func TestCalculatePublishedTimetables(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   Method
+		lat, lng float64
+		zone     string
+		date     string
+		want     map[string]string // nama waktu -> HH:MM
+	}{
+		{
+			name: "Kemenag Jakarta", method: MethodKemenag,
+			lat: -6.1754, lng: 106.8272, zone: "Asia/Jakarta", date: "2024-01-01",
+			want: map[string]string{"imsak": "04:07", "fajr": "04:17", "sunrise": "05:39", "dhuhr": "11:57", "asr": "15:24", "maghrib": "18:12", "isha": "19:27"},
+		},
+		{
+			name: "MWL London (hari pergantian DST)", method: MethodMWL,
+			lat: 51.5074, lng: -0.1278, zone: "Europe/London", date: "2024-03-31",
+			want: map[string]string{"fajr": "04:40", "sunrise": "06:36", "dhuhr": "13:04", "asr": "16:37", "maghrib": "19:33", "isha": "21:23"},
+		},
+		{
+			name: "ISNA New York", method: MethodISNA,
+			lat: 40.7128, lng: -74.0060, zone: "America/New_York", date: "2024-06-21",
+			want: map[string]string{"fajr": "03:46", "sunrise": "05:25", "dhuhr": "12:57", "asr": "16:58", "maghrib": "20:31", "isha": "22:10"},
+		},
+		{
+			name: "Umm al-Qura Makkah", method: MethodUmmAlQura,
+			lat: 21.4225, lng: 39.8262, zone: "Asia/Riyadh", date: "2024-01-01",
+			want: map[string]string{"fajr": "05:38", "sunrise": "06:58", "dhuhr": "12:23", "asr": "15:28", "maghrib": "17:49", "isha": "19:19"},
+		},
+		{
+			name: "Egyptian Cairo", method: MethodEgyptian,
+			lat: 30.0444, lng: 31.2357, zone: "Africa/Cairo", date: "2024-01-01",
+			want: map[string]string{"fajr": "05:18", "sunrise": "06:50", "dhuhr": "11:58", "asr": "14:47", "maghrib": "17:06", "isha": "18:29"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tz := mustLoad(t, tc.zone)
+			date, err := time.ParseInLocation("2006-01-02", tc.date, tz)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Calculate(date, Location{Latitude: tc.lat, Longitude: tc.lng, TimeZone: tz}, Config{Method: tc.method})
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+
+			for _, e := range got.Entries() {
+				wantClock, ok := tc.want[e.Name]
+				if !ok {
+					continue
+				}
+				want, err := time.ParseInLocation("2006-01-02 15:04", tc.date+" "+wantClock, tz)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := e.Time.Sub(want); diff > tolerance || diff < -tolerance {
+					t.Errorf("%s = %s, jadwal %s (selisih %v)", e.Name, e.Time.Format("15:04 MST"), wantClock, diff)
+				}
+			}
+		})
+	}
+}
+
+// TestCalculateAcrossDST: posisi matahari bergeser hanya beberapa menit per hari, jadi di sekitar
+// pergantian DST selisih waktu absolut (UTC) antar hari tetap sekitar 24 jam; yang bergeser
+// satu jam hanya jam dinding
+func TestCalculateAcrossDST(t *testing.T) {
+	cases := []struct {
+		zone     string
+		lat, lng float64
+		day      string // hari pergantian DST
+	}{
+		{"Europe/London", 51.5074, -0.1278, "2024-03-31"},
+		{"Europe/London", 51.5074, -0.1278, "2024-10-27"},
+		{"America/New_York", 40.7128, -74.0060, "2024-03-10"},
+		{"America/New_York", 40.7128, -74.0060, "2024-11-03"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.zone+" "+tc.day, func(t *testing.T) {
+			tz := mustLoad(t, tc.zone)
+			day, err := time.ParseInLocation("2006-01-02", tc.day, tz)
+			if err != nil {
+				t.Fatal(err)
+			}
+			loc := Location{Latitude: tc.lat, Longitude: tc.lng, TimeZone: tz}
+
+			var prev *Times
+			for _, d := range []time.Time{day.AddDate(0, 0, -1), day, day.AddDate(0, 0, 1)} {
+				cur, err := Calculate(d, loc, Config{Method: MethodMWL})
+				if err != nil {
+					t.Fatalf("Calculate: %v", err)
+				}
+				if y, m, dd := cur.Dhuhr.Date(); y != d.Year() || m != d.Month() || dd != d.Day() {
+					t.Errorf("dhuhr %s jatuh di luar tanggal %s", cur.Dhuhr, d.Format("2006-01-02"))
+				}
+				if prev != nil {
+					prevEntries, curEntries := prev.Entries(), cur.Entries()
+					for i := range curEntries {
+						a, b := prevEntries[i].Time, curEntries[i].Time
+						shift := int(b.Sub(a.Add(24 * time.Hour)).Minutes())
+						if shift > 10 || shift < -10 {
+							t.Errorf("%s bergeser %d menit dari %s ke %s", curEntries[i].Name, shift,
+								a.Format("2006-01-02 15:04 MST"), b.Format("2006-01-02 15:04 MST"))
+						}
+					}
+				}
+				prev = cur
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package calculator
+
+// Method adalah metode perhitungan waktu sholat
+type Method string
+
+const (
+	MethodKemenag   Method = "kemenag"   // Kementerian Agama RI
+	MethodMWL       Method = "mwl"       // Muslim World League
+	MethodISNA      Method = "isna"      // Islamic Society of North America
+	MethodUmmAlQura Method = "ummalqura" // Umm al-Qura, Makkah
+	MethodEgyptian  Method = "egyptian"  // Egyptian General Authority of Survey
+)
+
+// AsrMethod adalah pendapat madzhab untuk waktu Ashar
+type AsrMethod string
+
+const (
+	AsrShafii AsrMethod = "shafii" // bayangan = 1x tinggi benda (jumhur)
+	AsrHanafi AsrMethod = "hanafi" // bayangan = 2x tinggi benda
+)
+
+// MethodParams berisi parameter sudut / menit dari sebuah metode
+type MethodParams struct {
+	Name        string
+	FajrAngle   float64
+	IshaAngle   float64 // dipakai jika IshaMinute == 0
+	IshaMinute  int     // menit setelah maghrib (Umm al-Qura)
+	ImsakMinute int     // menit sebelum subuh
+	// Adjustments bawaan metode (ihtiyat), ditambahkan sebelum penyesuaian per masjid
+	Adjustments Adjustments
+}
+
+// Methods adalah daftar metode yang didukung
+var Methods = map[Method]MethodParams{
+	MethodKemenag: {
+		Name:        "Kementerian Agama RI",
+		FajrAngle:   20,
+		IshaAngle:   18,
+		ImsakMinute: 10,
+		// Kemenag menambahkan ihtiyat 2 menit (dan mengurangi 2 menit untuk terbit)
+		Adjustments: Adjustments{Fajr: 2, Sunrise: -2, Dhuhr: 2, Asr: 2, Maghrib: 2, Isha: 2},
+	},
+	MethodMWL: {
+		Name:        "Muslim World League",
+		FajrAngle:   18,
+		IshaAngle:   17,
+		ImsakMinute: 10,
+	},
+	MethodISNA: {
+		Name:        "Islamic Society of North America",
+		FajrAngle:   15,
+		IshaAngle:   15,
+		ImsakMinute: 10,
+	},
+	MethodUmmAlQura: {
+		Name:        "Umm al-Qura University, Makkah",
+		FajrAngle:   18.5,
+		IshaMinute:  90,
+		ImsakMinute: 10,
+	},
+	MethodEgyptian: {
+		Name:        "Egyptian General Authority of Survey",
+		FajrAngle:   19.5,
+		IshaAngle:   17.5,
+		ImsakMinute: 10,
+	},
+}
+
+// IsValid mengecek apakah metode dikenal
+func (m Method) IsValid() bool {
+	_, ok := Methods[m]
+	return ok
+}
+
+// IsValid mengecek apakah madzhab ashar dikenal
+func (a AsrMethod) IsValid() bool {
+	return a == AsrShafii || a == AsrHanafi
+}
+
+func (a AsrMethod) shadowFactor() float64 {
+	if a == AsrHanafi {
+		return 2
+	}
+	return 1
+}
+
+// Adjustments adalah penyesuaian dalam menit untuk tiap waktu
+type Adjustments struct {
+	Imsak   int `json:"imsak"`
+	Fajr    int `json:"fajr"`
+	Sunrise int `json:"sunrise"`
+	Dhuhr   int `json:"dhuhr"`
+	Asr     int `json:"asr"`
+	Maghrib int `json:"maghrib"`
+	Isha    int `json:"isha"`
+}
+
+// Add menjumlahkan dua penyesuaian
+func (a Adjustments) Add(b Adjustments) Adjustments {
+	return Adjustments{
+		Imsak:   a.Imsak + b.Imsak,
+		Fajr:    a.Fajr + b.Fajr,
+		Sunrise: a.Sunrise + b.Sunrise,
+		Dhuhr:   a.Dhuhr + b.Dhuhr,
+		Asr:     a.Asr + b.Asr,
+		Maghrib: a.Maghrib + b.Maghrib,
+		Isha:    a.Isha + b.Isha,
+	}
+}
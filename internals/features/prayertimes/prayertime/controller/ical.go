@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"masjidku/internals/features/prayertimes/prayertime/calculator"
+)
+
+var prayerLabels = map[string]string{
+	"imsak":   "Imsak",
+	"fajr":    "Subuh",
+	"sunrise": "Terbit",
+	"dhuhr":   "Dzuhur",
+	"asr":     "Ashar",
+	"maghrib": "Maghrib",
+	"isha":    "Isya",
+}
+
+// buildICalendar membuat feed iCalendar (RFC 5545) dari daftar jadwal sholat
+func buildICalendar(masjidID, masjidName string, days []calculator.Times) string {
+	var b strings.Builder
+	now := time.Now().UTC().Format("20060102T150405Z")
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//Masjidku//Jadwal Sholat//ID")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText("Jadwal Sholat "+masjidName))
+
+	for _, day := range days {
+		for _, e := range day.Entries() {
+			start := e.Time.UTC()
+			writeLine(&b, "BEGIN:VEVENT")
+			writeLine(&b, fmt.Sprintf("UID:%s-%s-%s@masjidku", start.Format("20060102"), e.Name, masjidID))
+			writeLine(&b, "DTSTAMP:"+now)
+			writeLine(&b, "DTSTART:"+start.Format("20060102T150405Z"))
+			writeLine(&b, "DURATION:PT10M")
+			writeLine(&b, "SUMMARY:"+escapeText(prayerLabels[e.Name]+" - "+masjidName))
+			writeLine(&b, "TRANSP:TRANSPARENT")
+			writeLine(&b, "END:VEVENT")
+		}
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// writeLine menulis satu baris dan melipatnya setiap 75 oktet sesuai RFC 5545
+func writeLine(b *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
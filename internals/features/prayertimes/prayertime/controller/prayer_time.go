@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	modelMasjid "masjidku/internals/features/masjids/masjid/models"
+	"masjidku/internals/features/prayertimes/prayertime/calculator"
+	"masjidku/internals/features/prayertimes/prayertime/models"
+)
+
+type PrayerTimeController struct {
+	DB *gorm.DB
+}
+
+func NewPrayerTimeController(db *gorm.DB) *PrayerTimeController {
+	return &PrayerTimeController{DB: db}
+}
+
+type UpdatePrayerSettingInput struct {
+	Method      string                 `json:"method"`
+	AsrMethod   string                 `json:"asr_method"`
+	Elevation   float64                `json:"elevation"`
+	Adjustments calculator.Adjustments `json:"adjustments"`
+}
+
+// GET /api/masjids/:id/prayer-times?date=YYYY-MM-DD[&format=ics]
+func (pc *PrayerTimeController) GetDailyPrayerTimes(c *fiber.Ctx) error {
+	masjid, setting, loc, err := pc.loadMasjid(c)
+	if err != nil {
+		return err
+	}
+
+	date := time.Now().In(loc.TimeZone)
+	if q := c.Query("date"); q != "" {
+		date, err = time.ParseInLocation("2006-01-02", q, loc.TimeZone)
+		if err != nil {
+			return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("Format date harus YYYY-MM-DD")
+		}
+	}
+
+	times, err := calculator.Calculate(date, loc, setting.CalculatorConfig())
+	if err != nil {
+		return prayerTimesUnavailable(err)
+	}
+
+	if wantsICalendar(c) {
+		return sendICalendar(c, masjid, []calculator.Times{*times})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Prayer times fetched successfully",
+		"data": fiber.Map{
+			"masjid_id":  masjid.ID,
+			"timezone":   loc.TimeZone.String(),
+			"method":     setting.Method,
+			"asr_method": setting.AsrMethod,
+			"schedule":   formatDay(*times),
+		},
+	})
+}
+
+// GET /api/masjids/:id/prayer-times/monthly?year=YYYY&month=M[&format=ics]
+func (pc *PrayerTimeController) GetMonthlyPrayerTimes(c *fiber.Ctx) error {
+	masjid, setting, loc, err := pc.loadMasjid(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().In(loc.TimeZone)
+	year, month := now.Year(), now.Month()
+	if q := c.Query("year"); q != "" {
+		if year, err = strconv.Atoi(q); err != nil || year < 1900 || year > 2200 {
+			return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("year harus 1900-2200")
+		}
+	}
+	if q := c.Query("month"); q != "" {
+		m, err := strconv.Atoi(q)
+		if err != nil || m < 1 || m > 12 {
+			return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("month harus 1-12")
+		}
+		month = time.Month(m)
+	}
+
+	days, err := calculator.CalculateMonth(year, month, loc, setting.CalculatorConfig())
+	if err != nil {
+		return prayerTimesUnavailable(err)
+	}
+
+	if wantsICalendar(c) {
+		return sendICalendar(c, masjid, days)
+	}
+
+	schedule := make([]fiber.Map, 0, len(days))
+	for _, d := range days {
+		schedule = append(schedule, formatDay(d))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Prayer times fetched successfully",
+		"data": fiber.Map{
+			"masjid_id":  masjid.ID,
+			"timezone":   loc.TimeZone.String(),
+			"method":     setting.Method,
+			"asr_method": setting.AsrMethod,
+			"year":       year,
+			"month":      int(month),
+			"schedule":   schedule,
+		},
+	})
+}
+
+// GET /api/masjids/:id/prayer-settings
+func (pc *PrayerTimeController) GetSetting(c *fiber.Ctx) error {
+	setting, err := pc.findSetting(c)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("ambil pengaturan jadwal sholat: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Prayer setting fetched successfully",
+		"data":    setting,
+	})
+}
+
+// PUT /api/masjids/:id/prayer-settings
+func (pc *PrayerTimeController) UpdateSetting(c *fiber.Ctx) error {
+	var input UpdatePrayerSettingInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if !calculator.Method(input.Method).IsValid() {
+		return apperror.BadRequest(apperror.CodeValidation).
+			WithField("method", "oneof", "method harus salah satu dari kemenag, mwl, isna, ummalqura, egyptian")
+	}
+	if input.AsrMethod == "" {
+		input.AsrMethod = string(calculator.AsrShafii)
+	}
+	if !calculator.AsrMethod(input.AsrMethod).IsValid() {
+		return apperror.BadRequest(apperror.CodeValidation).
+			WithField("asr_method", "oneof", "asr_method harus shafii atau hanafi")
+	}
+
+	setting, err := pc.findSetting(c)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("ambil pengaturan jadwal sholat: %w", err))
+	}
+
+	setting.Method = input.Method
+	setting.AsrMethod = input.AsrMethod
+	setting.Elevation = input.Elevation
+	setting.AdjImsak = input.Adjustments.Imsak
+	setting.AdjFajr = input.Adjustments.Fajr
+	setting.AdjSunrise = input.Adjustments.Sunrise
+	setting.AdjDhuhr = input.Adjustments.Dhuhr
+	setting.AdjAsr = input.Adjustments.Asr
+	setting.AdjMaghrib = input.Adjustments.Maghrib
+	setting.AdjIsha = input.Adjustments.Isha
+
+	if err := pc.DB.Save(setting).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("simpan pengaturan jadwal sholat: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Prayer setting updated successfully",
+		"data":    setting,
+	})
+}
+
+// loadMasjid mengambil masjid (sudah di-resolve auth.MasjidContext), pengaturan dan lokasinya.
+// Error yang dikembalikan selalu berupa *apperror.Error sehingga handler cukup meneruskannya.
+func (pc *PrayerTimeController) loadMasjid(c *fiber.Ctx) (*modelMasjid.MasjidModel, *models.MasjidPrayerSettingModel, calculator.Location, error) {
+	var loc calculator.Location
+
+	var masjid modelMasjid.MasjidModel
+	if err := pc.DB.First(&masjid, "id = ?", c.Locals("masjid_id")).Error; err != nil {
+		return nil, nil, loc, apperror.NotFound(apperror.CodeMasjidNotFound)
+	}
+	if masjid.Latitude == nil || masjid.Longitude == nil {
+		return nil, nil, loc, apperror.New(fiber.StatusUnprocessableEntity, apperror.CodePrayerTimesUnavailable).
+			WithDetail("Koordinat masjid belum diatur")
+	}
+
+	tz, err := time.LoadLocation(masjid.Timezone)
+	if err != nil {
+		log.Printf("[ERROR] Invalid timezone %q for masjid %v: %v", masjid.Timezone, masjid.ID, err)
+		return nil, nil, loc, apperror.New(fiber.StatusUnprocessableEntity, apperror.CodePrayerTimesUnavailable).
+			WithDetail("Zona waktu masjid tidak valid")
+	}
+
+	setting, err := pc.findSetting(c)
+	if err != nil {
+		return nil, nil, loc, apperror.Internal(fmt.Errorf("ambil pengaturan jadwal sholat: %w", err))
+	}
+
+	loc = calculator.Location{
+		Latitude:  *masjid.Latitude,
+		Longitude: *masjid.Longitude,
+		Elevation: setting.Elevation,
+		TimeZone:  tz,
+	}
+	return &masjid, setting, loc, nil
+}
+
+func (pc *PrayerTimeController) findSetting(c *fiber.Ctx) (*models.MasjidPrayerSettingModel, error) {
+	var setting models.MasjidPrayerSettingModel
+	err := pc.DB.First(&setting, "masjid_id = ?", c.Locals("masjid_id")).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		setting = models.DefaultPrayerSetting(c.Locals("masjid_id").(uuid.UUID))
+		return &setting, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// prayerTimesUnavailable: kalkulator menolak pengaturan / koordinat masjid (calculator.ErrInvalid*)
+func prayerTimesUnavailable(err error) error {
+	return apperror.New(fiber.StatusUnprocessableEntity, apperror.CodePrayerTimesUnavailable).
+		WithDetail("%s", err.Error()).WithCause(err)
+}
+
+func formatDay(t calculator.Times) fiber.Map {
+	day := fiber.Map{"date": t.Date.Format("2006-01-02")}
+	for _, e := range t.Entries() {
+		day[e.Name] = e.Time.Format("15:04")
+	}
+	return day
+}
+
+func wantsICalendar(c *fiber.Ctx) bool {
+	return c.Query("format") == "ics" || c.Accepts(fiber.MIMEApplicationJSON, "text/calendar") == "text/calendar"
+}
+
+func sendICalendar(c *fiber.Ctx, masjid *modelMasjid.MasjidModel, days []calculator.Times) error {
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="jadwal-sholat-`+masjid.Slug+`.ics"`)
+	return c.SendString(buildICalendar(masjid.ID.String(), masjid.Name, days))
+}
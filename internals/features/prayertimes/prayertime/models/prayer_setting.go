@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"masjidku/internals/features/prayertimes/prayertime/calculator"
+)
+
+// MasjidPrayerSettingModel menyimpan pengaturan perhitungan jadwal sholat per masjid
+type MasjidPrayerSettingModel struct {
+	MasjidID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"masjid_id"`
+	Method     string    `gorm:"type:varchar(20);not null;default:'kemenag'" json:"method"`
+	AsrMethod  string    `gorm:"type:varchar(10);not null;default:'shafii'" json:"asr_method"`
+	Elevation  float64   `gorm:"not null;default:0" json:"elevation"`
+	AdjImsak   int       `gorm:"not null;default:0" json:"adj_imsak"`
+	AdjFajr    int       `gorm:"not null;default:0" json:"adj_fajr"`
+	AdjSunrise int       `gorm:"not null;default:0" json:"adj_sunrise"`
+	AdjDhuhr   int       `gorm:"not null;default:0" json:"adj_dhuhr"`
+	AdjAsr     int       `gorm:"not null;default:0" json:"adj_asr"`
+	AdjMaghrib int       `gorm:"not null;default:0" json:"adj_maghrib"`
+	AdjIsha    int       `gorm:"not null;default:0" json:"adj_isha"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (MasjidPrayerSettingModel) TableName() string {
+	return "masjid_prayer_settings"
+}
+
+// DefaultPrayerSetting dipakai jika masjid belum menyimpan pengaturan
+func DefaultPrayerSetting(masjidID uuid.UUID) MasjidPrayerSettingModel {
+	return MasjidPrayerSettingModel{
+		MasjidID:  masjidID,
+		Method:    string(calculator.MethodKemenag),
+		AsrMethod: string(calculator.AsrShafii),
+	}
+}
+
+// CalculatorConfig mengubah pengaturan menjadi konfigurasi kalkulator
+func (s MasjidPrayerSettingModel) CalculatorConfig() calculator.Config {
+	return calculator.Config{
+		Method: calculator.Method(s.Method),
+		Asr:    calculator.AsrMethod(s.AsrMethod),
+		Adjustments: calculator.Adjustments{
+			Imsak:   s.AdjImsak,
+			Fajr:    s.AdjFajr,
+			Sunrise: s.AdjSunrise,
+			Dhuhr:   s.AdjDhuhr,
+			Asr:     s.AdjAsr,
+			Maghrib: s.AdjMaghrib,
+			Isha:    s.AdjIsha,
+		},
+	}
+}
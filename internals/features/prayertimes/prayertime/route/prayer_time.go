@@ -0,0 +1,31 @@
+package route
+
+import (
+	"masjidku/internals/configs"
+	"masjidku/internals/constants"
+	prayerTimeController "masjidku/internals/features/prayertimes/prayertime/controller"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	authMw "masjidku/internals/middlewares/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// PrayerTimeRoutes mendaftarkan jadwal sholat per masjid.
+// Jadwal bersifat publik, sedangkan pengaturan perhitungan hanya untuk pengurus masjid.
+func PrayerTimeRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy) {
+	prayerTimeCtrl := prayerTimeController.NewPrayerTimeController(db)
+	inMasjid := authMw.MasjidContext(db)
+	authMiddleware := authMw.AuthMiddleware(db, tokens, policy)
+
+	// 🔓 Jadwal sholat (publik)
+	app.Get("/api/masjids/:id/prayer-times", inMasjid, prayerTimeCtrl.GetDailyPrayerTimes)
+	app.Get("/api/masjids/:id/prayer-times/monthly", inMasjid, prayerTimeCtrl.GetMonthlyPrayerTimes)
+
+	// 🔐 Pengaturan metode & ihtiyat
+	settings := "/api/masjids/:id/prayer-settings"
+	app.Get(settings, authMiddleware, inMasjid, prayerTimeCtrl.GetSetting)
+	app.Put(settings, authMiddleware, inMasjid,
+		authMw.RequirePermission(constants.PermPrayerSettingsUpdate), authMw.RequireVerifiedEmail(db), prayerTimeCtrl.UpdateSetting)
+}
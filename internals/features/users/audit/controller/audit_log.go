@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+type AuditLogController struct {
+	DB *gorm.DB
+}
+
+func NewAuditLogController(db *gorm.DB) *AuditLogController {
+	return &AuditLogController{DB: db}
+}
+
+// 🔐 GET /api/admin/audit-logs?actor_id=&action=&target_type=&target_id=&from=&to=&page=&per_page=
+//
+// action boleh diakhiri ".*" untuk semua aksi satu domain (mis. action=auth.*).
+// from / to berformat YYYY-MM-DD (to inklusif sampai akhir hari) atau RFC3339.
+func (ac *AuditLogController) GetAuditLogs(c *fiber.Ctx) error {
+	query := ac.DB.Model(&audit.AuditLog{})
+
+	if q := c.Query("actor_id"); q != "" {
+		actorID, err := uuid.Parse(q)
+		if err != nil {
+			return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("actor_id harus UUID")
+		}
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if q := c.Query("action"); q != "" {
+		if prefix, ok := strings.CutSuffix(q, "*"); ok {
+			query = query.Where("action LIKE ?", likeEscaper.Replace(prefix)+"%")
+		} else {
+			query = query.Where("action = ?", q)
+		}
+	}
+	if q := c.Query("target_type"); q != "" {
+		query = query.Where("target_type = ?", q)
+	}
+	if q := c.Query("target_id"); q != "" {
+		query = query.Where("target_id = ?", q)
+	}
+	if q := c.Query("from"); q != "" {
+		from, _, err := parseTime(q)
+		if err != nil {
+			return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("Format from harus YYYY-MM-DD atau RFC3339")
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if q := c.Query("to"); q != "" {
+		to, dateOnly, err := parseTime(q)
+		if err != nil {
+			return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("Format to harus YYYY-MM-DD atau RFC3339")
+		}
+		if dateOnly {
+			query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("created_at <= ?", to)
+		}
+	}
+
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("page harus bilangan bulat >= 1")
+	}
+	perPage, err := strconv.Atoi(c.Query("per_page", strconv.Itoa(defaultPerPage)))
+	if err != nil || perPage < 1 || perPage > maxPerPage {
+		return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("per_page harus 1-%d", maxPerPage)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("hitung audit log: %w", err))
+	}
+
+	var logs []audit.AuditLog
+	if err := query.Order("id DESC").Offset((page - 1) * perPage).Limit(perPage).Find(&logs).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("ambil audit log: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Audit logs fetched successfully",
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+		"data":     logs,
+	})
+}
+
+// parseTime menerima YYYY-MM-DD (dateOnly=true, zona WIB) atau RFC3339
+func parseTime(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.ParseInLocation("2006-01-02", value, jakarta); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+var (
+	jakarta     = time.FixedZone("WIB", 7*60*60)
+	likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+)
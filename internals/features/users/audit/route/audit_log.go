@@ -0,0 +1,25 @@
+package route
+
+import (
+	"masjidku/internals/configs"
+	"masjidku/internals/constants"
+	auditController "masjidku/internals/features/users/audit/controller"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	authMw "masjidku/internals/middlewares/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AuditRoutes mendaftarkan admin API untuk membaca audit log (khusus admin platform)
+func AuditRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy) {
+	auditCtrl := auditController.NewAuditLogController(db)
+
+	admin := app.Group("/api/admin")
+	admin.Get("/audit-logs",
+		authMw.AuthMiddleware(db, tokens, policy),
+		authMw.RequirePermission(constants.PermAuditRead),
+		auditCtrl.GetAuditLogs,
+	)
+}
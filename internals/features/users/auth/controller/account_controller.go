@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	modelUser "masjidku/internals/features/users/user/models"
+	userService "masjidku/internals/features/users/user/service"
+	"masjidku/internals/ratelimit"
+)
+
+// ============================ RESTORE ACCOUNT ============================
+// RestoreAccount memulihkan akun yang dihapus pemiliknya selama masih dalam masa tenggang.
+// Akun yang dihapus tidak bisa login, jadi identitas dibuktikan dengan identifier + password
+// (dibatasi LoginGuard seperti login biasa), lalu sesi baru diterbitkan.
+func (ac *AuthController) RestoreAccount(c *fiber.Ctx) error {
+	var input struct {
+		Identifier string `json:"identifier"`
+		Password   string `json:"password"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+
+	ctx := c.UserContext()
+	meta := clientMeta(c)
+
+	user, err := ac.Deletion.FindDeleted(ctx, "email = ? OR user_name = ?", input.Identifier, input.Identifier)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Internal(fmt.Errorf("cari akun terhapus: %w", err))
+	}
+
+	wait, reason, err := ac.Guard.Check(ctx, input.Identifier, user)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cek percobaan login: %w", err))
+	}
+	if wait > 0 {
+		if err := ac.Guard.RecordFailure(ctx, input.Identifier, user, meta, reason); err != nil {
+			log.Printf("[ERROR] Failed to record login attempt: %v", err)
+		}
+		return ratelimit.TooManyRequests(c, "Terlalu banyak percobaan login, coba lagi nanti", ratelimit.Result{RetryAfter: wait})
+	}
+
+	// 📌 Pesan sama untuk akun tidak ada / password salah agar tidak membocorkan akun terhapus
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)) != nil {
+		if err := ac.Guard.RecordFailure(ctx, input.Identifier, user, meta, modelAuth.LoginFailInvalidCredentials); err != nil {
+			log.Printf("[ERROR] Failed to record login attempt: %v", err)
+		}
+		return apperror.Unauthorized(apperror.CodeInvalidCredentials)
+	}
+
+	entry := audit.FromRequest(c)
+	entry.ActorID = &user.ID
+	err = ac.Deletion.Restore(ctx, user, entry)
+	if errors.Is(err, userService.ErrNotRestorable) {
+		return apperror.Conflict(apperror.CodeAccountNotRestorable)
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("pulihkan akun: %w", err))
+	}
+	log.Printf("[SUCCESS] User restored own account: ID=%v", user.ID)
+
+	if err := ac.Guard.RecordSuccess(ctx, input.Identifier, user, meta); err != nil {
+		log.Printf("[ERROR] Failed to record login attempt: %v", err)
+	}
+	return ac.startSession(c, user)
+}
+
+// startSession: user dengan 2FA aktif mendapat challenge token, selain itu langsung sesi baru
+func (ac *AuthController) startSession(c *fiber.Ctx, user *modelUser.UserModel) error {
+	mfaEnabled, err := userMFAEnabled(ac.DB, user.ID)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cek status MFA: %w", err))
+	}
+	if mfaEnabled {
+		return sendMFAChallenge(c, ac.Config, user)
+	}
+	return ac.issueSession(c, user, false)
+}
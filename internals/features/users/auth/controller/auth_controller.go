@@ -1,56 +1,113 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
 	"masjidku/internals/configs"
+	"masjidku/internals/constants"
 	modelAuth "masjidku/internals/features/users/auth/models"
+	"masjidku/internals/features/users/auth/service"
+	"masjidku/internals/features/users/user/dto"
 	modelUser "masjidku/internals/features/users/user/models"
+	userService "masjidku/internals/features/users/user/service"
+	"masjidku/internals/mailer"
+	"masjidku/internals/ratelimit"
 
 	"gorm.io/gorm"
 )
 
 type AuthController struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Config *configs.Config
+	Mailer mailer.Mailer
+	Tokens *service.TokenService
+	Guard  *service.LoginGuard
+	Audit  *audit.Recorder
+	// Verifier membuat dan memeriksa link verifikasi email
+	Verifier *service.EmailVerifier
+	// Deletion dipakai untuk memulihkan akun yang sedang dalam masa tenggang penghapusan
+	Deletion *userService.AccountDeletion
 }
 
-func NewAuthController(db *gorm.DB) *AuthController {
-	return &AuthController{DB: db}
+func NewAuthController(db *gorm.DB, cfg *configs.Config, mail mailer.Mailer, tokens *service.TokenService, guard *service.LoginGuard, recorder *audit.Recorder, deletion *userService.AccountDeletion) *AuthController {
+	return &AuthController{DB: db, Config: cfg, Mailer: mail, Tokens: tokens, Guard: guard, Audit: recorder,
+		Verifier: service.NewEmailVerifier(cfg, mail), Deletion: deletion}
+}
+
+// setRefreshCookie menyimpan refresh_token di HttpOnly cookie sesuai konfigurasi cookie
+func (ac *AuthController) setRefreshCookie(c *fiber.Ctx, value string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    value,
+		Domain:   ac.Config.Cookie.Domain,
+		HTTPOnly: true,
+		Secure:   ac.Config.Cookie.Secure,
+		SameSite: ac.Config.Cookie.SameSite,
+		Expires:  expires,
+	})
+}
+
+// registerInput adalah field yang boleh diisi sendiri saat registrasi. Sengaja tanpa role:
+// role selalu diisi server (constants.RoleUser) dan hanya bisa diubah admin.
+type registerInput struct {
+	UserName         string  `json:"user_name" validate:"required,min=3,max=50"`
+	Email            string  `json:"email" validate:"required,email"`
+	Password         string  `json:"password" validate:"required,min=8"`
+	SecurityQuestion string  `json:"security_question"`
+	SecurityAnswer   string  `json:"security_answer"`
+	DonationName     *string `json:"donation_name" validate:"omitempty,max=100"`
+	OriginalName     *string `json:"original_name" validate:"omitempty,max=100"`
 }
 
 // ============================ REGISTER ============================
 func (ac *AuthController) Register(c *fiber.Ctx) error {
-	var input modelUser.UserModel
+	var input registerInput
 	if err := c.BodyParser(&input); err != nil {
 		log.Printf("[ERROR] Failed to parse request body: %v", err)
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request format"})
+		return apperror.BadRequest(apperror.CodeInvalidBody)
 	}
-	if err := input.Validate(); err != nil {
-		log.Printf("[ERROR] Validation failed: %v", err)
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	if err := apperror.Validate(&input); err != nil {
+		return err
 	}
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		log.Printf("[ERROR] Failed to hash password: %v", err)
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to secure password"})
+		return apperror.Internal(fmt.Errorf("hash password: %w", err))
+	}
+	user := modelUser.UserModel{
+		UserName:         input.UserName,
+		Email:            input.Email,
+		Password:         string(passwordHash),
+		Role:             constants.RoleUser,
+		SecurityQuestion: input.SecurityQuestion,
+		SecurityAnswer:   input.SecurityAnswer,
+		DonationName:     input.DonationName,
+		OriginalName:     input.OriginalName,
+		// EmailVerifiedAt hanya bisa diisi lewat link verifikasi
 	}
-	input.Password = string(passwordHash)
-	if err := ac.DB.Create(&input).Error; err != nil {
-		log.Printf("[ERROR] Failed to save user to database: %v", err)
+	if err := ac.DB.Create(&user).Error; err != nil {
 		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			return c.Status(400).JSON(fiber.Map{"error": "Email already registered"})
+			return apperror.Conflict(apperror.CodeEmailTaken)
 		}
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to register user"})
+		return apperror.Internal(fmt.Errorf("simpan user: %w", err))
 	}
-	log.Printf("[SUCCESS] User registered: ID=%v, Email=%s", input.ID, input.Email)
-	return c.Status(201).JSON(fiber.Map{"message": "User registered successfully"})
+	log.Printf("[SUCCESS] User registered: ID=%v, Email=%s", user.ID, user.Email)
+
+	// 📌 Gagal kirim email tidak menggagalkan registrasi; user bisa minta kirim ulang
+	if err := ac.Verifier.Send(c.UserContext(), &user); err != nil {
+		log.Printf("[ERROR] Failed to send verification email: %v", err)
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "User registered successfully. Please check your email to verify your account"})
 }
 
 // ============================ LOGIN ============================
@@ -60,163 +117,174 @@ func (ac *AuthController) Login(c *fiber.Ctx) error {
 		Password   string `json:"password"`
 	}
 	if err := c.BodyParser(&input); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+		return apperror.BadRequest(apperror.CodeInvalidBody)
 	}
 
-	var user modelUser.UserModel
-	if err := ac.DB.Where("email = ? OR user_name = ?", input.Identifier, input.Identifier).First(&user).Error; err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "Invalid email, username, or password"})
-	}
+	ctx := c.UserContext()
+	meta := clientMeta(c)
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "Invalid email, username, or password"})
+	// 📌 target nil jika identifier tidak terdaftar; percobaan tetap dicatat & dibatasi
+	var user modelUser.UserModel
+	var target *modelUser.UserModel
+	err := ac.DB.Where("email = ? OR user_name = ?", input.Identifier, input.Identifier).First(&user).Error
+	switch {
+	case err == nil:
+		target = &user
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.Internal(fmt.Errorf("cari user: %w", err))
 	}
 
-	// Generate Access Token (15 menit)
-	accessExp := time.Now().Add(15 * time.Minute)
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":        user.ID.String(),
-		"user_name": user.UserName,
-		"role":      user.Role,
-		"exp":       accessExp.Unix(),
-	})
-	accessTokenString, err := accessToken.SignedString([]byte(configs.JWTSecret))
+	// 📌 Akun terkunci / delay progresif dicek sebelum password, dengan pesan yang sama
+	wait, reason, err := ac.Guard.Check(ctx, input.Identifier, target)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate access token"})
+		return apperror.Internal(fmt.Errorf("cek percobaan login: %w", err))
+	}
+	if wait > 0 {
+		if err := ac.Guard.RecordFailure(ctx, input.Identifier, target, meta, reason); err != nil {
+			log.Printf("[ERROR] Failed to record login attempt: %v", err)
+		}
+		return ratelimit.TooManyRequests(c, "Terlalu banyak percobaan login, coba lagi nanti", ratelimit.Result{RetryAfter: wait})
 	}
 
-	// Generate Refresh Token (7 hari)
-	refreshExp := time.Now().Add(7 * 24 * time.Hour)
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":  user.ID.String(),
-		"exp": refreshExp.Unix(),
-	})
-	refreshTokenString, err := refreshToken.SignedString([]byte(configs.JWTRefreshSecret))
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate refresh token"})
+	if target == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)) != nil {
+		if err := ac.Guard.RecordFailure(ctx, input.Identifier, target, meta, modelAuth.LoginFailInvalidCredentials); err != nil {
+			log.Printf("[ERROR] Failed to record login attempt: %v", err)
+		}
+		return apperror.Unauthorized(apperror.CodeInvalidCredentials)
 	}
 
-	// Simpan refresh token ke DB
-	rt := modelAuth.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshTokenString,
-		ExpiresAt: refreshExp,
+	if err := ac.Guard.RecordSuccess(ctx, input.Identifier, &user, meta); err != nil {
+		log.Printf("[ERROR] Failed to record login attempt: %v", err)
 	}
-	if err := ac.DB.Create(&rt).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to store refresh token"})
+	// 📌 Akun yang di-ban / disuspend ditolak setelah password benar (alasan hanya untuk pemilik akun)
+	if err := user.BlockedError(time.Now()); err != nil {
+		return err
 	}
 
-	user.Password = ""
-	// Set refresh_token ke dalam HttpOnly cookie
-	c.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshTokenString,
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "Strict", // bisa diubah ke Lax jika butuh cross-domain
-		Expires:  refreshExp,
-	})
-
-	// Kirim access_token dan user data saja
-	return c.JSON(fiber.Map{
-		"access_token": accessTokenString,
-		"user": fiber.Map{
-			"id":        user.ID,
-			"user_name": user.UserName,
-			"email":     user.Email,
-			"role":      user.Role,
-		},
-	})
+	// 📌 User dengan 2FA aktif mendapat challenge token, bukan access token
+	mfaEnabled, err := userMFAEnabled(ac.DB, user.ID)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cek status MFA: %w", err))
+	}
+	if mfaEnabled {
+		return sendMFAChallenge(c, ac.Config, &user)
+	}
 
+	return ac.issueSession(c, &user, false)
 }
 
-func (ac *AuthController) RefreshToken(c *fiber.Ctx) error {
-	// 1. Ambil refresh_token dari cookie
-	oldToken := c.Cookies("refresh_token")
-	if oldToken == "" {
-		return c.Status(401).JSON(fiber.Map{"error": "Refresh token not found"})
+// NotifyAccountLocked adalah hook LoginGuard: memberi tahu user lewat email bahwa akunnya
+// dikunci sementara, beserta saran reset password jika bukan dia yang mencoba login
+func (ac *AuthController) NotifyAccountLocked(ctx context.Context, event service.LockoutEvent) {
+	link := strings.TrimRight(ac.Config.App.FrontendURL, "/") + "/forgot-password"
+	msg := mailer.Message{
+		To:      event.User.Email,
+		Subject: "Akun Masjidku Anda dikunci sementara",
+		Body: fmt.Sprintf("Assalamu'alaikum %s,\n\n"+
+			"Terdeteksi %d percobaan login gagal ke akun Anda (terakhir dari IP %s). "+
+			"Untuk keamanan, login dengan password dikunci sampai %s.\n\n"+
+			"Jika itu bukan Anda, segera reset password lewat link berikut (akun langsung terbuka setelah reset):\n\n%s\n",
+			event.User.UserName, event.Failures, event.Meta.IP, event.Until.Format("02 Jan 2006 15:04 MST"), link),
+	}
+	if err := ac.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("[ERROR] Failed to send account locked email: %v", err)
 	}
+}
 
-	// 2. Verifikasi JWT refresh token
-	token, err := jwt.Parse(oldToken, func(t *jwt.Token) (interface{}, error) {
-		return []byte(configs.JWTRefreshSecret), nil
+// AuditAccountLocked adalah hook LoginGuard: mencatat penguncian akun ke audit log
+func (ac *AuthController) AuditAccountLocked(ctx context.Context, event service.LockoutEvent) {
+	err := ac.Audit.Record(ctx, audit.Entry{
+		Action:     audit.ActionAccountLocked,
+		TargetType: audit.TargetUser,
+		TargetID:   event.User.ID.String(),
+		Metadata:   map[string]interface{}{"failures": event.Failures, "locked_until": event.Until},
+		IP:         event.Meta.IP,
+		UserAgent:  event.Meta.UserAgent,
 	})
-	if err != nil || !token.Valid {
-		return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired refresh token"})
+	if err != nil {
+		log.Printf("[ERROR] Failed to record audit log %s: %v", audit.ActionAccountLocked, err)
 	}
+}
 
-	// 3. Ambil claims (user ID)
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || claims["id"] == nil {
-		return c.Status(401).JSON(fiber.Map{"error": "Invalid token claims"})
-	}
-	userID := claims["id"].(string)
+// sessionUser adalah data user di response login: data akun sendiri + penanda sesi 2FA
+type sessionUser struct {
+	dto.UserSelf
+	MFA bool `json:"mfa"`
+}
 
-	// 4. Cek token di database (validasi)
-	var stored modelAuth.RefreshToken
-	if err := ac.DB.Where("token = ?", oldToken).First(&stored).Error; err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "Refresh token not registered"})
-	}
-	if time.Now().After(stored.ExpiresAt) {
-		ac.DB.Delete(&stored)
-		return c.Status(401).JSON(fiber.Map{"error": "Refresh token expired"})
-	}
+// clientMeta mengambil informasi perangkat untuk dicatat di sesi
+func clientMeta(c *fiber.Ctx) service.ClientMeta {
+	return service.ClientMeta{UserAgent: c.Get(fiber.HeaderUserAgent), IP: c.IP()}
+}
 
-	// 5. Ambil user
-	var user modelUser.UserModel
-	if err := ac.DB.First(&user, "id = ?", userID).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+// issueSession menerbitkan access token + refresh token lewat TokenService,
+// menyimpan refresh token di cookie, lalu mengirim access_token dan data user.
+// mfa menandai sesi yang lolos verifikasi 2FA.
+func (ac *AuthController) issueSession(c *fiber.Ctx, user *modelUser.UserModel, mfa bool) error {
+	// Semua jalur login (password, 2FA, Google, pulihkan akun) lewat sini
+	if err := user.BlockedError(time.Now()); err != nil {
+		return err
+	}
+	pair, err := ac.Tokens.Issue(ac.DB, user, mfa, clientMeta(c))
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("terbitkan token: %w", err))
 	}
 
-	// 6. Hapus refresh token lama
-	ac.DB.Delete(&stored)
+	// Set refresh_token ke dalam HttpOnly cookie
+	ac.setRefreshCookie(c, pair.RefreshToken, pair.RefreshExpiresAt)
 
-	// 7. Generate access token baru
-	accessExp := time.Now().Add(15 * time.Minute)
-	newAccessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":        user.ID.String(),
-		"user_name": user.UserName,
-		"role":      user.Role,
-		"exp":       accessExp.Unix(),
+	ac.Audit.Log(c, audit.Entry{
+		ActorID:    &user.ID,
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID.String(),
+		Metadata:   map[string]interface{}{"mfa": mfa},
 	})
-	accessTokenString, err := newAccessToken.SignedString([]byte(configs.JWTSecret))
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate access token"})
-	}
 
-	// 8. Generate refresh token baru
-	refreshExp := time.Now().Add(7 * 24 * time.Hour)
-	newRefreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":  user.ID.String(),
-		"exp": refreshExp.Unix(),
+	// Kirim access_token dan user data saja
+	return c.JSON(fiber.Map{
+		"access_token": pair.AccessToken,
+		"expires_in":   int(time.Until(pair.AccessExpiresAt).Seconds()),
+		"user":         sessionUser{UserSelf: dto.ToUserSelf(user), MFA: mfa},
 	})
-	newRefreshTokenString, err := newRefreshToken.SignedString([]byte(configs.JWTRefreshSecret))
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate new refresh token"})
-	}
+}
 
-	// 9. Simpan refresh token baru ke DB
-	newRT := modelAuth.RefreshToken{
-		UserID:    user.ID,
-		Token:     newRefreshTokenString,
-		ExpiresAt: refreshExp,
+func (ac *AuthController) RefreshToken(c *fiber.Ctx) error {
+	// 1. Ambil refresh_token dari cookie
+	oldToken := c.Cookies("refresh_token")
+	if oldToken == "" {
+		return apperror.Unauthorized(apperror.CodeTokenMissing)
 	}
-	if err := ac.DB.Create(&newRT).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to store new refresh token"})
+
+	// 2. Verifikasi, tandai token lama rotated, terbitkan pasangan token baru
+	pair, _, err := ac.Tokens.Rotate(ac.DB, oldToken, clientMeta(c))
+	var blocked *apperror.Error
+	switch {
+	case errors.Is(err, service.ErrInvalidToken), errors.Is(err, service.ErrTokenNotRegistered):
+		return apperror.Unauthorized(apperror.CodeTokenInvalid).WithCause(err)
+	case errors.Is(err, service.ErrTokenRevoked):
+		ac.setRefreshCookie(c, "", time.Now().Add(-time.Hour))
+		return apperror.Unauthorized(apperror.CodeSessionRevoked)
+	case errors.Is(err, service.ErrTokenReused):
+		log.Printf("[WARNING] Refresh token reuse detected from IP=%s, session revoked", c.IP())
+		ac.setRefreshCookie(c, "", time.Now().Add(-time.Hour))
+		return apperror.Unauthorized(apperror.CodeTokenReused)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.NotFound(apperror.CodeUserNotFound)
+	case errors.As(err, &blocked):
+		// Akun di-ban / disuspend (lihat UserModel.BlockedError)
+		ac.setRefreshCookie(c, "", time.Now().Add(-time.Hour))
+		return blocked
+	case err != nil:
+		return apperror.Internal(fmt.Errorf("rotasi refresh token: %w", err))
 	}
 
-	// 10. Set cookie refresh token baru
-	c.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    newRefreshTokenString,
-		HTTPOnly: true,
-		Secure:   true,
-		SameSite: "Strict",
-		Expires:  refreshExp,
-	})
+	// 3. Set cookie refresh token baru
+	ac.setRefreshCookie(c, pair.RefreshToken, pair.RefreshExpiresAt)
 
-	// 11. Return access token baru
+	// 4. Return access token baru
 	return c.JSON(fiber.Map{
-		"access_token": accessTokenString,
+		"access_token": pair.AccessToken,
+		"expires_in":   int(time.Until(pair.AccessExpiresAt).Seconds()),
 	})
 }
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"masjidku/internals/apperror"
+	modelUser "masjidku/internals/features/users/user/models"
+)
+
+// 🔥 VERIFY EMAIL - GET /auth/verify-email?token=...
+func (ac *AuthController) VerifyEmail(c *fiber.Ctx) error {
+	claims, err := ac.Verifier.Parse(c.Query("token"))
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeVerificationTokenInvalid)
+	}
+
+	var user modelUser.UserModel
+	if err := ac.DB.First(&user, "id = ?", claims.UserID).Error; err != nil {
+		return apperror.NotFound(apperror.CodeUserNotFound)
+	}
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return apperror.BadRequest(apperror.CodeVerificationTokenInvalid)
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := ac.DB.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			return apperror.Internal(fmt.Errorf("verifikasi email: %w", err))
+		}
+		log.Printf("[SUCCESS] Email verified: UserID=%v", user.ID)
+	}
+
+	return c.JSON(fiber.Map{"message": "Email verified successfully"})
+}
+
+// 🔥 RESEND VERIFICATION - POST /auth/verify-email/resend (dibatasi rate limiter di route)
+func (ac *AuthController) ResendVerificationEmail(c *fiber.Ctx) error {
+	var input struct {
+		Email string `json:"email" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	input.Email = strings.TrimSpace(input.Email)
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	// 📌 Response selalu sama agar tidak bisa dipakai untuk menebak email yang terdaftar
+	response := fiber.Map{"message": "Jika email terdaftar dan belum diverifikasi, link verifikasi telah dikirim"}
+
+	var user modelUser.UserModel
+	if err := ac.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		return c.JSON(response)
+	}
+	if user.EmailVerifiedAt != nil {
+		return c.JSON(response)
+	}
+
+	if err := ac.Verifier.Send(c.UserContext(), &user); err != nil {
+		return apperror.Internal(fmt.Errorf("kirim email verifikasi: %w", err))
+	}
+	return c.JSON(response)
+}
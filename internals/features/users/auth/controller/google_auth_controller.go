@@ -14,11 +14,11 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
 	"masjidku/internals/configs"
 	"masjidku/internals/features/users/user/models"
 )
@@ -51,39 +51,24 @@ type TokenResponse struct {
 	TokenType   string `json:"token_type"`
 }
 
-// UserResponse is the clean data structure sent to the frontend
-type UserResponse struct {
-	ID           uuid.UUID `json:"id"` 
-	UserName     string    `json:"user_name"`
-	Email        string    `json:"email"`
-	GoogleID     *string   `json:"google_id,omitempty"`
-	Role         string    `json:"role"`
-	DonationName *string   `json:"donation_name,omitempty"`
-	OriginalName *string   `json:"original_name,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-}
-
-// AuthResponse is the response sent to the frontend after successful authentication
-type AuthResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
-}
-
 // GoogleAuthController handles Google authentication
 type GoogleAuthController struct {
 	DB           *gorm.DB
+	Config       *configs.Config
 	GoogleConfig GoogleConfig
+	Audit        *audit.Recorder
 }
 
 // NewGoogleAuthController creates a new GoogleAuthController
-func NewGoogleAuthController(db *gorm.DB) *GoogleAuthController {
+func NewGoogleAuthController(db *gorm.DB, cfg *configs.Config, recorder *audit.Recorder) *GoogleAuthController {
 	return &GoogleAuthController{
-		DB: db,
+		DB:     db,
+		Config: cfg,
+		Audit:  recorder,
 		GoogleConfig: GoogleConfig{
-			ClientID:     configs.GetEnv("GOOGLE_CLIENT_ID"),
-			ClientSecret: configs.GetEnv("GOOGLE_CLIENT_SECRET"),
-			RedirectURL:  configs.GetEnv("GOOGLE_REDIRECT_URL"),
+			ClientID:     cfg.Google.ClientID,
+			ClientSecret: cfg.Google.ClientSecret,
+			RedirectURL:  cfg.Google.RedirectURL,
 			Scopes:       []string{"profile", "email"},
 			AuthURL:      "https://accounts.google.com/o/oauth2/auth",
 			TokenURL:     "https://oauth2.googleapis.com/token",
@@ -99,10 +84,7 @@ func (gc *GoogleAuthController) GoogleLogin(c *fiber.Ctx) error {
 	// Generate cryptographically secure random state
 	state, err := generateRandomString(32)
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate random state: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Authentication initialization failed",
-		})
+		return apperror.Internal(fmt.Errorf("generate state OAuth: %w", err))
 	}
 
 	// Store state in session
@@ -111,7 +93,7 @@ func (gc *GoogleAuthController) GoogleLogin(c *fiber.Ctx) error {
 		Value:    state,
 		Expires:  time.Now().Add(10 * time.Minute),
 		HTTPOnly: true,
-		Secure:   true,
+		Secure:   gc.Config.Cookie.Secure,
 		SameSite: "lax",
 		Path:     "/",
 	})
@@ -129,7 +111,9 @@ func (gc *GoogleAuthController) GoogleLogin(c *fiber.Ctx) error {
 	return c.Redirect(authURL)
 }
 
-// GoogleCallback handles the callback from Google
+// GoogleCallback handles the OAuth callback from Google. Instead of returning a JWT in
+// JSON it redirects the browser to the frontend with a one-time code (?code=) that the
+// frontend exchanges via POST /auth/google/exchange. Failures redirect with ?error=.
 func (gc *GoogleAuthController) GoogleCallback(c *fiber.Ctx) error {
 	log.Println("[INFO] Handling Google callback")
 
@@ -139,18 +123,14 @@ func (gc *GoogleAuthController) GoogleCallback(c *fiber.Ctx) error {
 
 	if code == "" {
 		log.Println("[ERROR] No code received from Google!")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No code received from Google",
-		})
+		return gc.redirectToFrontend(c, "error", "google_no_code")
 	}
 
 	// Verify state
 	storedState := c.Cookies("google_oauth_state")
 	if storedState == "" || storedState != state {
 		log.Println("[ERROR] Invalid state parameter")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid state parameter",
-		})
+		return gc.redirectToFrontend(c, "error", "invalid_state")
 	}
 
 	// Clear the state cookie
@@ -159,7 +139,7 @@ func (gc *GoogleAuthController) GoogleCallback(c *fiber.Ctx) error {
 		Value:    "",
 		Expires:  time.Now().Add(-time.Hour),
 		HTTPOnly: true,
-		Secure:   true,
+		Secure:   gc.Config.Cookie.Secure,
 		SameSite: "lax",
 		Path:     "/",
 	})
@@ -168,56 +148,42 @@ func (gc *GoogleAuthController) GoogleCallback(c *fiber.Ctx) error {
 	tokenResponse, err := gc.exchangeCodeForToken(code)
 	if err != nil {
 		log.Printf("[ERROR] Token exchange failed: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to authenticate with Google",
-		})
+		return gc.redirectToFrontend(c, "error", "google_auth_failed")
 	}
 
 	// 3. Get user info
 	userInfo, err := gc.getUserInfo(tokenResponse.AccessToken)
 	if err != nil {
 		log.Printf("[ERROR] Failed to get user info: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve user information",
-		})
+		return gc.redirectToFrontend(c, "error", "google_userinfo_failed")
 	}
 
 	// 4. Process user data and create/update user in database
-	user, err := gc.processUserData(userInfo)
+	user, err := gc.processUserData(c, userInfo)
 	if err != nil {
 		log.Printf("[ERROR] Failed to process user data: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process user account",
-		})
+		return gc.redirectToFrontend(c, "error", "account_failed")
 	}
 
-	// 5. Generate JWT token
-	token, err := gc.generateJWTToken(user)
+	// 5. Issue a one-time login code (2FA, if enabled, is enforced at exchange time)
+	loginCode, err := createOAuthLoginCode(gc.DB, user.ID, gc.Config.Auth.OAuthCodeTTL)
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate token: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate authentication token",
-		})
+		log.Printf("[ERROR] Failed to create login code: %v", err)
+		return gc.redirectToFrontend(c, "error", "account_failed")
 	}
 
-	// 6. Prepare and return clean response
-	response := AuthResponse{
-		Token: token,
-		User: UserResponse{
-			ID:           user.ID,
-			UserName:     user.UserName,
-			Email:        user.Email,
-			GoogleID:     user.GoogleID,
-			Role:         user.Role,
-			DonationName: user.DonationName,
-			OriginalName: user.OriginalName,
-			CreatedAt:    user.CreatedAt,
-			UpdatedAt:    user.UpdatedAt,
-		},
-	}
+	log.Printf("[SUCCESS] Google login successful for user: ID=%v, Email=%s", user.ID, user.Email)
+	return gc.redirectToFrontend(c, "code", loginCode)
+}
 
-	log.Printf("[SUCCESS] Google login successful for user: ID=%d, Email=%s", user.ID, user.Email)
-	return c.JSON(response)
+// redirectToFrontend redirects the browser to the frontend callback page with a single query parameter
+func (gc *GoogleAuthController) redirectToFrontend(c *fiber.Ctx, key, value string) error {
+	target := gc.Config.GoogleFrontendCallbackURL()
+	sep := "?"
+	if strings.Contains(target, "?") {
+		sep = "&"
+	}
+	return c.Redirect(target+sep+url.Values{key: {value}}.Encode(), fiber.StatusFound)
 }
 
 // exchangeCodeForToken exchanges the authorization code for an access token
@@ -305,8 +271,9 @@ func (gc *GoogleAuthController) getUserInfo(accessToken string) (*GoogleUserInfo
 	return &userInfo, nil
 }
 
-// processUserData processes the user information and creates or updates the user in the database
-func (gc *GoogleAuthController) processUserData(userInfo *GoogleUserInfo) (*models.UserModel, error) {
+// processUserData processes the user information and creates or updates the user in the database.
+// Account creation and linking a Google ID to an existing account are audited in the same transaction.
+func (gc *GoogleAuthController) processUserData(c *fiber.Ctx, userInfo *GoogleUserInfo) (*models.UserModel, error) {
 	var user models.UserModel
 	tx := gc.DB.Begin()
 
@@ -363,6 +330,10 @@ func (gc *GoogleAuthController) processUserData(userInfo *GoogleUserInfo) (*mode
 				SecurityAnswer:   "google_auth_user",
 				OriginalName:     &userName,
 			}
+			if userInfo.VerifiedEmail {
+				now := time.Now()
+				newUser.EmailVerifiedAt = &now
+			}
 
 			// Create new user
 			if err := tx.Create(&newUser).Error; err != nil {
@@ -370,6 +341,18 @@ func (gc *GoogleAuthController) processUserData(userInfo *GoogleUserInfo) (*mode
 				return nil, fmt.Errorf("failed to create user: %w", err)
 			}
 
+			entry := audit.FromRequest(c)
+			entry.ActorID = &newUser.ID
+			entry.Action = audit.ActionUserCreate
+			entry.TargetType = audit.TargetUser
+			entry.TargetID = newUser.ID.String()
+			entry.After = newUser
+			entry.Metadata = map[string]interface{}{"provider": "google"}
+			if err := gc.Audit.RecordTx(tx, entry); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to record audit log: %w", err)
+			}
+
 			// Commit the transaction
 			if err := tx.Commit().Error; err != nil {
 				return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -384,14 +367,32 @@ func (gc *GoogleAuthController) processUserData(userInfo *GoogleUserInfo) (*mode
 		} else {
 			// User found by email, update Google ID
 			log.Printf("[INFO] Updating existing user with Google ID: %s", userInfo.ID)
+			before := user
 			googleID := userInfo.ID
 			user.GoogleID = &googleID
+			// Google sudah memverifikasi kepemilikan email ini
+			if user.EmailVerifiedAt == nil && userInfo.VerifiedEmail {
+				now := time.Now()
+				user.EmailVerifiedAt = &now
+			}
 
 			if err := tx.Save(&user).Error; err != nil {
 				tx.Rollback()
 				return nil, fmt.Errorf("failed to update user with Google ID: %w", err)
 			}
 
+			entry := audit.FromRequest(c)
+			entry.ActorID = &user.ID
+			entry.Action = audit.ActionGoogleLink
+			entry.TargetType = audit.TargetUser
+			entry.TargetID = user.ID.String()
+			entry.Before = before
+			entry.After = user
+			if err := gc.Audit.RecordTx(tx, entry); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to record audit log: %w", err)
+			}
+
 			if err := tx.Commit().Error; err != nil {
 				return nil, fmt.Errorf("failed to commit transaction: %w", err)
 			}
@@ -406,6 +407,15 @@ func (gc *GoogleAuthController) processUserData(userInfo *GoogleUserInfo) (*mode
 		needsUpdate := false
 		if user.Email != userInfo.Email {
 			user.Email = userInfo.Email
+			user.EmailVerifiedAt = nil
+			if userInfo.VerifiedEmail {
+				now := time.Now()
+				user.EmailVerifiedAt = &now
+			}
+			needsUpdate = true
+		} else if user.EmailVerifiedAt == nil && userInfo.VerifiedEmail {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
 			needsUpdate = true
 		}
 
@@ -437,25 +447,6 @@ func (gc *GoogleAuthController) processUserData(userInfo *GoogleUserInfo) (*mode
 	return &user, nil
 }
 
-// generateJWTToken generates a JWT token for the user
-func (gc *GoogleAuthController) generateJWTToken(user *models.UserModel) (string, error) {
-	expirationTime := time.Now().Add(time.Hour * 96) // 4 days
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":    user.ID,
-		"email": user.Email,
-		"role":  user.Role,
-		"exp":   expirationTime.Unix(),
-		"iat":   time.Now().Unix(),
-	})
-
-	tokenString, err := token.SignedString([]byte(configs.GetEnv("JWT_SECRET")))
-	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
-	}
-
-	return tokenString, nil
-}
-
 // generateRandomString generates a cryptographically secure random string
 func generateRandomString(length int) (string, error) {
 	b := make([]byte, length)
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	"masjidku/internals/constants"
+	"masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	"masjidku/internals/features/users/user/dto"
+	modelUser "masjidku/internals/features/users/user/models"
+	authMw "masjidku/internals/middlewares/auth"
+)
+
+// ImpersonationController: admin platform "login sebagai" user untuk membantu / menelusuri masalah.
+// Token yang diterbitkan berumur pendek, membawa claim act (RFC 8693), tidak bisa dipakai untuk
+// aksi sensitif (authMw.DenyImpersonation) dan setiap request-nya dicatat di audit log.
+type ImpersonationController struct {
+	DB     *gorm.DB
+	Tokens *service.TokenService
+	Policy *rbacService.Policy
+	Audit  *audit.Recorder
+	TTL    time.Duration
+}
+
+func NewImpersonationController(db *gorm.DB, tokens *service.TokenService, policy *rbacService.Policy, recorder *audit.Recorder, ttl time.Duration) *ImpersonationController {
+	return &ImpersonationController{DB: db, Tokens: tokens, Policy: policy, Audit: recorder, TTL: ttl}
+}
+
+type impersonateInput struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+	Reason string `json:"reason" validate:"required,max=255"` // wajib, dicatat di audit log
+}
+
+// ============================ START ============================
+// POST /api/auth/impersonate - menerbitkan access token atas nama user untuk admin yang login.
+// Tidak ada refresh token: setelah token kedaluwarsa admin kembali memakai tokennya sendiri.
+func (ic *ImpersonationController) Start(c *fiber.Ctx) error {
+	principal, ok := authMw.PrincipalFrom(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var input impersonateInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+	targetID, err := uuid.Parse(input.UserID)
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidID)
+	}
+	if targetID == principal.UserID {
+		return apperror.BadRequest(apperror.CodeCannotImpersonate).WithDetail("Tidak bisa login sebagai akun sendiri")
+	}
+
+	var target modelUser.UserModel
+	if err := ic.DB.First(&target, "id = ?", targetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound(apperror.CodeUserNotFound)
+		}
+		return apperror.Internal(fmt.Errorf("cari user: %w", err))
+	}
+	// 📌 Akun yang juga bisa impersonate (admin lain) tidak boleh di-impersonate agar tidak jadi jalan pintas hak akses
+	if ic.Policy.Can(target.Role, constants.PermUsersImpersonate) {
+		return apperror.Forbidden(apperror.CodeCannotImpersonate)
+	}
+	if err := target.BlockedError(time.Now()); err != nil {
+		return apperror.Forbidden(apperror.CodeCannotImpersonate).WithCause(err)
+	}
+
+	token, claims, err := ic.Tokens.IssueImpersonation(&target, principal.Claims, ic.TTL)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	expiresAt := claims.ExpiresAt.Time
+
+	// Token hanya dikirim jika awal impersonation berhasil dicatat
+	entry := audit.FromRequest(c)
+	entry.Action = audit.ActionImpersonationStart
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = target.ID.String()
+	entry.Metadata = map[string]interface{}{
+		"reason":     input.Reason,
+		"token_id":   claims.ID,
+		"expires_at": expiresAt.UTC().Format(time.RFC3339),
+	}
+	if err := ic.Audit.Record(c.UserContext(), entry); err != nil {
+		return apperror.Internal(fmt.Errorf("catat impersonation: %w", err))
+	}
+	log.Printf("[SUCCESS] Impersonation started: admin=%s user=%s jti=%s", principal.UserID, target.ID, claims.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"access_token": token,
+		"expires_in":   int(time.Until(expiresAt).Seconds()),
+		"user":         dto.ToUserAdmin(&target),
+		"impersonation": fiber.Map{
+			"impersonator_id": principal.UserID,
+			"reason":          input.Reason,
+			"expires_at":      expiresAt,
+		},
+	})
+}
+
+// ============================ END ============================
+// POST /api/auth/impersonate/end - dipanggil dengan token impersonation; token itu langsung dicabut
+func (ic *ImpersonationController) End(c *fiber.Ctx) error {
+	principal, ok := authMw.PrincipalFrom(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	if !principal.Impersonated() {
+		return apperror.BadRequest(apperror.CodeBadRequest).WithDetail("Token ini bukan token impersonation")
+	}
+
+	if err := ic.Tokens.RevokeAccess(c.UserContext(), principal.Claims); err != nil {
+		return apperror.Internal(fmt.Errorf("cabut token impersonation: %w", err))
+	}
+
+	entry := audit.FromRequest(c)
+	entry.Action = audit.ActionImpersonationEnd
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = principal.UserID.String()
+	entry.Metadata = map[string]interface{}{"token_id": principal.TokenID}
+	ic.Audit.Log(c, entry)
+	log.Printf("[SUCCESS] Impersonation ended: admin=%s user=%s", principal.Impersonator.UserID, principal.UserID)
+
+	return c.JSON(fiber.Map{"message": "Impersonation ended"})
+}
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// 🔑 JWKS - GET /.well-known/jwks.json
+// Public key untuk verifikasi access token (termasuk kunci lama yang masih dalam masa tenggang)
+func (ac *AuthController) JWKS(c *fiber.Ctx) error {
+	// Cache singkat: kunci baru hasil rotasi harus cepat terlihat oleh verifier lain
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(fiber.Map{"keys": ac.Tokens.Keys().JWKS()})
+}
@@ -0,0 +1,408 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	"masjidku/internals/configs"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	"masjidku/internals/features/users/auth/service"
+	modelUser "masjidku/internals/features/users/user/models"
+	authMw "masjidku/internals/middlewares/auth"
+)
+
+const (
+	mfaChallengePurpose = "mfa_challenge"
+	recoveryCodeCount   = 10
+)
+
+// userMFAEnabled mengecek apakah user sudah menyelesaikan enrollment 2FA
+func userMFAEnabled(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&modelAuth.UserMFA{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL", userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// sendMFAChallenge mengirim challenge token berumur pendek sebagai pengganti access token.
+// Dipakai oleh login password maupun login Google.
+func sendMFAChallenge(c *fiber.Ctx, cfg *configs.Config, user *modelUser.UserModel) error {
+	exp := time.Now().Add(cfg.Auth.MFAChallengeTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":     user.ID.String(),
+		"purpose": mfaChallengePurpose,
+		"jti":     uuid.NewString(),
+		"iat":     time.Now().Unix(),
+		"exp":     exp.Unix(),
+	})
+	signed, err := token.SignedString(mfaChallengeKey(cfg))
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("tanda tangani MFA challenge: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"mfa_required": true,
+		"mfa_token":    signed,
+		"expires_in":   int(cfg.Auth.MFAChallengeTTL.Seconds()),
+	})
+}
+
+// 🔥 LOGIN MFA - POST /auth/login/mfa (langkah kedua setelah /auth/login)
+func (ac *AuthController) LoginMFA(c *fiber.Ctx) error {
+	var input struct {
+		MFAToken string `json:"mfa_token" form:"mfa_token" validate:"required"`
+		Code     string `json:"code" form:"code" validate:"required"` // kode TOTP 6 digit atau kode pemulihan
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	token, err := jwt.Parse(input.MFAToken, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return mfaChallengeKey(ac.Config), nil
+	})
+	if err != nil || !token.Valid {
+		return apperror.Unauthorized(apperror.CodeMFAChallengeInvalid)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != mfaChallengePurpose {
+		return apperror.Unauthorized(apperror.CodeMFAChallengeInvalid)
+	}
+	idStr, _ := claims["sub"].(string)
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		return apperror.Unauthorized(apperror.CodeMFAChallengeInvalid)
+	}
+
+	var user modelUser.UserModel
+	if err := ac.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return apperror.Unauthorized(apperror.CodeMFAChallengeInvalid)
+	}
+
+	valid, err := ac.verifyMFACode(ac.DB, userID, input.Code)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("verifikasi kode MFA: %w", err))
+	}
+	if !valid {
+		log.Printf("[WARNING] Invalid MFA code for UserID=%v", userID)
+		return apperror.Unauthorized(apperror.CodeMFACodeInvalid)
+	}
+
+	return ac.issueSession(c, &user, true)
+}
+
+// 🔥 MFA STATUS - GET /api/auth/mfa
+func (ac *AuthController) GetMFAStatus(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var mfa modelAuth.UserMFA
+	err := ac.DB.First(&mfa, "user_id = ?", userID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Internal(fmt.Errorf("ambil status MFA: %w", err))
+	}
+
+	var remaining int64
+	ac.DB.Model(&modelAuth.MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining)
+
+	principal, _ := authMw.PrincipalFrom(c)
+	return c.JSON(fiber.Map{
+		"enabled":                  err == nil && mfa.EnabledAt != nil,
+		"enabled_at":               mfa.EnabledAt,
+		"recovery_codes_remaining": remaining,
+		"session_verified":         principal.MFA,
+	})
+}
+
+// 🔥 SETUP MFA - POST /api/auth/mfa/setup
+// Membuat secret baru dan mengembalikan URI otpauth:// untuk ditampilkan sebagai QR code.
+func (ac *AuthController) SetupMFA(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var user modelUser.UserModel
+	if err := ac.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return apperror.NotFound(apperror.CodeUserNotFound)
+	}
+
+	var existing modelAuth.UserMFA
+	if err := ac.DB.First(&existing, "user_id = ?", userID).Error; err == nil && existing.EnabledAt != nil {
+		return apperror.Conflict(apperror.CodeMFAAlreadyEnabled)
+	}
+
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("generate secret TOTP: %w", err))
+	}
+	box, err := ac.secretBox()
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("init MFA secret box: %w", err))
+	}
+	sealed, err := box.Seal(secret)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("enkripsi secret TOTP: %w", err))
+	}
+
+	// Enrollment yang belum dikonfirmasi boleh ditimpa
+	mfa := modelAuth.UserMFA{UserID: userID, SecretEnc: sealed}
+	if err := ac.DB.Save(&mfa).Error; err != nil {
+		return apperror.Internal(fmt.Errorf("simpan secret MFA: %w", err))
+	}
+
+	return c.JSON(fiber.Map{
+		"secret":      secret,
+		"otpauth_uri": service.ProvisioningURI(ac.Config.Auth.MFAIssuer, user.Email, secret),
+	})
+}
+
+// 🔥 ENABLE MFA - POST /api/auth/mfa/enable
+// Mengonfirmasi enrollment dengan kode pertama lalu mengembalikan kode pemulihan (hanya ditampilkan sekali).
+func (ac *AuthController) EnableMFA(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	var input struct {
+		Code string `json:"code" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	var codes []string
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		var mfa modelAuth.UserMFA
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&mfa, "user_id = ?", userID).Error; err != nil {
+			return apperror.BadRequest(apperror.CodeMFASetupRequired)
+		}
+		if mfa.EnabledAt != nil {
+			return apperror.Conflict(apperror.CodeMFAAlreadyEnabled)
+		}
+
+		step, ok, err := ac.checkTOTP(&mfa, input.Code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperror.Unauthorized(apperror.CodeMFACodeInvalid)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&mfa).Updates(map[string]interface{}{
+			"enabled_at":     now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return apperror.From(fmt.Errorf("aktifkan MFA: %w", err))
+	}
+
+	log.Printf("[SUCCESS] MFA enabled: UserID=%v", userID)
+	ac.Audit.Log(c, audit.Entry{Action: audit.ActionMFAEnable, TargetType: audit.TargetUser, TargetID: userID.String()})
+	return c.JSON(fiber.Map{
+		"message":        "Two-factor authentication enabled. Please log in again to start a verified session",
+		"recovery_codes": codes,
+	})
+}
+
+// 🔥 DISABLE MFA - POST /api/auth/mfa/disable
+func (ac *AuthController) DisableMFA(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	var input struct {
+		Password string `json:"password" validate:"required"`
+		Code     string `json:"code" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	var user modelUser.UserModel
+	if err := ac.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return apperror.NotFound(apperror.CodeUserNotFound)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		return apperror.Unauthorized(apperror.CodeInvalidPassword)
+	}
+
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		valid, err := ac.verifyMFACode(tx, userID, input.Code)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return apperror.Unauthorized(apperror.CodeMFACodeInvalid)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&modelAuth.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&modelAuth.UserMFA{}).Error
+	})
+	if err != nil {
+		return apperror.From(fmt.Errorf("nonaktifkan MFA: %w", err))
+	}
+
+	log.Printf("[SUCCESS] MFA disabled: UserID=%v", userID)
+	ac.Audit.Log(c, audit.Entry{Action: audit.ActionMFADisable, TargetType: audit.TargetUser, TargetID: userID.String()})
+	return c.JSON(fiber.Map{"message": "Two-factor authentication disabled"})
+}
+
+// 🔥 REGENERATE RECOVERY CODES - POST /api/auth/mfa/recovery-codes
+func (ac *AuthController) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	var input struct {
+		Code string `json:"code" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	var codes []string
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		valid, err := ac.verifyMFACode(tx, userID, input.Code)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return apperror.Unauthorized(apperror.CodeMFACodeInvalid)
+		}
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return apperror.From(fmt.Errorf("buat ulang kode pemulihan: %w", err))
+	}
+
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+// verifyMFACode menerima kode TOTP atau kode pemulihan. Kode TOTP yang sudah pernah
+// dipakai (counter <= last_used_step) dan kode pemulihan yang sudah terpakai ditolak.
+func (ac *AuthController) verifyMFACode(db *gorm.DB, userID uuid.UUID, code string) (bool, error) {
+	var mfa modelAuth.UserMFA
+	if err := db.First(&mfa, "user_id = ? AND enabled_at IS NOT NULL", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	step, ok, err := ac.checkTOTP(&mfa, code)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		// Update bersyarat agar kode yang sama tidak bisa dipakai dua kali secara paralel
+		res := db.Model(&modelAuth.UserMFA{}).
+			Where("user_id = ? AND last_used_step < ?", userID, step).
+			Update("last_used_step", step)
+		return res.RowsAffected == 1, res.Error
+	}
+
+	res := db.Model(&modelAuth.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if res.RowsAffected == 1 {
+		log.Printf("[INFO] Recovery code used: UserID=%v", userID)
+	}
+	return res.RowsAffected == 1, res.Error
+}
+
+func (ac *AuthController) checkTOTP(mfa *modelAuth.UserMFA, code string) (int64, bool, error) {
+	box, err := ac.secretBox()
+	if err != nil {
+		return 0, false, err
+	}
+	secret, err := box.Open(mfa.SecretEnc)
+	if err != nil {
+		return 0, false, err
+	}
+	step, ok := service.ValidateTOTP(secret, code, time.Now())
+	return step, ok, nil
+}
+
+func (ac *AuthController) secretBox() (*service.SecretBox, error) {
+	return service.NewSecretBox(ac.Config.Auth.MFAEncryptionKey, ac.Config.JWT.Secret, "masjidku/mfa-secret")
+}
+
+// mfaChallengeKey diturunkan dari JWT_SECRET sehingga challenge token tidak bisa dipakai sebagai access token
+func mfaChallengeKey(cfg *configs.Config) []byte {
+	mac := hmac.New(sha256.New, []byte(cfg.JWT.Secret))
+	mac.Write([]byte("masjidku/mfa-challenge"))
+	return mac.Sum(nil)
+}
+
+// replaceRecoveryCodes menghapus kode lama dan membuat kode pemulihan baru (disimpan sebagai hash)
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&modelAuth.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]modelAuth.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(buf))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		rows = append(rows, modelAuth.MFARecoveryCode{UserID: userID, CodeHash: hashToken(raw)})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
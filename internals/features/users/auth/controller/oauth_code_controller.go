@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"masjidku/internals/apperror"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	modelUser "masjidku/internals/features/users/user/models"
+)
+
+var errInvalidLoginCode = errors.New("invalid or expired login code")
+
+// createOAuthLoginCode membuat kode sekali pakai untuk login OAuth; hanya hash-nya yang disimpan
+func createOAuthLoginCode(db *gorm.DB, userID uuid.UUID, ttl time.Duration) (string, error) {
+	code, err := generateRandomString(43)
+	if err != nil {
+		return "", err
+	}
+	row := modelAuth.OAuthLoginCode{
+		UserID:    userID,
+		CodeHash:  hashToken(code),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := db.Create(&row).Error; err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// 🔥 EXCHANGE GOOGLE CODE - POST /auth/google/exchange
+// Menukar kode sekali pakai dari redirect Google callback dengan access token + refresh token.
+// User dengan 2FA aktif mendapat MFA challenge seperti login biasa.
+func (ac *AuthController) ExchangeGoogleCode(c *fiber.Ctx) error {
+	var input struct {
+		Code string `json:"code" validate:"required"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	var user modelUser.UserModel
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		var row modelAuth.OAuthLoginCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code_hash = ?", hashToken(input.Code)).First(&row).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvalidLoginCode
+			}
+			return err
+		}
+		if row.UsedAt != nil || time.Now().After(row.ExpiresAt) {
+			return errInvalidLoginCode
+		}
+		if err := tx.Model(&row).Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.First(&user, "id = ?", row.UserID).Error
+	})
+	if errors.Is(err, errInvalidLoginCode) {
+		return apperror.Unauthorized(apperror.CodeLoginCodeInvalid)
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("tukar kode login: %w", err))
+	}
+
+	mfaEnabled, err := userMFAEnabled(ac.DB, user.ID)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cek status MFA: %w", err))
+	}
+	if mfaEnabled {
+		return sendMFAChallenge(c, ac.Config, &user)
+	}
+
+	return ac.issueSession(c, &user, false)
+}
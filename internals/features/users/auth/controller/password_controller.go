@@ -1,95 +1,214 @@
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	modelAuth "masjidku/internals/features/users/auth/models"
 	modelUser "masjidku/internals/features/users/user/models"
+	"masjidku/internals/mailer"
+	authMw "masjidku/internals/middlewares/auth"
 )
 
-// 🔥 CHANGE PASSWORD (Menggunakan c.Locals dan Transaksi)
+// 🔥 CHANGE PASSWORD (Menggunakan Principal dan Transaksi)
 func (ac *AuthController) ChangePassword(c *fiber.Ctx) error {
 	// 🆔 Ambil User ID dari middleware (sudah divalidasi di AuthMiddleware)
-	userID, ok := c.Locals("user_id").(uint)
+	userID, ok := authMw.UserID(c)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized - Invalid token"})
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
 	}
 
-	// 📌 Parsing request body
+	// 📌 Parsing & validasi request body
 	var input struct {
-		OldPassword string `json:"old_password"`
-		NewPassword string `json:"new_password"`
+		OldPassword string `json:"old_password" validate:"required"`
+		NewPassword string `json:"new_password" validate:"required,min=8"`
 	}
 	if err := c.BodyParser(&input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request format"})
+		return apperror.BadRequest(apperror.CodeInvalidBody)
 	}
-
-	// 📌 Validasi input kosong
-	if input.OldPassword == "" || input.NewPassword == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Both old and new passwords are required"})
+	if err := apperror.Validate(&input); err != nil {
+		return err
 	}
 
 	// 🚨 Cek apakah password baru sama dengan yang lama
 	if input.OldPassword == input.NewPassword {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "New password must be different from old password"})
+		return apperror.BadRequest(apperror.CodePasswordUnchanged)
 	}
 
 	// 🔍 Cari user di database
 	var user modelUser.UserModel
-	if err := ac.DB.First(&user, userID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	if err := ac.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return apperror.NotFound(apperror.CodeUserNotFound)
 	}
 
 	// 🔑 Cek apakah password lama cocok
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.OldPassword)); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Old password is incorrect"})
+		return apperror.Unauthorized(apperror.CodeInvalidPassword)
 	}
 
 	// 🔒 Hash password baru
 	newHashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to hash new password"})
+		return apperror.Internal(fmt.Errorf("hash password baru: %w", err))
 	}
 
-	// 🔥 Update password menggunakan transaksi
-	tx := ac.DB.Begin()
-	if err := tx.Model(&user).Update("password", string(newHashedPassword)).Error; err != nil {
-		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update password"})
+	// 🔥 Update password menggunakan transaksi (audit log ikut dalam transaksi yang sama)
+	entry := audit.FromRequest(c)
+	entry.Action = audit.ActionPasswordChange
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = user.ID.String()
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", string(newHashedPassword)).Error; err != nil {
+			return err
+		}
+		return ac.Audit.RecordTx(tx, entry)
+	})
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("update password: %w", err))
 	}
-	tx.Commit()
 
 	// 🎉 Beri response sukses
 	return c.JSON(fiber.Map{"message": "Password changed successfully"})
 }
 
-// 🔥 RESET PASSWORD
-func (ac *AuthController) ResetPassword(c *fiber.Ctx) error {
+// 🔥 FORGOT PASSWORD - kirim link reset password ke email
+func (ac *AuthController) ForgotPassword(c *fiber.Ctx) error {
 	var input struct {
-		Email       string `json:"email"`
-		NewPassword string `json:"new_password"`
+		Email string `json:"email" validate:"required"`
 	}
-
-	// 📌 Parsing JSON input
 	if err := c.BodyParser(&input); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request format"})
+		return apperror.BadRequest(apperror.CodeInvalidBody)
 	}
+	input.Email = strings.TrimSpace(input.Email)
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+
+	// 📌 Response selalu sama agar tidak bisa dipakai untuk menebak email yang terdaftar,
+	// termasuk saat menyimpan token atau mengirim email gagal (error hanya dicatat di log)
+	response := fiber.Map{"message": "Jika email terdaftar, link reset password telah dikirim"}
 
-	// 📌 Cek user berdasarkan email kembali untuk memastikan
 	var user modelUser.UserModel
 	if err := ac.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("[ERROR] Failed to find user for password reset: %v", err)
+		}
+		return c.JSON(response)
+	}
+
+	rawToken, err := generateRandomString(43)
+	if err != nil {
+		log.Printf("[ERROR] Failed to generate password reset token: %v", err)
+		return c.JSON(response)
+	}
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		// 📌 Token lama yang belum dipakai dianggap hangus
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&modelAuth.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&modelAuth.PasswordResetToken{
+			UserID:      user.ID,
+			TokenHash:   hashToken(rawToken),
+			ExpiresAt:   time.Now().Add(ac.Config.Auth.PasswordResetTTL),
+			RequestedIP: c.IP(),
+		}).Error
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to save password reset token: UserID=%v: %v", user.ID, err)
+		return c.JSON(response)
+	}
+
+	link := strings.TrimRight(ac.Config.App.FrontendURL, "/") + "/reset-password?token=" + url.QueryEscape(rawToken)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun Masjidku",
+		Body: fmt.Sprintf("Assalamu'alaikum %s,\n\n"+
+			"Kami menerima permintaan reset password untuk akun Anda. Buka link berikut untuk membuat password baru:\n\n%s\n\n"+
+			"Link ini hanya berlaku %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak meminta reset password.\n",
+			user.UserName, link, ac.Config.Auth.PasswordResetTTL),
+	}
+	if err := ac.Mailer.Send(c.UserContext(), msg); err != nil {
+		log.Printf("[ERROR] Failed to send password reset email: UserID=%v: %v", user.ID, err)
+		return c.JSON(response)
+	}
+
+	log.Printf("[SUCCESS] Password reset requested: UserID=%v", user.ID)
+	return c.JSON(response)
+}
+
+// 🔥 RESET PASSWORD - pakai token dari email (sekali pakai)
+func (ac *AuthController) ResetPassword(c *fiber.Ctx) error {
+	var input struct {
+		Token       string `json:"token" validate:"required"`
+		NewPassword string `json:"new_password" validate:"required,min=8"`
+	}
+
+	// 📌 Parsing & validasi JSON input
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
 	}
 
 	// 📌 Hashing password baru
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to hash new password"})
+		return apperror.Internal(fmt.Errorf("hash password baru: %w", err))
 	}
 
-	// 📌 Update password di database
-	if err := ac.DB.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update password"})
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		var reset modelAuth.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(input.Token), time.Now()).
+			First(&reset).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&reset).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		// 📌 Reset password lewat email sekaligus membuka akun yang terkunci karena login gagal.
+		// Access token dengan iat sebelum sessions_revoked_at ikut ditolak.
+		if err := tx.Model(&modelUser.UserModel{}).Where("id = ?", reset.UserID).
+			Updates(map[string]interface{}{"password": string(hashedPassword), "locked_until": nil, "sessions_revoked_at": now}).Error; err != nil {
+			return err
+		}
+
+		// 📌 Semua sesi lama dicabut: refresh token dan access token yang beredar (per sesi di revocation store)
+		if _, err := ac.Tokens.RevokeUserSessions(tx, reset.UserID, nil, modelAuth.RevokeReasonPasswordReset); err != nil {
+			return err
+		}
+
+		// Request tanpa login: actor adalah pemilik token reset
+		entry := audit.FromRequest(c)
+		entry.ActorID = &reset.UserID
+		entry.Action = audit.ActionPasswordReset
+		entry.TargetType = audit.TargetUser
+		entry.TargetID = reset.UserID.String()
+		return ac.Audit.RecordTx(tx, entry)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.BadRequest(apperror.CodeResetTokenInvalid)
+		}
+		return apperror.Internal(fmt.Errorf("reset password: %w", err))
 	}
 
 	// 📌 Response sukses reset password
@@ -97,3 +216,9 @@ func (ac *AuthController) ResetPassword(c *fiber.Ctx) error {
 		"message": "Password reset successfully",
 	})
 }
+
+// hashToken menyimpan token dalam bentuk SHA-256 agar kebocoran database tidak membocorkan token aktif
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
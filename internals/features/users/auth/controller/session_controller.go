@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"masjidku/internals/apperror"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	authMw "masjidku/internals/middlewares/auth"
+)
+
+// SessionResponse adalah satu sesi login (satu family refresh token / satu perangkat)
+type SessionResponse struct {
+	ID           uuid.UUID `json:"id"`
+	UserAgent    string    `json:"user_agent"`
+	IPAddress    string    `json:"ip_address"`
+	MFA          bool      `json:"mfa"`
+	StartedAt    time.Time `json:"started_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Current      bool      `json:"current"`
+}
+
+// 🔥 LIST SESSIONS - GET /api/auth/sessions
+func (ac *AuthController) GetSessions(c *fiber.Ctx) error {
+	principal, ok := authMw.PrincipalFrom(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	userID, currentID := principal.UserID, principal.SessionID
+
+	// Token aktif dalam family adalah hasil rotasi terakhir: created_at-nya = waktu aktivitas terakhir
+	var sessions []SessionResponse
+	err := ac.DB.Model(&modelAuth.RefreshToken{}).
+		Select(`family_id AS id, user_agent, ip_address, mfa, expires_at,
+			created_at AS last_active_at,
+			(SELECT MIN(f.created_at) FROM refresh_tokens f WHERE f.family_id = refresh_tokens.family_id) AS started_at`).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Scan(&sessions).Error
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("ambil sesi: %w", err))
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return c.JSON(fiber.Map{"data": sessions})
+}
+
+// 🔥 REVOKE SESSION - DELETE /api/auth/sessions/:id
+func (ac *AuthController) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidID)
+	}
+
+	affected, err := ac.Tokens.RevokeFamily(ac.DB, userID, sessionID, modelAuth.RevokeReasonUserRevoked)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cabut sesi: %w", err))
+	}
+	if affected == 0 {
+		return apperror.NotFound(apperror.CodeSessionNotFound)
+	}
+
+	if principal, _ := authMw.PrincipalFrom(c); principal.SessionID == sessionID {
+		ac.setRefreshCookie(c, "", time.Now().Add(-time.Hour))
+	}
+	return c.JSON(fiber.Map{"message": "Session revoked successfully"})
+}
+
+// 🔥 LOGOUT OTHER DEVICES - POST /api/auth/sessions/revoke-others
+func (ac *AuthController) RevokeOtherSessions(c *fiber.Ctx) error {
+	principal, ok := authMw.PrincipalFrom(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	userID, current := principal.UserID, principal.SessionID
+	if !principal.HasSession() {
+		return apperror.BadRequest(apperror.CodeSessionUnknown)
+	}
+
+	affected, err := ac.Tokens.RevokeUserSessions(ac.DB, userID, &current, modelAuth.RevokeReasonUserRevoked)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cabut sesi lain: %w", err))
+	}
+
+	log.Printf("[SUCCESS] Revoked %d other session token(s) for UserID=%v", affected, userID)
+	return c.JSON(fiber.Map{"message": "Logged out from all other devices"})
+}
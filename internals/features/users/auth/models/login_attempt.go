@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alasan kegagalan login yang dicatat di login_attempts
+const (
+	LoginFailInvalidCredentials = "invalid_credentials"
+	LoginFailLocked             = "locked"
+	LoginFailThrottled          = "throttled"
+)
+
+// LoginAttempt adalah riwayat percobaan login dengan password. Kegagalan sejak login sukses
+// terakhir (di dalam window) dipakai untuk delay progresif dan penguncian akun sementara.
+type LoginAttempt struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID        *uuid.UUID `gorm:"type:uuid;index"` // nil jika identifier tidak cocok dengan user mana pun
+	Identifier    string     `gorm:"size:255;not null;index"`
+	IPAddress     string     `gorm:"size:45"`
+	UserAgent     string     `gorm:"size:255"`
+	Success       bool       `gorm:"not null"`
+	FailureReason string     `gorm:"size:30"`
+	CreatedAt     time.Time
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthLoginCode adalah kode sekali pakai yang dikirim ke frontend lewat redirect setelah
+// login OAuth berhasil. Frontend menukarnya dengan token lewat POST /auth/google/exchange,
+// sehingga JWT tidak pernah muncul di URL maupun history browser.
+type OAuthLoginCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	CodeHash  string    `gorm:"size:64;not null;unique"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (OAuthLoginCode) TableName() string {
+	return "oauth_login_codes"
+}
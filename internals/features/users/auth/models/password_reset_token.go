@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken menyimpan hash token reset password (token asli hanya dikirim lewat email)
+type PasswordResetToken struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	TokenHash   string    `gorm:"size:64;not null;unique"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	UsedAt      *time.Time
+	RequestedIP string `gorm:"size:45"`
+	CreatedAt   time.Time
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
@@ -6,11 +6,31 @@ import (
 	"github.com/google/uuid"
 )
 
+// Alasan pencabutan refresh token (kolom revoked_reason)
+const (
+	RevokeReasonRotated       = "rotated"        // sudah ditukar dengan token baru
+	RevokeReasonReuseDetected = "reuse_detected" // token lama dipakai ulang, seluruh family dicabut
+	RevokeReasonLogout        = "logout"
+	RevokeReasonUserRevoked   = "user_revoked" // dicabut user dari daftar sesi
+	RevokeReasonPasswordReset = "password_reset"
+	RevokeReasonAccountDelete = "account_deleted"
+	RevokeReasonSuspended     = "suspended" // akun disuspend / di-ban admin
+)
+
+// RefreshToken adalah satu token dalam rantai rotasi. Semua token hasil rotasi dari
+// satu login berbagi FamilyID (= satu sesi / perangkat); ParentID menunjuk token sebelumnya.
 type RefreshToken struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null"`
-	Token     string    `gorm:"text;not null;unique"`
-	ExpiresAt time.Time `gorm:"not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null"`
+	Token         string     `gorm:"text;not null;unique"`
+	FamilyID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ParentID      *uuid.UUID `gorm:"type:uuid"`
+	UserAgent     string     `gorm:"size:255"`
+	IPAddress     string     `gorm:"size:45"`
+	MFA           bool       `gorm:"not null;default:false"`
+	ExpiresAt     time.Time  `gorm:"not null"`
+	RevokedAt     *time.Time
+	RevokedReason string `gorm:"size:30"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RevokedToken adalah entri pencabutan token, dipakai oleh revocation.DBStore.
+// Key berisi jti access token, atau "sid:<family_id>" untuk seluruh sesi.
+// Baris boleh dihapus setelah ExpiresAt karena token-nya sudah tidak berlaku.
+type RevokedToken struct {
+	Key       string    `gorm:"size:100;primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
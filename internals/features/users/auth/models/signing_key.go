@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// SigningKey adalah kunci asimetris untuk menandatangani access token (header kid = KID).
+// Kunci aktif (RetiredAt nil) dipakai untuk sign; kunci yang dipensiunkan hanya dipakai
+// untuk verifikasi sampai VerifyUntil, lalu boleh dihapus (`masjidku keys prune`).
+type SigningKey struct {
+	KID           string     `gorm:"column:kid;size:64;primaryKey" json:"kid"`
+	Algorithm     string     `gorm:"size:10;not null" json:"algorithm"` // RS256 | EdDSA
+	PublicKeyPEM  string     `gorm:"column:public_key_pem;type:text;not null" json:"-"`
+	PrivateKeyEnc string     `gorm:"column:private_key_enc;type:text;not null" json:"-"` // PKCS#8 PEM, dienkripsi SecretBox
+	CreatedAt     time.Time  `json:"created_at"`
+	RetiredAt     *time.Time `json:"retired_at,omitempty"`
+	VerifyUntil   *time.Time `json:"verify_until,omitempty"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (SigningKey) TableName() string {
+	return "jwt_signing_keys"
+}
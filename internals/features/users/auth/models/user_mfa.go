@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA menyimpan secret TOTP user (terenkripsi). EnabledAt nil berarti enrollment
+// sudah dimulai tetapi belum dikonfirmasi dengan kode pertama.
+type UserMFA struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	SecretEnc    string    `gorm:"type:text;not null"`
+	EnabledAt    *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"` // mencegah kode yang sama dipakai dua kali
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode menyimpan hash kode pemulihan sekali pakai
+type MFARecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	CodeHash  string    `gorm:"size:64;not null;unique"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (MFARecoveryCode) TableName() string {
+	return "user_mfa_recovery_codes"
+}
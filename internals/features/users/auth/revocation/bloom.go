@@ -0,0 +1,65 @@
+package revocation
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// bloomFilter menjawab "pasti tidak ada" tanpa menyentuh backend. Entri tidak bisa dihapus,
+// jadi filter dibangun ulang secara berkala dari key yang masih aktif.
+type bloomFilter struct {
+	mu   sync.RWMutex
+	bits []uint64
+	m    uint64 // jumlah bit
+	k    uint64 // jumlah fungsi hash
+}
+
+// newBloomFilter membuat filter untuk n elemen dengan peluang false positive p
+func newBloomFilter(n int, p float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// hashes memakai double hashing (Kirsch–Mitzenmacher) dari FNV-1a dan FNV-1
+func (b *bloomFilter) hashes(key string) (uint64, uint64) {
+	h1 := fnv.New64a()
+	h1.Write([]byte(key))
+	h2 := fnv.New64()
+	h2.Write([]byte(key))
+	return h1.Sum64(), h2.Sum64() | 1
+}
+
+func (b *bloomFilter) Add(key string) {
+	h1, h2 := b.hashes(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := uint64(0); i < b.k; i++ {
+		idx := (h1 + i*h2) % b.m
+		b.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// Test mengembalikan false jika key pasti belum pernah ditambahkan
+func (b *bloomFilter) Test(key string) bool {
+	h1, h2 := b.hashes(key)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for i := uint64(0); i < b.k; i++ {
+		idx := (h1 + i*h2) % b.m
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package revocation
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// negativeTTL membatasi berapa lama hasil "tidak dicabut" dipercaya tanpa bertanya ke backend
+	// (jaga-jaga jika notifikasi dari replika lain terlewat)
+	negativeTTL = 30 * time.Second
+	// positiveTTL cukup panjang karena token yang sudah expired toh ditolak saat verifikasi JWT
+	positiveTTL = 24 * time.Hour
+)
+
+// CachedStore membungkus backend dengan bloom filter (jawaban negatif tanpa I/O untuk hampir
+// semua request) dan LRU untuk hasil lookup backend. Pencabutan dari replika lain diterima
+// lewat PGNotifier sehingga bloom dan LRU lokal ikut ter-update.
+//
+// Bloom hanya berisi pencabutan yang terlihat oleh replika ini, jadi jawaban negatifnya
+// baru dipercaya (TrustBloom) selama listener notifikasi tersambung. Tanpa itu, key yang
+// tidak ada di bloom tetap dicek ke LRU / backend.
+type CachedStore struct {
+	backend  Store
+	notifier *PGNotifier
+	lru      *lruCache
+
+	bloomCapacity int
+	bloomFP       float64
+	bloomTrusted  atomic.Bool
+	bloomMu       sync.RWMutex
+	bloom         *bloomFilter // nil jika backend tidak mengimplementasikan Lister
+	rebuilds      int          // Rebuild yang sedang berjalan
+	pending       []string     // key yang dicabut selama Rebuild berjalan, ditambahkan lagi setelah swap
+}
+
+func NewCachedStore(backend Store, notifier *PGNotifier, cacheSize, bloomCapacity int, bloomFP float64) *CachedStore {
+	return &CachedStore{
+		backend:       backend,
+		notifier:      notifier,
+		lru:           newLRUCache(cacheSize),
+		bloomCapacity: bloomCapacity,
+		bloomFP:       bloomFP,
+	}
+}
+
+func (s *CachedStore) Revoke(ctx context.Context, key string, exp time.Time) error {
+	if err := s.backend.Revoke(ctx, key, exp); err != nil {
+		return err
+	}
+	s.applyRemote(key)
+	if s.notifier != nil {
+		if err := s.notifier.Publish(ctx, key); err != nil {
+			// Replika lain tetap akan melihatnya setelah negativeTTL / rebuild berikutnya
+			log.Printf("[WARNING] Gagal mengirim notifikasi pencabutan token: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *CachedStore) IsRevoked(ctx context.Context, key string) (bool, error) {
+	if s.bloomTrusted.Load() {
+		s.bloomMu.RLock()
+		bloom := s.bloom
+		s.bloomMu.RUnlock()
+		if bloom != nil && !bloom.Test(key) {
+			return false, nil
+		}
+	}
+
+	if revoked, ok := s.lru.Get(key); ok {
+		return revoked, nil
+	}
+
+	revoked, err := s.backend.IsRevoked(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		s.lru.Add(key, true, positiveTTL)
+	} else {
+		s.lru.Add(key, false, negativeTTL)
+	}
+	return revoked, nil
+}
+
+// Cleanup membersihkan backend lalu membangun ulang bloom filter tanpa key yang sudah kedaluwarsa
+func (s *CachedStore) Cleanup(ctx context.Context) (int64, error) {
+	n, err := s.backend.Cleanup(ctx)
+	if err != nil {
+		return n, err
+	}
+	return n, s.Rebuild(ctx)
+}
+
+// TrustBloom mengatur apakah jawaban negatif bloom boleh dipakai tanpa bertanya ke backend.
+// Hanya aktifkan selama semua pencabutan dari replika lain pasti sampai (listener tersambung).
+func (s *CachedStore) TrustBloom(trusted bool) {
+	s.bloomTrusted.Store(trusted)
+}
+
+// Rebuild mengisi ulang bloom filter dari key aktif di backend. Key yang dicabut selama
+// ActiveKeys berjalan belum tentu ada di hasilnya, jadi dicatat dan ditambahkan setelah swap.
+func (s *CachedStore) Rebuild(ctx context.Context) error {
+	lister, ok := s.backend.(Lister)
+	if !ok {
+		return nil
+	}
+
+	s.bloomMu.Lock()
+	s.rebuilds++
+	s.bloomMu.Unlock()
+	defer func() {
+		s.bloomMu.Lock()
+		if s.rebuilds--; s.rebuilds == 0 {
+			s.pending = nil
+		}
+		s.bloomMu.Unlock()
+	}()
+
+	keys, err := lister.ActiveKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	capacity := s.bloomCapacity
+	if len(keys)*2 > capacity {
+		capacity = len(keys) * 2
+	}
+	bloom := newBloomFilter(capacity, s.bloomFP)
+	for _, key := range keys {
+		bloom.Add(key)
+	}
+
+	s.bloomMu.Lock()
+	for _, key := range s.pending {
+		bloom.Add(key)
+	}
+	s.bloom = bloom
+	s.bloomMu.Unlock()
+	s.lru.PurgeNegative()
+	return nil
+}
+
+// applyRemote mencatat pencabutan (lokal atau dari replika lain) ke bloom dan LRU
+func (s *CachedStore) applyRemote(key string) {
+	s.bloomMu.Lock()
+	if s.bloom != nil {
+		s.bloom.Add(key)
+	}
+	if s.rebuilds > 0 {
+		s.pending = append(s.pending, key)
+	}
+	s.bloomMu.Unlock()
+	s.lru.Add(key, true, positiveTTL)
+}
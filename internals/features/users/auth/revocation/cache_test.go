@@ -0,0 +1,153 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memBackend adalah backend Store + Lister di memori. onList dipanggil setelah ActiveKeys
+// mengambil snapshot, untuk menyisipkan pencabutan di tengah Rebuild.
+type memBackend struct {
+	mu      sync.Mutex
+	keys    map[string]time.Time
+	lookups int
+	onList  func()
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{keys: map[string]time.Time{}}
+}
+
+func (b *memBackend) Revoke(ctx context.Context, key string, exp time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.keys[key] = exp
+	return nil
+}
+
+func (b *memBackend) IsRevoked(ctx context.Context, key string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lookups++
+	exp, ok := b.keys[key]
+	return ok && exp.After(time.Now()), nil
+}
+
+func (b *memBackend) Cleanup(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (b *memBackend) ActiveKeys(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	keys := make([]string, 0, len(b.keys))
+	for key := range b.keys {
+		keys = append(keys, key)
+	}
+	onList := b.onList
+	b.mu.Unlock()
+	if onList != nil {
+		onList()
+	}
+	return keys, nil
+}
+
+func (b *memBackend) lookupCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lookups
+}
+
+func TestCachedStoreBloomNotTrusted(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemBackend()
+	s := NewCachedStore(backend, nil, 100, 100, 0.01)
+	if err := s.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	// Dicabut oleh replika lain tanpa notifikasi: tidak ada di bloom replika ini
+	backend.Revoke(ctx, "jti-remote", time.Now().Add(time.Hour))
+	revoked, err := s.IsRevoked(ctx, "jti-remote")
+	if err != nil || !revoked {
+		t.Fatalf("IsRevoked = %v, %v; bloom negatif tidak boleh dipercaya tanpa notifikasi", revoked, err)
+	}
+
+	// Hasil negatif tetap di-cache LRU, bukan lookup backend di setiap request
+	s.IsRevoked(ctx, "jti-aktif")
+	s.IsRevoked(ctx, "jti-aktif")
+	if n := backend.lookupCount(); n != 2 {
+		t.Errorf("lookup backend = %d, want 2", n)
+	}
+}
+
+func TestCachedStoreBloomTrusted(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemBackend()
+	backend.Revoke(ctx, "jti-lama", time.Now().Add(time.Hour))
+	s := NewCachedStore(backend, nil, 100, 100, 0.01)
+	if err := s.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	s.TrustBloom(true)
+
+	if revoked, _ := s.IsRevoked(ctx, "jti-aktif"); revoked {
+		t.Error("jti-aktif tidak dicabut")
+	}
+	if n := backend.lookupCount(); n != 0 {
+		t.Errorf("bloom negatif yang dipercaya tidak boleh lookup backend, lookup = %d", n)
+	}
+	if revoked, _ := s.IsRevoked(ctx, "jti-lama"); !revoked {
+		t.Error("jti-lama harus dicabut")
+	}
+
+	// Listener putus: notifikasi bisa terlewat, jadi backend ditanya lagi
+	s.TrustBloom(false)
+	backend.Revoke(ctx, "jti-terlewat", time.Now().Add(time.Hour))
+	if revoked, _ := s.IsRevoked(ctx, "jti-terlewat"); !revoked {
+		t.Error("jti-terlewat harus dicabut setelah listener putus")
+	}
+}
+
+// TestCachedStoreRebuildInterleaving: pencabutan yang masuk antara ActiveKeys dan swap
+// bloom tidak boleh hilang dari bloom yang baru
+func TestCachedStoreRebuildInterleaving(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemBackend()
+	s := NewCachedStore(backend, nil, 100, 100, 0.01)
+	if err := s.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	s.TrustBloom(true)
+
+	backend.onList = func() {
+		// Lokal lewat Revoke dan dari replika lain lewat notifikasi
+		if err := s.Revoke(ctx, "jti-lokal", time.Now().Add(time.Hour)); err != nil {
+			t.Errorf("Revoke: %v", err)
+		}
+		backend.Revoke(ctx, "jti-remote", time.Now().Add(time.Hour))
+		s.applyRemote("jti-remote")
+	}
+	if err := s.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	backend.onList = nil
+
+	for _, key := range []string{"jti-lokal", "jti-remote"} {
+		if !s.bloom.Test(key) {
+			t.Errorf("%s hilang dari bloom setelah Rebuild", key)
+		}
+		if revoked, _ := s.IsRevoked(ctx, key); !revoked {
+			t.Errorf("%s harus dicabut", key)
+		}
+	}
+
+	// Pending hanya berlaku selama Rebuild berjalan
+	if err := s.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if len(s.pending) != 0 || s.rebuilds != 0 {
+		t.Errorf("pending = %v, rebuilds = %d setelah Rebuild selesai", s.pending, s.rebuilds)
+	}
+}
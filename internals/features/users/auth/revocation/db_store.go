@@ -0,0 +1,49 @@
+package revocation
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"masjidku/internals/features/users/auth/models"
+)
+
+// DBStore menyimpan pencabutan di tabel revoked_tokens
+type DBStore struct {
+	db *gorm.DB
+}
+
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+func (s *DBStore) Revoke(ctx context.Context, key string, exp time.Time) error {
+	row := models.RevokedToken{Key: key, ExpiresAt: exp}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+	}).Create(&row).Error
+}
+
+func (s *DBStore) IsRevoked(ctx context.Context, key string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.RevokedToken{}).
+		Where("key = ? AND expires_at > ?", key, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s *DBStore) Cleanup(ctx context.Context) (int64, error) {
+	res := s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{})
+	return res.RowsAffected, res.Error
+}
+
+func (s *DBStore) ActiveKeys(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := s.db.WithContext(ctx).Model(&models.RevokedToken{}).
+		Where("expires_at > ?", time.Now()).
+		Pluck("key", &keys).Error
+	return keys, err
+}
@@ -0,0 +1,75 @@
+package revocation
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruCache menyimpan hasil IsRevoked terakhir (positif maupun negatif) dengan batas jumlah entri
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	revoked   bool
+	expiresAt time.Time
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *lruCache) Get(key string) (revoked bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return false, false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return false, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.revoked, true
+}
+
+func (c *lruCache) Add(key string, revoked bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.revoked, entry.expiresAt = revoked, expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, revoked: revoked, expiresAt: expiresAt})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// PurgeNegative menghapus semua hasil "tidak dicabut", dipakai setelah notifikasi mungkin terlewat
+func (c *lruCache) PurgeNegative() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.items {
+		if !el.Value.(*lruEntry).revoked {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package revocation
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// NotifyChannel adalah channel Postgres untuk menyebarkan pencabutan token antar replika
+const NotifyChannel = "masjidku_token_revoked"
+
+// PGNotifier mengirim dan menerima pencabutan token lewat Postgres LISTEN/NOTIFY.
+// Listen memakai koneksi pgx tersendiri karena LISTEN tidak bekerja lewat pooler mode transaksi.
+type PGNotifier struct {
+	db        *gorm.DB
+	listenURL string
+}
+
+func NewPGNotifier(db *gorm.DB, listenURL string) *PGNotifier {
+	return &PGNotifier{db: db, listenURL: listenURL}
+}
+
+// Publish mengirim key yang dicabut ke semua replika (termasuk dirinya sendiri)
+func (n *PGNotifier) Publish(ctx context.Context, key string) error {
+	return n.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", NotifyChannel, key).Error
+}
+
+// Listen menerima notifikasi sampai ctx dibatalkan dan otomatis menyambung ulang.
+// onConnect dipanggil setiap kali LISTEN aktif (termasuk setelah reconnect), onDisconnect
+// setiap kali koneksi listener putus (notifikasi berikutnya bisa terlewat sampai onConnect).
+func (n *PGNotifier) Listen(ctx context.Context, onKey func(key string), onConnect, onDisconnect func()) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := n.listenOnce(ctx, onKey, func() {
+			backoff = time.Second
+			onConnect()
+		})
+		onDisconnect()
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("[WARNING] Listener pencabutan token terputus: %v (coba lagi dalam %s)", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+func (n *PGNotifier) listenOnce(ctx context.Context, onKey func(key string), onConnect func()) error {
+	conn, err := pgx.Connect(ctx, n.listenURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{NotifyChannel}.Sanitize()); err != nil {
+		return err
+	}
+	log.Println("[INFO] Listening pencabutan token di channel", NotifyChannel)
+	onConnect()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		onKey(notification.Payload)
+	}
+}
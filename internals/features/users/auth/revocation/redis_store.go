@@ -0,0 +1,51 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "masjidku:revoked:"
+
+// RedisStore menyimpan pencabutan sebagai key Redis dengan TTL = sisa umur token,
+// sehingga Cleanup tidak perlu melakukan apa pun
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("revocation: REDIS_URL tidak valid: %w", err)
+	}
+	return &RedisStore{client: redis.NewClient(opts)}, nil
+}
+
+func (s *RedisStore) Revoke(ctx context.Context, key string, exp time.Time) error {
+	ttl := time.Until(exp)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, redisKeyPrefix+key, 1, ttl).Err()
+}
+
+func (s *RedisStore) IsRevoked(ctx context.Context, key string) (bool, error) {
+	n, err := s.client.Exists(ctx, redisKeyPrefix+key).Result()
+	return n > 0, err
+}
+
+func (s *RedisStore) Cleanup(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (s *RedisStore) ActiveKeys(ctx context.Context) ([]string, error) {
+	var keys []string
+	iter := s.client.Scan(ctx, 0, redisKeyPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val()[len(redisKeyPrefix):])
+	}
+	return keys, iter.Err()
+}
@@ -0,0 +1,84 @@
+// Package revocation menyimpan daftar token yang dicabut sebelum exp-nya habis
+// (logout, sesi dicabut, reuse refresh token). Key berupa jti access token atau
+// "sid:<family_id>" untuk seluruh sesi, dan setiap entri kedaluwarsa bersama token-nya.
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"masjidku/internals/configs"
+)
+
+// Store adalah penyimpanan pencabutan token
+type Store interface {
+	// Revoke menandai key sebagai dicabut sampai exp
+	Revoke(ctx context.Context, key string, exp time.Time) error
+	// IsRevoked mengecek apakah key sedang dicabut
+	IsRevoked(ctx context.Context, key string) (bool, error)
+	// Cleanup menghapus entri yang sudah kedaluwarsa
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+// Lister diimplementasikan backend yang bisa mendaftar key aktif,
+// dipakai untuk mengisi ulang bloom filter di CachedStore
+type Lister interface {
+	ActiveKeys(ctx context.Context) ([]string, error)
+}
+
+// SessionKey adalah key pencabutan untuk seluruh sesi (family refresh token)
+func SessionKey(familyID string) string {
+	return "sid:" + familyID
+}
+
+// New membangun Store sesuai konfigurasi: backend db/redis yang dibungkus cache LRU + bloom filter,
+// dan jika Notify aktif, sinkronisasi antar replika lewat Postgres LISTEN/NOTIFY.
+// Listener berjalan sampai ctx dibatalkan.
+func New(ctx context.Context, db *gorm.DB, cfg *configs.Config) (Store, error) {
+	var backend Store
+	switch cfg.Revocation.Driver {
+	case "db":
+		backend = NewDBStore(db)
+	case "redis":
+		rs, err := NewRedisStore(cfg.Redis.URL)
+		if err != nil {
+			return nil, err
+		}
+		backend = rs
+	default:
+		return nil, fmt.Errorf("revocation: driver tidak dikenal %q", cfg.Revocation.Driver)
+	}
+
+	var notifier *PGNotifier
+	if cfg.Revocation.Notify {
+		listenURL := cfg.Revocation.ListenURL
+		if listenURL == "" {
+			listenURL = cfg.Database.URL
+		}
+		notifier = NewPGNotifier(db, listenURL)
+	}
+
+	cached := NewCachedStore(backend, notifier, cfg.Revocation.CacheSize,
+		cfg.Revocation.BloomCapacity, cfg.Revocation.BloomFalsePositive)
+	if err := cached.Rebuild(ctx); err != nil {
+		log.Printf("[WARNING] Gagal memuat token yang dicabut ke bloom filter: %v", err)
+	}
+	if notifier != nil {
+		go notifier.Listen(ctx, cached.applyRemote, func() {
+			// Notifikasi bisa terlewat selama koneksi putus, jadi bloom dibangun ulang
+			// dan baru dipercaya lagi setelah berisi semua key aktif
+			if err := cached.Rebuild(ctx); err != nil {
+				log.Printf("[WARNING] Gagal membangun ulang bloom filter: %v", err)
+				return
+			}
+			cached.TrustBloom(true)
+		}, func() {
+			cached.TrustBloom(false)
+		})
+	}
+	return cached, nil
+}
@@ -1,30 +1,118 @@
 package route
 
 import (
+	"time"
+
+	"masjidku/internals/audit"
+	"masjidku/internals/configs"
+	"masjidku/internals/constants"
 	controller "masjidku/internals/features/users/auth/controller"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	userService "masjidku/internals/features/users/user/service"
+	"masjidku/internals/mailer"
 	authMw "masjidku/internals/middlewares/auth"
+	"masjidku/internals/ratelimit"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-func AuthRoutes(app *fiber.App, db *gorm.DB) {
-	authController := controller.NewAuthController(db)
-	googleAuthController := controller.NewGoogleAuthController(db)
+func AuthRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy, mail mailer.Mailer, limiter *ratelimit.Limiter, recorder *audit.Recorder) {
+	guard := authService.NewLoginGuard(db, cfg, limiter)
+	deletion := userService.NewAccountDeletion(db, tokens, recorder, cfg.Auth.AccountDeletionGrace)
+	authController := controller.NewAuthController(db, cfg, mail, tokens, guard, recorder, deletion)
+	guard.OnLockout(authController.NotifyAccountLocked)
+	guard.OnLockout(authController.AuditAccountLocked)
+	googleAuthController := controller.NewGoogleAuthController(db, cfg, recorder)
+	impersonationController := controller.NewImpersonationController(db, tokens, policy, recorder, cfg.Auth.ImpersonationTTL)
+
+	// Public key access token (RS256/EdDSA) untuk verifier lain
+	app.Get("/.well-known/jwks.json", authController.JWKS)
 
 	auth := app.Group("/auth")
-	auth.Post("/register", authController.Register)
-	auth.Post("/login", authController.Login)
-	auth.Post("/refresh-token", authController.RefreshToken)
-	auth.Post("/forgot-password/check", authController.CheckSecurityAnswer)
-	auth.Post("/forgot-password/reset", authController.ResetPassword)
+	// 🔒 Rate limit per IP untuk endpoint sensitif; batas per akun & lockout login ada di LoginGuard
+	rl := cfg.RateLimit
+	passwordResetLimit := limiter.Middleware(ratelimit.MiddlewareConfig{
+		Name: "password-reset:ip",
+		Rule: ratelimit.Rule{Limit: rl.PasswordResetPerIP, Window: rl.PasswordResetWindow},
+	})
+
+	auth.Post("/register", limiter.Middleware(ratelimit.MiddlewareConfig{
+		Name: "register:ip",
+		Rule: ratelimit.Rule{Limit: rl.RegisterPerIP, Window: rl.RegisterWindow},
+	}), authController.Register)
+	auth.Post("/login", limiter.Middleware(ratelimit.MiddlewareConfig{
+		Name:    "login:ip",
+		Rule:    ratelimit.Rule{Limit: rl.LoginPerIP, Window: rl.LoginWindow},
+		Message: "Terlalu banyak percobaan login, coba lagi nanti",
+	}), authController.Login)
+	auth.Post("/login/mfa", append(loginMFALimits(limiter, cfg), authController.LoginMFA)...)
+	// Pulihkan akun yang dihapus sendiri (masih dalam masa tenggang); dibatasi seperti login
+	auth.Post("/restore-account", limiter.Middleware(ratelimit.MiddlewareConfig{
+		Name:    "restore-account:ip",
+		Rule:    ratelimit.Rule{Limit: rl.LoginPerIP, Window: rl.LoginWindow},
+		Message: "Terlalu banyak percobaan login, coba lagi nanti",
+	}), authController.RestoreAccount)
+	auth.Post("/refresh-token", limiter.Middleware(ratelimit.MiddlewareConfig{
+		Name: "refresh:ip",
+		Rule: ratelimit.Rule{Limit: rl.RefreshPerIP, Window: rl.RefreshWindow},
+	}), authController.RefreshToken)
+	auth.Post("/forgot-password", passwordResetLimit, authController.ForgotPassword)
+	auth.Post("/reset-password", passwordResetLimit, authController.ResetPassword)
+	auth.Get("/verify-email", authController.VerifyEmail)
+	auth.Post("/verify-email/resend", limiter.Middleware(ratelimit.MiddlewareConfig{
+		Name: "verify-email-resend:ip-email",
+		Rule: ratelimit.Rule{Limit: cfg.Auth.EmailVerifyResendMax, Window: 15 * time.Minute},
+		Key:  ratelimit.Join(ratelimit.ByIP, ratelimit.ByBodyField("email")),
+	}), authController.ResendVerificationEmail)
 
 	// Protected routes
-	protectedRoutes := app.Group("/api/auth", authMw.AuthMiddleware(db))
+	protectedRoutes := app.Group("/api/auth", authMw.AuthMiddleware(db, tokens, policy))
+	// 🎭 Aksi sensitif hanya untuk pemilik akun, tidak untuk admin yang login sebagai user
+	ownerOnly := authMw.DenyImpersonation()
 	protectedRoutes.Post("/logout", authController.Logout)
-	protectedRoutes.Post("/change-password", authController.ChangePassword)
+	protectedRoutes.Post("/change-password", ownerOnly, authController.ChangePassword)
+
+	// Sesi login per perangkat
+	protectedRoutes.Get("/sessions", authController.GetSessions)
+	protectedRoutes.Post("/sessions/revoke-others", ownerOnly, authController.RevokeOtherSessions)
+	protectedRoutes.Delete("/sessions/:id", ownerOnly, authController.RevokeSession)
+
+	// Two-factor authentication (TOTP)
+	protectedRoutes.Get("/mfa", authController.GetMFAStatus)
+	protectedRoutes.Post("/mfa/setup", ownerOnly, authController.SetupMFA)
+	protectedRoutes.Post("/mfa/enable", ownerOnly, authController.EnableMFA)
+	protectedRoutes.Post("/mfa/disable", ownerOnly, authController.DisableMFA)
+	protectedRoutes.Post("/mfa/recovery-codes", ownerOnly, authController.RegenerateRecoveryCodes)
+
+	// Impersonation ("login sebagai") untuk admin platform; diakhiri dengan token impersonation itu sendiri
+	protectedRoutes.Post("/impersonate", ownerOnly,
+		authMw.RequirePermission(constants.PermUsersImpersonate), impersonationController.Start)
+	protectedRoutes.Post("/impersonate/end", impersonationController.End)
 
 	// Google auth
 	auth.Get("/google", googleAuthController.GoogleLogin)
 	auth.Get("/google/callback", googleAuthController.GoogleCallback)
+	auth.Post("/google/exchange", authController.ExchangeGoogleCode)
+}
+
+// loginMFALimits membatasi /auth/login/mfa per IP dan per challenge token, sehingga kode
+// 6 digit tidak bisa di-brute force. Request tanpa mfa_token ditolak, bukan dilewatkan.
+func loginMFALimits(limiter *ratelimit.Limiter, cfg *configs.Config) []fiber.Handler {
+	rl := cfg.RateLimit
+	return []fiber.Handler{
+		limiter.Middleware(ratelimit.MiddlewareConfig{
+			Name:    "login-mfa:ip",
+			Rule:    ratelimit.Rule{Limit: rl.LoginPerIP, Window: rl.LoginWindow},
+			Message: "Terlalu banyak percobaan login, coba lagi nanti",
+		}),
+		limiter.Middleware(ratelimit.MiddlewareConfig{
+			Name:     "login-mfa:token",
+			Rule:     ratelimit.Rule{Limit: cfg.Auth.MFAMaxAttempts, Window: cfg.Auth.MFAChallengeTTL},
+			Key:      ratelimit.ByBodyField("mfa_token"),
+			Required: true,
+			Message:  "Terlalu banyak percobaan, silakan login ulang",
+		}),
+	}
 }
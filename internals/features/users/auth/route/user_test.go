@@ -0,0 +1,98 @@
+package route
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/configs"
+	"masjidku/internals/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestLoginMFALimits: batas per challenge token harus berlaku untuk semua Content-Type yang
+// diterima BodyParser di LoginMFA, dan request tanpa token tidak boleh lolos tanpa batas
+func TestLoginMFALimits(t *testing.T) {
+	multipartBody := func(token string) (string, string) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		_ = w.WriteField("mfa_token", token)
+		_ = w.WriteField("code", "000000")
+		_ = w.Close()
+		return buf.String(), w.FormDataContentType()
+	}
+	multipartToken, multipartType := multipartBody("token-multipart")
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", fiber.MIMEApplicationJSON, `{"mfa_token":"token-json","code":"000000"}`},
+		{"json key huruf besar", fiber.MIMEApplicationJSON, `{"MFA_TOKEN":"token-upper","code":"000000"}`},
+		{"form", fiber.MIMEApplicationForm, "mfa_token=token-form&code=000000"},
+		{"multipart", multipartType, multipartToken},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newLoginMFAApp(3, 100)
+			for i := 1; i <= 4; i++ {
+				want := fiber.StatusUnauthorized
+				if i == 4 {
+					want = fiber.StatusTooManyRequests
+				}
+				if got := postLoginMFA(t, app, tc.contentType, tc.body); got != want {
+					t.Fatalf("percobaan %d: status = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("tanpa mfa_token", func(t *testing.T) {
+		app := newLoginMFAApp(3, 100)
+		for _, body := range []string{"code=000000", "MFAToken=token-lain&code=000000"} {
+			if got := postLoginMFA(t, app, fiber.MIMEApplicationForm, body); got != fiber.StatusBadRequest {
+				t.Errorf("%q: status = %d, want 400", body, got)
+			}
+		}
+	})
+
+	t.Run("per IP", func(t *testing.T) {
+		app := newLoginMFAApp(100, 2)
+		for i, want := range []int{fiber.StatusUnauthorized, fiber.StatusUnauthorized, fiber.StatusTooManyRequests} {
+			body := "mfa_token=token-" + string(rune('a'+i)) + "&code=000000"
+			if got := postLoginMFA(t, app, fiber.MIMEApplicationForm, body); got != want {
+				t.Fatalf("percobaan %d: status = %d, want %d", i+1, got, want)
+			}
+		}
+	})
+}
+
+// newLoginMFAApp memasang loginMFALimits di depan handler yang selalu menolak kode
+func newLoginMFAApp(perToken, perIP int) *fiber.App {
+	cfg := configs.Defaults()
+	cfg.Auth.MFAMaxAttempts = perToken
+	cfg.RateLimit.LoginPerIP = perIP
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.ErrorHandler})
+	app.Post("/auth/login/mfa", append(loginMFALimits(limiter, cfg), func(c *fiber.Ctx) error {
+		return apperror.Unauthorized(apperror.CodeMFACodeInvalid)
+	})...)
+	return app
+}
+
+func postLoginMFA(t *testing.T, app *fiber.App, contentType, body string) int {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/auth/login/mfa", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	return resp.StatusCode
+}
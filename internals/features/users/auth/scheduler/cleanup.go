@@ -1,28 +1,84 @@
 package scheduler
 
 import (
+	"context"
 	"log"
 	"time"
 
-	"masjidku/internals/features/users/auth/models"
-
 	"gorm.io/gorm"
+
+	modelAuth "masjidku/internals/features/users/auth/models"
+	"masjidku/internals/features/users/auth/revocation"
+	userService "masjidku/internals/features/users/user/service"
+	"masjidku/internals/storage"
 )
 
-func StartBlacklistCleanupScheduler(db *gorm.DB) {
+// StartBlacklistCleanupScheduler menghapus entri token yang dicabut dan sudah kedaluwarsa
+// dari revocation store secara berkala (sekaligus membangun ulang bloom filter-nya)
+func StartBlacklistCleanupScheduler(store revocation.Store, interval time.Duration) {
 	go func() {
 		for {
-			log.Println("[CLEANUP] Menjalankan pembersihan token_blacklist...")
-			result := db.Where("expired_at < ?", time.Now()).Delete(&models.TokenBlacklist{})
-			if result.Error != nil {
-				log.Printf("[CLEANUP ERROR] %v", result.Error)
-			} else if result.RowsAffected > 0 {
-				log.Printf("[CLEANUP] %d token kadaluarsa dihapus", result.RowsAffected)
+			log.Println("[CLEANUP] Menjalankan pembersihan token yang dicabut...")
+			removed, err := store.Cleanup(context.Background())
+			if err != nil {
+				log.Printf("[CLEANUP ERROR] %v", err)
+			} else if removed > 0 {
+				log.Printf("[CLEANUP] %d token kadaluarsa dihapus", removed)
 			} else {
 				log.Println("[CLEANUP] Tidak ada token kadaluarsa ditemukan")
 			}
 
-			time.Sleep(24 * time.Hour)
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// StartLoginAttemptsCleanupScheduler menghapus riwayat login_attempts yang lebih tua dari retention
+func StartLoginAttemptsCleanupScheduler(db *gorm.DB, retention, interval time.Duration) {
+	go func() {
+		for {
+			res := db.Where("created_at < ?", time.Now().Add(-retention)).Delete(&modelAuth.LoginAttempt{})
+			if res.Error != nil {
+				log.Printf("[CLEANUP ERROR] login_attempts: %v", res.Error)
+			} else if res.RowsAffected > 0 {
+				log.Printf("[CLEANUP] %d riwayat login lama dihapus", res.RowsAffected)
+			}
+
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// StartAccountPurgeScheduler menghapus permanen akun yang masa tenggang penghapusannya sudah lewat
+func StartAccountPurgeScheduler(deletion *userService.AccountDeletion, interval time.Duration) {
+	go func() {
+		for {
+			purged, err := deletion.Purge(context.Background())
+			if err != nil {
+				log.Printf("[CLEANUP ERROR] purge akun: %v", err)
+			}
+			if purged > 0 {
+				log.Printf("[CLEANUP] %d akun dihapus permanen", purged)
+			}
+
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// StartDataExportCleanupScheduler menghapus arsip ekspor data user yang sudah kedaluwarsa dari storage
+func StartDataExportCleanupScheduler(db *gorm.DB, store storage.Storage, interval time.Duration) {
+	go func() {
+		for {
+			removed, err := userService.CleanupDataExports(context.Background(), db, store)
+			if err != nil {
+				log.Printf("[CLEANUP ERROR] data export: %v", err)
+			}
+			if removed > 0 {
+				log.Printf("[CLEANUP] %d arsip ekspor data dihapus", removed)
+			}
+
+			time.Sleep(interval)
 		}
 	}()
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"masjidku/internals/configs"
+	modelUser "masjidku/internals/features/users/user/models"
+	"masjidku/internals/mailer"
+)
+
+// ErrInvalidVerificationToken: token verifikasi rusak, salah tanda tangan, atau kadaluarsa
+var ErrInvalidVerificationToken = errors.New("token verifikasi tidak valid atau sudah kadaluarsa")
+
+// EmailVerificationClaims adalah isi token verifikasi email. Email ikut ditandatangani
+// sehingga link otomatis tidak berlaku jika user mengganti email.
+type EmailVerificationClaims struct {
+	UserID uuid.UUID `json:"uid"`
+	Email  string    `json:"email"`
+	Exp    int64     `json:"exp"`
+}
+
+// EmailVerifier membuat, mengirim dan memeriksa link verifikasi email
+// (dipakai saat registrasi, kirim ulang, dan saat user mengganti email)
+type EmailVerifier struct {
+	cfg    *configs.Config
+	mailer mailer.Mailer
+}
+
+func NewEmailVerifier(cfg *configs.Config, mail mailer.Mailer) *EmailVerifier {
+	return &EmailVerifier{cfg: cfg, mailer: mail}
+}
+
+// Send mengirim link verifikasi ke email user saat ini
+func (v *EmailVerifier) Send(ctx context.Context, user *modelUser.UserModel) error {
+	token, err := v.sign(EmailVerificationClaims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Exp:    time.Now().Add(v.cfg.Auth.EmailVerifyTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := strings.TrimRight(v.cfg.App.FrontendURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+	return v.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun Masjidku",
+		Body: fmt.Sprintf("Assalamu'alaikum %s,\n\n"+
+			"Buka link berikut untuk memverifikasi email akun Masjidku Anda:\n\n%s\n\n"+
+			"Link ini berlaku %s.\n",
+			user.UserName, link, v.cfg.Auth.EmailVerifyTTL),
+	})
+}
+
+// Parse memeriksa tanda tangan dan masa berlaku token dari link verifikasi
+func (v *EmailVerifier) Parse(token string) (*EmailVerificationClaims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidVerificationToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, v.mac(encoded)) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	var claims EmailVerificationClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	if time.Now().Unix() > claims.Exp {
+		return nil, ErrInvalidVerificationToken
+	}
+	return &claims, nil
+}
+
+func (v *EmailVerifier) sign(claims EmailVerificationClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(v.mac(encoded)), nil
+}
+
+// mac = HMAC-SHA256(secret, payload). Jika EMAIL_VERIFY_SECRET kosong,
+// secret diturunkan dari JWT_SECRET dengan label khusus agar tidak bisa dipakai sebagai JWT.
+func (v *EmailVerifier) mac(payload string) []byte {
+	secret := []byte(v.cfg.Auth.EmailVerifySecret)
+	if len(secret) == 0 {
+		derive := hmac.New(sha256.New, []byte(v.cfg.JWT.Secret))
+		derive.Write([]byte("masjidku/email-verification"))
+		secret = derive.Sum(nil)
+	}
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/dataexport"
+	modelAuth "masjidku/internals/features/users/auth/models"
+)
+
+func init() {
+	dataexport.Register("auth", exportAuth)
+}
+
+// exportAuth: riwayat sesi login per perangkat, riwayat percobaan login dan status 2FA.
+// Token, secret TOTP dan hash kode pemulihan tidak pernah ikut diekspor.
+func exportAuth(ctx context.Context, db *gorm.DB, userID uuid.UUID, archive *dataexport.Archive) error {
+	var sessions []struct {
+		FamilyID      uuid.UUID
+		UserAgent     string
+		IPAddress     string
+		MFA           bool
+		StartedAt     time.Time
+		LastActiveAt  time.Time
+		ExpiresAt     time.Time
+		RevokedAt     *time.Time
+		RevokedReason string
+	}
+	// Satu baris per sesi (family); token terakhir dalam family mewakili kondisi sesi saat ini
+	err := db.Raw(`SELECT DISTINCT ON (family_id) family_id, user_agent, ip_address, mfa,
+			(SELECT MIN(f.created_at) FROM refresh_tokens f WHERE f.family_id = t.family_id) AS started_at,
+			created_at AS last_active_at, expires_at, revoked_at, revoked_reason
+		FROM refresh_tokens t WHERE user_id = ?
+		ORDER BY family_id, created_at DESC`, userID).Scan(&sessions).Error
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, []string{
+			s.FamilyID.String(), s.UserAgent, s.IPAddress, strconv.FormatBool(s.MFA),
+			dataexport.FormatTime(&s.StartedAt), dataexport.FormatTime(&s.LastActiveAt),
+			dataexport.FormatTime(&s.ExpiresAt), dataexport.FormatTime(s.RevokedAt), s.RevokedReason,
+		})
+	}
+	if err := archive.CSV("sessions.csv", []string{
+		"session_id", "user_agent", "ip_address", "mfa", "started_at", "last_active_at", "expires_at", "revoked_at", "revoked_reason",
+	}, rows); err != nil {
+		return err
+	}
+
+	var attempts []modelAuth.LoginAttempt
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&attempts).Error; err != nil {
+		return err
+	}
+	rows = make([][]string, 0, len(attempts))
+	for _, a := range attempts {
+		rows = append(rows, []string{
+			dataexport.FormatTime(&a.CreatedAt), a.Identifier, a.IPAddress, a.UserAgent,
+			strconv.FormatBool(a.Success), a.FailureReason,
+		})
+	}
+	if err := archive.CSV("login_attempts.csv", []string{
+		"created_at", "identifier", "ip_address", "user_agent", "success", "failure_reason",
+	}, rows); err != nil {
+		return err
+	}
+
+	var mfa modelAuth.UserMFA
+	res := db.Where("user_id = ?", userID).Limit(1).Find(&mfa)
+	if res.Error != nil {
+		return res.Error
+	}
+	return archive.JSON("mfa.json", map[string]interface{}{
+		"enabled":    res.RowsAffected > 0 && mfa.EnabledAt != nil,
+		"enabled_at": mfa.EnabledAt,
+	})
+}
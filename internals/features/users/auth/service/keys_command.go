@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const keysUsage = `Usage: masjidku keys <command>
+
+Commands:
+  list               Tampilkan semua signing key beserta statusnya
+  generate [alg]     Tambah signing key aktif baru (RS256 | EdDSA, default JWT_ALGORITHM)
+  rotate [alg]       Buat signing key baru dan pensiunkan kunci aktif lain (masa tenggang JWT_KEY_GRACE_PERIOD)
+  prune              Hapus kunci yang masa verifikasinya sudah habis`
+
+// RunKeysCommand menjalankan subcommand `masjidku keys ...`
+func RunKeysCommand(keys *KeySet, args []string) error {
+	if len(args) == 0 {
+		return errors.New(keysUsage)
+	}
+	ctx := context.Background()
+
+	alg := keys.cfg.Algorithm
+	if len(args) > 1 {
+		alg = args[1]
+	}
+
+	switch args[0] {
+	case "list":
+		rows, err := keys.List(ctx)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			fmt.Println("Belum ada signing key")
+			return nil
+		}
+		now := time.Now()
+		for _, row := range rows {
+			status := "active"
+			switch {
+			case row.VerifyUntil != nil && row.VerifyUntil.Before(now):
+				status = "expired"
+			case row.VerifyUntil != nil:
+				status = "retired, verify until " + row.VerifyUntil.Format(time.RFC3339)
+			case row.RetiredAt != nil:
+				status = "retired"
+			}
+			fmt.Printf("  %s  %-5s  %s  (%s)\n", row.KID, row.Algorithm, row.CreatedAt.Format(time.RFC3339), status)
+		}
+		return nil
+
+	case "generate":
+		row, err := keys.Generate(ctx, alg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Signing key dibuat: kid=%s alg=%s\n", row.KID, row.Algorithm)
+		return nil
+
+	case "rotate":
+		row, err := keys.Rotate(ctx, alg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Signing key dirotasi: kid=%s alg=%s (kunci lama diverifikasi sampai %s)\n",
+			row.KID, row.Algorithm, time.Now().Add(keys.cfg.KeyGracePeriod).Format(time.RFC3339))
+		return nil
+
+	case "prune":
+		removed, err := keys.Prune(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ %d signing key kedaluwarsa dihapus\n", removed)
+		return nil
+
+	default:
+		return fmt.Errorf("perintah tidak dikenal: %q\n\n%s", args[0], keysUsage)
+	}
+}
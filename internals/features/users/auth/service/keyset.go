@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+
+	"masjidku/internals/configs"
+	modelAuth "masjidku/internals/features/users/auth/models"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	// reloadCooldown membatasi reload key set saat menerima kid yang belum dikenal
+	// (kunci baru dari replika lain) supaya token dengan kid asal-asalan tidak membanjiri database
+	reloadCooldown = 10 * time.Second
+)
+
+var (
+	ErrNoSigningKey     = errors.New("belum ada signing key aktif, jalankan `masjidku keys generate`")
+	ErrUnknownAlgorithm = errors.New("algoritma harus RS256 atau EdDSA")
+)
+
+// KeySet memuat signing key dari tabel jwt_signing_keys: kunci aktif terbaru dipakai
+// untuk sign access token, semua kunci yang belum lewat VerifyUntil dipakai untuk verifikasi
+// (dipilih lewat header kid) dan dipublikasikan di /.well-known/jwks.json.
+type KeySet struct {
+	db  *gorm.DB
+	cfg configs.JWTConfig
+	box *SecretBox
+
+	mu       sync.RWMutex
+	keys     map[string]*loadedKey
+	signer   *loadedKey
+	loadedAt time.Time
+}
+
+type loadedKey struct {
+	kid         string
+	method      jwt.SigningMethod
+	public      crypto.PublicKey
+	private     crypto.PrivateKey // nil untuk kunci yang sudah dipensiunkan
+	createdAt   time.Time
+	verifyUntil *time.Time
+}
+
+func NewKeySet(db *gorm.DB, cfg configs.JWTConfig) (*KeySet, error) {
+	box, err := NewSecretBox(cfg.KeyEncryptionKey, cfg.Secret, "masjidku/jwt-signing-keys")
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{db: db, cfg: cfg, box: box, keys: map[string]*loadedKey{}}, nil
+}
+
+// Load memuat ulang semua kunci yang masih berlaku dari database
+func (k *KeySet) Load(ctx context.Context) error {
+	var rows []modelAuth.SigningKey
+	if err := k.db.WithContext(ctx).
+		Where("verify_until IS NULL OR verify_until > ?", time.Now()).
+		Order("created_at DESC").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	keys := make(map[string]*loadedKey, len(rows))
+	var signer *loadedKey
+	for i := range rows {
+		key, err := k.decode(&rows[i])
+		if err != nil {
+			return fmt.Errorf("kunci %s: %w", rows[i].KID, err)
+		}
+		keys[key.kid] = key
+		// rows sudah urut terbaru dulu, jadi kunci aktif pertama adalah signer
+		if signer == nil && key.private != nil {
+			signer = key
+		}
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.signer = signer
+	k.loadedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// EnsureSigningKey membuat kunci pertama (algoritma dari JWT_ALGORITHM) jika belum ada
+// kunci aktif sama sekali. Advisory lock mencegah beberapa replika membuat kunci bersamaan.
+func (k *KeySet) EnsureSigningKey(ctx context.Context) error {
+	err := k.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('masjidku_jwt_signing_keys'))").Error; err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&modelAuth.SigningKey{}).Where("retired_at IS NULL").Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return nil
+		}
+		row, err := k.generate(k.cfg.Algorithm)
+		if err != nil {
+			return err
+		}
+		log.Printf("[KEYS] Signing key pertama dibuat: kid=%s alg=%s", row.KID, row.Algorithm)
+		return tx.Create(row).Error
+	})
+	if err != nil {
+		return err
+	}
+	return k.Load(ctx)
+}
+
+// StartRefresh memuat ulang key set secara berkala supaya rotasi dari CLI / replika lain terbaca
+func (k *KeySet) StartRefresh(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := k.Load(ctx); err != nil {
+					log.Printf("[KEYS ERROR] Gagal memuat ulang signing key: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Generate menambah kunci aktif baru tanpa memensiunkan kunci lain
+func (k *KeySet) Generate(ctx context.Context, alg string) (*modelAuth.SigningKey, error) {
+	row, err := k.generate(alg)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.db.WithContext(ctx).Create(row).Error; err != nil {
+		return nil, err
+	}
+	return row, k.Load(ctx)
+}
+
+// Rotate membuat kunci aktif baru lalu memensiunkan semua kunci aktif lain. Kunci lama
+// tetap dipakai untuk verifikasi selama KeyGracePeriod (token yang sudah terbit tetap valid).
+func (k *KeySet) Rotate(ctx context.Context, alg string) (*modelAuth.SigningKey, error) {
+	row, err := k.generate(alg)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = k.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&modelAuth.SigningKey{}).Where("retired_at IS NULL").
+			Updates(map[string]interface{}{
+				"retired_at":   now,
+				"verify_until": now.Add(k.cfg.KeyGracePeriod),
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(row).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row, k.Load(ctx)
+}
+
+// Prune menghapus kunci yang masa verifikasinya sudah habis
+func (k *KeySet) Prune(ctx context.Context) (int64, error) {
+	res := k.db.WithContext(ctx).
+		Where("verify_until IS NOT NULL AND verify_until <= ?", time.Now()).
+		Delete(&modelAuth.SigningKey{})
+	return res.RowsAffected, res.Error
+}
+
+// List mengembalikan semua kunci di database, terbaru dulu
+func (k *KeySet) List(ctx context.Context) ([]modelAuth.SigningKey, error) {
+	var rows []modelAuth.SigningKey
+	err := k.db.WithContext(ctx).Order("created_at DESC").Find(&rows).Error
+	return rows, err
+}
+
+// Sign menandatangani claims dengan kunci aktif terbaru dan mengisi header kid
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	signer := k.signer
+	k.mu.RUnlock()
+	if signer == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(signer.method, claims)
+	token.Header["kid"] = signer.kid
+	return token.SignedString(signer.private)
+}
+
+// Keyfunc memilih public key berdasarkan header kid untuk jwt.Parse / gofiber jwtware.
+// Algoritma di header harus sama dengan algoritma kunci (mencegah alg confusion).
+func (k *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token tanpa kid")
+	}
+
+	key := k.lookup(kid)
+	if key == nil {
+		return nil, fmt.Errorf("kid tidak dikenal: %s", kid)
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("algoritma %v tidak cocok dengan kunci %s", t.Header["alg"], kid)
+	}
+	if key.verifyUntil != nil && time.Now().After(*key.verifyUntil) {
+		return nil, fmt.Errorf("kunci %s sudah tidak berlaku", kid)
+	}
+	return key.public, nil
+}
+
+func (k *KeySet) lookup(kid string) *loadedKey {
+	k.mu.RLock()
+	key := k.keys[kid]
+	stale := time.Since(k.loadedAt) > reloadCooldown
+	k.mu.RUnlock()
+	if key != nil || !stale {
+		return key
+	}
+
+	// Kemungkinan kunci baru hasil rotasi di replika lain yang belum terbaca
+	if err := k.Load(context.Background()); err != nil {
+		log.Printf("[KEYS ERROR] Gagal memuat ulang signing key: %v", err)
+		return nil
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[kid]
+}
+
+// JWK adalah satu entri JSON Web Key (RFC 7517) untuk RSA atau Ed25519 (RFC 8037)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS mengembalikan semua public key yang masih bisa dipakai verifikasi, terbaru dulu
+func (k *KeySet) JWKS() []JWK {
+	k.mu.RLock()
+	keys := make([]*loadedKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+	k.mu.RUnlock()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].createdAt.After(keys[j].createdAt) })
+
+	out := make([]JWK, 0, len(keys))
+	for _, key := range keys {
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		out = append(out, jwk)
+	}
+	return out
+}
+
+// generate membuat pasangan kunci baru (RSA-2048 atau Ed25519); private key disimpan terenkripsi
+func (k *KeySet) generate(alg string) (*modelAuth.SigningKey, error) {
+	var (
+		public  crypto.PublicKey
+		private crypto.PrivateKey
+	)
+	switch alg {
+	case AlgRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		public, private = &priv.PublicKey, priv
+	case AlgEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		public, private = pub, priv
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := k.box.Seal(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})))
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &modelAuth.SigningKey{
+		KID:           now.UTC().Format("20060102") + "-" + hex.EncodeToString(suffix),
+		Algorithm:     alg,
+		PublicKeyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})),
+		PrivateKeyEnc: sealed,
+		CreatedAt:     now,
+	}, nil
+}
+
+func (k *KeySet) decode(row *modelAuth.SigningKey) (*loadedKey, error) {
+	key := &loadedKey{kid: row.KID, createdAt: row.CreatedAt, verifyUntil: row.VerifyUntil}
+	switch row.Algorithm {
+	case AlgRS256:
+		key.method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+
+	block, _ := pem.Decode([]byte(row.PublicKeyPEM))
+	if block == nil {
+		return nil, errors.New("public key PEM tidak valid")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key.public = pub
+
+	// Private key kunci yang sudah dipensiunkan tidak perlu dibuka
+	if row.RetiredAt != nil {
+		return key, nil
+	}
+	privPEM, err := k.box.Open(row.PrivateKeyEnc)
+	if err != nil {
+		return nil, fmt.Errorf("dekripsi private key (cek JWT_KEY_ENCRYPTION_KEY): %w", err)
+	}
+	block, _ = pem.Decode([]byte(privPEM))
+	if block == nil {
+		return nil, errors.New("private key PEM tidak valid")
+	}
+	key.private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
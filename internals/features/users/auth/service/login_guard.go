@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"masjidku/internals/configs"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	modelUser "masjidku/internals/features/users/user/models"
+	"masjidku/internals/ratelimit"
+)
+
+// LockoutEvent dikirim ke hook saat akun baru saja dikunci
+type LockoutEvent struct {
+	User     modelUser.UserModel
+	Until    time.Time
+	Failures int
+	Meta     ClientMeta
+}
+
+// LockoutHook dipanggil (di goroutine terpisah) setiap kali akun dikunci, mis. untuk email ke user
+type LockoutHook func(ctx context.Context, event LockoutEvent)
+
+// LoginGuard melindungi login dengan password dari brute force:
+//   - rate limit sliding window per akun (di samping rate limit per IP di middleware)
+//   - delay progresif setelah LoginFreeAttempts kegagalan berturut-turut
+//   - penguncian sementara setelah LoginLockoutThreshold kegagalan dalam LoginLockoutWindow
+//
+// Semua percobaan dicatat di tabel login_attempts.
+type LoginGuard struct {
+	db          *gorm.DB
+	cfg         configs.AuthConfig
+	limiter     *ratelimit.Limiter
+	accountRule ratelimit.Rule
+
+	mu    sync.RWMutex
+	hooks []LockoutHook
+}
+
+func NewLoginGuard(db *gorm.DB, cfg *configs.Config, limiter *ratelimit.Limiter) *LoginGuard {
+	return &LoginGuard{
+		db:          db,
+		cfg:         cfg.Auth,
+		limiter:     limiter,
+		accountRule: ratelimit.Rule{Limit: cfg.RateLimit.LoginPerAccount, Window: cfg.RateLimit.LoginWindow},
+	}
+}
+
+// OnLockout mendaftarkan hook notifikasi penguncian akun
+func (g *LoginGuard) OnLockout(hook LockoutHook) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.hooks = append(g.hooks, hook)
+}
+
+// Check dipanggil sebelum password diverifikasi. Jika wait > 0 login harus ditolak (429)
+// tanpa memeriksa password; reason berisi LoginFailLocked atau LoginFailThrottled.
+// user boleh nil jika identifier tidak cocok dengan user mana pun.
+func (g *LoginGuard) Check(ctx context.Context, identifier string, user *modelUser.UserModel) (wait time.Duration, reason string, err error) {
+	now := time.Now()
+	if user != nil && user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return user.LockedUntil.Sub(now), modelAuth.LoginFailLocked, nil
+	}
+
+	res, err := g.limiter.Allow(ctx, g.accountKey(identifier, user), g.accountRule)
+	if err != nil {
+		// Store rate limit bermasalah: jangan blokir login, delay & lockout di bawah tetap berlaku
+		log.Printf("[RATELIMIT ERROR] login account: %v", err)
+	} else if !res.Allowed {
+		return res.RetryAfter, modelAuth.LoginFailThrottled, nil
+	}
+
+	failures, last, err := g.failures(ctx, identifier, user, now)
+	if err != nil {
+		return 0, "", err
+	}
+	if failures < g.cfg.LoginFreeAttempts {
+		return 0, "", nil
+	}
+	if wait := last.Add(g.delay(failures)).Sub(now); wait > 0 {
+		return wait, modelAuth.LoginFailThrottled, nil
+	}
+	return 0, "", nil
+}
+
+// RecordFailure mencatat percobaan gagal. Kegagalan karena password salah dihitung untuk
+// delay progresif; jika mencapai ambang, akun dikunci dan hook OnLockout dipanggil sekali.
+func (g *LoginGuard) RecordFailure(ctx context.Context, identifier string, user *modelUser.UserModel, meta ClientMeta, reason string) error {
+	if err := g.record(ctx, identifier, user, meta, false, reason); err != nil {
+		return err
+	}
+	if user == nil || reason != modelAuth.LoginFailInvalidCredentials {
+		return nil
+	}
+
+	now := time.Now()
+	failures, _, err := g.failures(ctx, identifier, user, now)
+	if err != nil || failures < g.cfg.LoginLockoutThreshold {
+		return err
+	}
+
+	until := now.Add(g.cfg.LoginLockoutDuration)
+	res := g.db.WithContext(ctx).Model(&modelUser.UserModel{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", user.ID, now).
+		Update("locked_until", until)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil // sudah dikunci oleh request lain
+	}
+
+	log.Printf("[WARNING] Akun %s dikunci sampai %s setelah %d login gagal (IP=%s)", user.ID, until.Format(time.RFC3339), failures, meta.IP)
+	event := LockoutEvent{User: *user, Until: until, Failures: failures, Meta: meta}
+	g.mu.RLock()
+	hooks := append([]LockoutHook(nil), g.hooks...)
+	g.mu.RUnlock()
+	for _, hook := range hooks {
+		go hook(context.Background(), event)
+	}
+	return nil
+}
+
+// RecordSuccess mencatat login sukses; hitungan kegagalan dimulai lagi dari nol
+func (g *LoginGuard) RecordSuccess(ctx context.Context, identifier string, user *modelUser.UserModel, meta ClientMeta) error {
+	if err := g.record(ctx, identifier, user, meta, true, ""); err != nil {
+		return err
+	}
+	if err := g.limiter.Reset(ctx, g.accountKey(identifier, user)); err != nil {
+		log.Printf("[RATELIMIT ERROR] reset login account: %v", err)
+	}
+	if user.LockedUntil != nil {
+		return g.db.WithContext(ctx).Model(&modelUser.UserModel{}).Where("id = ?", user.ID).Update("locked_until", nil).Error
+	}
+	return nil
+}
+
+func (g *LoginGuard) record(ctx context.Context, identifier string, user *modelUser.UserModel, meta ClientMeta, success bool, reason string) error {
+	attempt := modelAuth.LoginAttempt{
+		Identifier:    truncate(normalizeIdentifier(identifier), 255),
+		IPAddress:     truncate(meta.IP, 45),
+		UserAgent:     truncate(meta.UserAgent, 255),
+		Success:       success,
+		FailureReason: reason,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	return g.db.WithContext(ctx).Create(&attempt).Error
+}
+
+// failures menghitung kegagalan karena password salah sejak yang paling akhir dari:
+// awal window, login sukses terakhir, atau berakhirnya penguncian sebelumnya
+func (g *LoginGuard) failures(ctx context.Context, identifier string, user *modelUser.UserModel, now time.Time) (int, time.Time, error) {
+	scope := func() *gorm.DB {
+		q := g.db.WithContext(ctx).Model(&modelAuth.LoginAttempt{})
+		if user != nil {
+			return q.Where("user_id = ?", user.ID)
+		}
+		return q.Where("identifier = ? AND user_id IS NULL", normalizeIdentifier(identifier))
+	}
+
+	since := now.Add(-g.cfg.LoginLockoutWindow)
+	var lastSuccess *time.Time
+	if err := scope().Where("success = ?", true).Select("MAX(created_at)").Scan(&lastSuccess).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	if lastSuccess != nil && lastSuccess.After(since) {
+		since = *lastSuccess
+	}
+	if user != nil && user.LockedUntil != nil && user.LockedUntil.After(since) && !user.LockedUntil.After(now) {
+		since = *user.LockedUntil
+	}
+
+	var row struct {
+		Failures int
+		Last     *time.Time
+	}
+	err := scope().
+		Where("success = ? AND failure_reason = ? AND created_at > ?", false, modelAuth.LoginFailInvalidCredentials, since).
+		Select("COUNT(*) AS failures, MAX(created_at) AS last").Scan(&row).Error
+	if err != nil || row.Last == nil {
+		return row.Failures, time.Time{}, err
+	}
+	return row.Failures, *row.Last, nil
+}
+
+// delay = LoginDelayBase * 2^(failures - LoginFreeAttempts), maksimal LoginDelayMax
+func (g *LoginGuard) delay(failures int) time.Duration {
+	d := g.cfg.LoginDelayBase
+	for i := g.cfg.LoginFreeAttempts; i < failures; i++ {
+		d *= 2
+		if d >= g.cfg.LoginDelayMax {
+			return g.cfg.LoginDelayMax
+		}
+	}
+	return d
+}
+
+func (g *LoginGuard) accountKey(identifier string, user *modelUser.UserModel) string {
+	if user != nil {
+		return "login:account:" + user.ID.String()
+	}
+	return "login:identifier:" + normalizeIdentifier(identifier)
+}
+
+func normalizeIdentifier(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
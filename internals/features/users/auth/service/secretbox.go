@@ -0,0 +1,71 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// SecretBox mengenkripsi data kecil (mis. secret TOTP) dengan AES-256-GCM
+// sebelum disimpan di database.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox membuat SecretBox dari key apa pun; key di-hash SHA-256 menjadi 32 byte.
+// Jika key kosong, key diturunkan dari fallback dengan label sehingga tidak sama dengan secret aslinya.
+func NewSecretBox(key, fallback, label string) (*SecretBox, error) {
+	var k []byte
+	if key != "" {
+		sum := sha256.Sum256([]byte(key))
+		k = sum[:]
+	} else {
+		if fallback == "" {
+			return nil, errors.New("secretbox: key kosong")
+		}
+		mac := hmac.New(sha256.New, []byte(fallback))
+		mac.Write([]byte(label))
+		k = mac.Sum(nil)
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal mengenkripsi plaintext, hasilnya base64(nonce || ciphertext)
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// Open membuka hasil Seal
+func (b *SecretBox) Open(sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	n := b.aead.NonceSize()
+	if len(raw) < n {
+		return "", errors.New("secretbox: ciphertext terlalu pendek")
+	}
+	plain, err := b.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
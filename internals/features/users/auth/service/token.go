@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"masjidku/internals/configs"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	"masjidku/internals/features/users/auth/revocation"
+	modelUser "masjidku/internals/features/users/user/models"
+)
+
+var (
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrTokenNotRegistered = errors.New("refresh token not registered")
+	ErrTokenRevoked       = errors.New("refresh token has been revoked")
+	// ErrTokenReused dikembalikan jika refresh token yang sudah dirotasi dipakai lagi;
+	// seluruh family (sesi) sudah dicabut saat error ini dikembalikan.
+	ErrTokenReused = errors.New("refresh token reuse detected")
+)
+
+// AccessClaims adalah isi access token untuk semua jalur login (password, Google, MFA).
+// sub berisi UUID user, jti unik per token.
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	Role      string `json:"role"`
+	UserName  string `json:"user_name,omitempty"`
+	MFA       bool   `json:"mfa,omitempty"` // true jika sesi dibuat lewat login 2FA
+	SessionID string `json:"sid,omitempty"` // family ID refresh token (satu sesi / perangkat)
+	// Actor diisi pada token impersonation: admin yang bertindak atas nama user sub (RFC 8693 "act")
+	Actor *ActorClaim `json:"act,omitempty"`
+}
+
+// ActorClaim adalah claim "act" (RFC 8693 §4.1): pihak yang sebenarnya memakai token
+type ActorClaim struct {
+	Subject   string `json:"sub"`           // UUID admin
+	SessionID string `json:"sid,omitempty"` // sesi admin saat impersonation dimulai; ikut dicabut jika sesi itu dicabut
+}
+
+// UserID mengembalikan UUID user dari claim sub
+func (c *AccessClaims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// FamilyID mengembalikan family ID sesi dari claim sid
+func (c *AccessClaims) FamilyID() (uuid.UUID, error) {
+	return uuid.Parse(c.SessionID)
+}
+
+// Impersonated bernilai true jika token diterbitkan untuk admin yang login sebagai user lain
+func (c *AccessClaims) Impersonated() bool {
+	return c.Actor != nil
+}
+
+// ActorID mengembalikan UUID admin dari claim act.sub
+func (c *AccessClaims) ActorID() (uuid.UUID, error) {
+	if c.Actor == nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return uuid.Parse(c.Actor.Subject)
+}
+
+// RefreshClaims adalah isi refresh token. Token juga disimpan di tabel refresh_tokens,
+// yang menjadi sumber kebenaran untuk status MFA, family dan pencabutan.
+type RefreshClaims struct {
+	jwt.RegisteredClaims
+}
+
+// ClientMeta adalah informasi perangkat yang dicatat untuk setiap sesi
+type ClientMeta struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenPair adalah hasil penerbitan sesi baru
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// TokenService menerbitkan dan memverifikasi access token serta refresh token
+// dengan claim yang seragam (sub, jti, iat, iss, aud, role).
+// Access token ditandatangani KeySet (RS256/EdDSA, header kid) sehingga bisa diverifikasi
+// layanan lain lewat JWKS; refresh token tetap HS256 karena hanya diverifikasi server ini.
+type TokenService struct {
+	cfg     configs.JWTConfig
+	keys    *KeySet
+	revoked revocation.Store
+}
+
+func NewTokenService(cfg configs.JWTConfig, keys *KeySet, revoked revocation.Store) *TokenService {
+	return &TokenService{cfg: cfg, keys: keys, revoked: revoked}
+}
+
+// Keys mengembalikan key set yang dipakai untuk access token
+func (s *TokenService) Keys() *KeySet {
+	return s.keys
+}
+
+// Issue memulai sesi (family) baru untuk user: membuat access token + refresh token
+// dan menyimpan refresh token di db
+func (s *TokenService) Issue(db *gorm.DB, user *modelUser.UserModel, mfa bool, meta ClientMeta) (*TokenPair, error) {
+	return s.issue(db, user, modelAuth.RefreshToken{
+		FamilyID: uuid.New(),
+		MFA:      mfa,
+	}, meta)
+}
+
+func (s *TokenService) issue(db *gorm.DB, user *modelUser.UserModel, rt modelAuth.RefreshToken, meta ClientMeta) (*TokenPair, error) {
+	now := time.Now()
+	pair := &TokenPair{
+		AccessExpiresAt:  now.Add(s.cfg.AccessTTL),
+		RefreshExpiresAt: now.Add(s.cfg.RefreshTTL),
+	}
+
+	access := AccessClaims{
+		RegisteredClaims: s.registered(user.ID, now, pair.AccessExpiresAt),
+		Role:             user.Role,
+		UserName:         user.UserName,
+		MFA:              rt.MFA,
+		SessionID:        rt.FamilyID.String(),
+	}
+	var err error
+	pair.AccessToken, err = s.keys.Sign(access)
+	if err != nil {
+		return nil, fmt.Errorf("sign access token: %w", err)
+	}
+
+	refresh := RefreshClaims{RegisteredClaims: s.registered(user.ID, now, pair.RefreshExpiresAt)}
+	pair.RefreshToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, refresh).SignedString([]byte(s.cfg.RefreshSecret))
+	if err != nil {
+		return nil, fmt.Errorf("sign refresh token: %w", err)
+	}
+
+	rt.UserID = user.ID
+	rt.Token = pair.RefreshToken
+	rt.ExpiresAt = pair.RefreshExpiresAt
+	rt.UserAgent = truncate(meta.UserAgent, 255)
+	rt.IPAddress = truncate(meta.IP, 45)
+	if err := db.Create(&rt).Error; err != nil {
+		return nil, fmt.Errorf("store refresh token: %w", err)
+	}
+	return pair, nil
+}
+
+// IssueImpersonation menerbitkan access token berumur ttl untuk admin (actor) yang login sebagai user.
+// Token tidak punya refresh token maupun sesi sendiri (tidak bisa diperpanjang) dan terikat ke sesi
+// admin lewat act.sid: logout / pencabutan sesi admin ikut mengakhiri impersonation.
+func (s *TokenService) IssueImpersonation(user *modelUser.UserModel, actor *AccessClaims, ttl time.Duration) (string, *AccessClaims, error) {
+	now := time.Now()
+	claims := &AccessClaims{
+		RegisteredClaims: s.registered(user.ID, now, now.Add(ttl)),
+		Role:             user.Role,
+		UserName:         user.UserName,
+		// 📌 Status 2FA mengikuti sesi admin, bukan user yang di-impersonate
+		MFA:   actor.MFA,
+		Actor: &ActorClaim{Subject: actor.Subject, SessionID: actor.SessionID},
+	}
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("sign impersonation token: %w", err)
+	}
+	return token, claims, nil
+}
+
+// Rotate menukar refresh token dengan pasangan token baru dalam family yang sama.
+// Token lama ditandai rotated (tidak dihapus) sehingga jika dipakai lagi — tanda token
+// dicuri — seluruh family dicabut dan ErrTokenReused dikembalikan.
+func (s *TokenService) Rotate(db *gorm.DB, refreshToken string, meta ClientMeta) (*TokenPair, *modelUser.UserModel, error) {
+	claims, err := s.ParseRefresh(refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	var (
+		pair   *TokenPair
+		user   modelUser.UserModel
+		reused bool
+	)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var current modelAuth.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token = ? AND user_id = ?", refreshToken, userID).First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTokenNotRegistered
+			}
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			if current.RevokedReason != modelAuth.RevokeReasonRotated {
+				return ErrTokenRevoked
+			}
+			// Replay token yang sudah dirotasi: cabut seluruh family, commit, lalu tolak
+			reused = true
+			_, err := s.RevokeFamily(tx, userID, current.FamilyID, modelAuth.RevokeReasonReuseDetected)
+			return err
+		}
+		if time.Now().After(current.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		// Akun yang di-ban / disuspend tidak mendapat token baru
+		if err := user.BlockedError(time.Now()); err != nil {
+			return err
+		}
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": modelAuth.RevokeReasonRotated,
+		}).Error; err != nil {
+			return err
+		}
+
+		pair, err = s.issue(tx, &user, modelAuth.RefreshToken{
+			FamilyID: current.FamilyID,
+			ParentID: &current.ID,
+			MFA:      current.MFA,
+		}, meta)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if reused {
+		return nil, nil, ErrTokenReused
+	}
+	return pair, &user, nil
+}
+
+// RevokeFamily mencabut semua refresh token aktif dalam satu sesi. Access token sesi itu
+// ikut dicabut lewat revocation store (key sid) sampai access token terakhirnya kedaluwarsa.
+func (s *TokenService) RevokeFamily(db *gorm.DB, userID, familyID uuid.UUID, reason string) (int64, error) {
+	res := db.Model(&modelAuth.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if err := s.revokeSession(db.Statement.Context, familyID); err != nil {
+		return res.RowsAffected, err
+	}
+	return res.RowsAffected, nil
+}
+
+// RevokeUserSessions mencabut semua sesi user, kecuali family except jika diisi
+func (s *TokenService) RevokeUserSessions(db *gorm.DB, userID uuid.UUID, except *uuid.UUID, reason string) (int64, error) {
+	q := db.Model(&modelAuth.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if except != nil {
+		q = q.Where("family_id <> ?", *except)
+	}
+
+	var families []uuid.UUID
+	if err := q.Session(&gorm.Session{}).Distinct("family_id").Pluck("family_id", &families).Error; err != nil {
+		return 0, err
+	}
+	res := q.Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	for _, familyID := range families {
+		if err := s.revokeSession(db.Statement.Context, familyID); err != nil {
+			return res.RowsAffected, err
+		}
+	}
+	return res.RowsAffected, nil
+}
+
+// RevokeAccess mencabut satu access token berdasarkan jti sampai exp aslinya
+func (s *TokenService) RevokeAccess(ctx context.Context, claims *AccessClaims) error {
+	return s.revoked.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// IsAccessRevoked mengecek pencabutan per token (jti) maupun per sesi (sid)
+func (s *TokenService) IsAccessRevoked(ctx context.Context, claims *AccessClaims) (bool, error) {
+	revoked, err := s.revoked.IsRevoked(ctx, claims.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+	if claims.SessionID != "" {
+		if revoked, err = s.revoked.IsRevoked(ctx, revocation.SessionKey(claims.SessionID)); err != nil || revoked {
+			return revoked, err
+		}
+	}
+	// Token impersonation ikut batal jika sesi admin yang memulainya dicabut
+	if claims.Actor != nil && claims.Actor.SessionID != "" {
+		return s.revoked.IsRevoked(ctx, revocation.SessionKey(claims.Actor.SessionID))
+	}
+	return false, nil
+}
+
+func (s *TokenService) revokeSession(ctx context.Context, familyID uuid.UUID) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Access token terakhir sesi ini paling lambat kedaluwarsa AccessTTL dari sekarang
+	return s.revoked.Revoke(ctx, revocation.SessionKey(familyID.String()), time.Now().Add(s.cfg.AccessTTL))
+}
+
+// ParseAccess memverifikasi tanda tangan (terhadap key set, berdasarkan kid), exp, iss dan aud access token
+func (s *TokenService) ParseAccess(token string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, s.keys.Keyfunc)
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+	if !s.validRegistered(&claims.RegisteredClaims) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ParseRefresh memverifikasi tanda tangan, exp, iss dan aud refresh token
+func (s *TokenService) ParseRefresh(token string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(s.cfg.RefreshSecret), nil
+	})
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+	if !s.validRegistered(&claims.RegisteredClaims) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (s *TokenService) registered(userID uuid.UUID, now, exp time.Time) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   userID.String(),
+		ID:        uuid.NewString(),
+		Issuer:    s.cfg.Issuer,
+		Audience:  jwt.ClaimStrings{s.cfg.Audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(exp),
+	}
+}
+
+func (s *TokenService) validRegistered(c *jwt.RegisteredClaims) bool {
+	return c.ExpiresAt != nil && c.IssuedAt != nil && c.ID != "" && c.Subject != "" &&
+		c.VerifyIssuer(s.cfg.Issuer, true) && c.VerifyAudience(s.cfg.Audience, true)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	"masjidku/internals/features/users/rbac/service"
+)
+
+type RoleController struct {
+	Policy *service.Policy
+	Audit  *audit.Recorder
+}
+
+func NewRoleController(policy *service.Policy, recorder *audit.Recorder) *RoleController {
+	return &RoleController{Policy: policy, Audit: recorder}
+}
+
+// 🔐 GET /api/admin/roles - semua role beserta permission langsung, parent dan permission efektif
+func (rc *RoleController) GetRoles(c *fiber.Ctx) error {
+	roles := rc.Policy.Roles()
+	return c.JSON(fiber.Map{
+		"message": "Roles fetched successfully",
+		"total":   len(roles),
+		"data":    roles,
+	})
+}
+
+// 🔐 GET /api/admin/roles/:name
+func (rc *RoleController) GetRole(c *fiber.Ctx) error {
+	role, err := rc.Policy.Role(c.Params("name"))
+	if err != nil {
+		return roleError(err)
+	}
+	return c.JSON(fiber.Map{"data": role})
+}
+
+// 🔐 GET /api/admin/permissions - daftar permission yang dikenal aplikasi
+func (rc *RoleController) GetPermissions(c *fiber.Ctx) error {
+	perms, err := rc.Policy.ListPermissions(c.UserContext())
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("ambil permission: %w", err))
+	}
+	return c.JSON(fiber.Map{
+		"message": "Permissions fetched successfully",
+		"total":   len(perms),
+		"data":    perms,
+	})
+}
+
+// 🔐 POST /api/admin/roles - buat role custom
+func (rc *RoleController) CreateRole(c *fiber.Ctx) error {
+	var input service.RoleInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+
+	role, err := rc.Policy.CreateRole(c.UserContext(), input)
+	if err != nil {
+		return roleError(err)
+	}
+	log.Printf("[SUCCESS] Role %s created", role.Name)
+	rc.Audit.Log(c, audit.Entry{Action: audit.ActionRoleCreate, TargetType: audit.TargetRole, TargetID: role.Name, After: role})
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// 🔐 PUT /api/admin/roles/:name - ganti deskripsi, permission dan parent role
+func (rc *RoleController) UpdateRole(c *fiber.Ctx) error {
+	var input service.RoleInput
+	if err := c.BodyParser(&input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	input.Name = c.Params("name")
+	before, _ := rc.Policy.Role(input.Name)
+
+	role, err := rc.Policy.UpdateRole(c.UserContext(), input)
+	if err != nil {
+		return roleError(err)
+	}
+	log.Printf("[SUCCESS] Role %s updated", role.Name)
+	rc.Audit.Log(c, audit.Entry{Action: audit.ActionRoleUpdate, TargetType: audit.TargetRole, TargetID: role.Name, Before: before, After: role})
+	return c.JSON(fiber.Map{
+		"message": "Role updated successfully",
+		"data":    role,
+	})
+}
+
+// 🔐 DELETE /api/admin/roles/:name - hanya role custom yang tidak dipakai
+func (rc *RoleController) DeleteRole(c *fiber.Ctx) error {
+	name := c.Params("name")
+	before, _ := rc.Policy.Role(name)
+	if err := rc.Policy.DeleteRole(c.UserContext(), name); err != nil {
+		return roleError(err)
+	}
+	log.Printf("[SUCCESS] Role %s deleted", name)
+	rc.Audit.Log(c, audit.Entry{Action: audit.ActionRoleDelete, TargetType: audit.TargetRole, TargetID: name, Before: before})
+	return c.JSON(fiber.Map{"message": "Role deleted successfully"})
+}
+
+// roleError memetakan error Policy ke apperror; pesan error Policy dikirim sebagai detail
+func roleError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		return apperror.NotFound(apperror.CodeRoleNotFound)
+	case errors.Is(err, service.ErrRoleExists), errors.Is(err, service.ErrRoleInUse), errors.Is(err, service.ErrBuiltinRole):
+		return apperror.Conflict(apperror.CodeConflict).WithDetail("%s", err.Error())
+	case errors.Is(err, service.ErrInvalidRoleName), errors.Is(err, service.ErrUnknownPermission),
+		errors.Is(err, service.ErrUnknownParent), errors.Is(err, service.ErrRoleCycle):
+		return apperror.BadRequest(apperror.CodeBadRequest).WithDetail("%s", err.Error())
+	default:
+		return apperror.Internal(fmt.Errorf("operasi role: %w", err))
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// PermissionModel adalah izin bernama (mis. "donations:read"). Nama permission dicek di kode
+// (constants.Perm*), jadi tabel ini hanya diisi lewat migrasi.
+type PermissionModel struct {
+	Name        string    `gorm:"size:100;primaryKey" json:"name"`
+	Description string    `gorm:"size:255;not null;default:''" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (PermissionModel) TableName() string {
+	return "permissions"
+}
+
+// RoleModel adalah definisi role. Role builtin hasil seed tidak bisa dihapus,
+// tetapi permission dan parent-nya tetap bisa diubah lewat /api/admin/roles.
+type RoleModel struct {
+	Name        string    `gorm:"size:20;primaryKey" json:"name"`
+	Description string    `gorm:"size:255;not null;default:''" json:"description"`
+	Builtin     bool      `gorm:"not null;default:false" json:"builtin"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (RoleModel) TableName() string {
+	return "roles"
+}
+
+// RolePermissionModel memetakan role ke permission yang dimilikinya langsung
+type RolePermissionModel struct {
+	RoleName       string `gorm:"size:20;primaryKey"`
+	PermissionName string `gorm:"size:100;primaryKey"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (RolePermissionModel) TableName() string {
+	return "role_permissions"
+}
+
+// RoleParentModel: RoleName mewarisi semua permission ParentName (rekursif)
+type RoleParentModel struct {
+	RoleName   string `gorm:"size:20;primaryKey"`
+	ParentName string `gorm:"size:20;primaryKey"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (RoleParentModel) TableName() string {
+	return "role_parents"
+}
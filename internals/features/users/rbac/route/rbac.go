@@ -0,0 +1,32 @@
+package route
+
+import (
+	"masjidku/internals/audit"
+	"masjidku/internals/configs"
+	"masjidku/internals/constants"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacController "masjidku/internals/features/users/rbac/controller"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	authMw "masjidku/internals/middlewares/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RBACRoutes mendaftarkan admin API untuk definisi role & permission (khusus admin platform)
+func RBACRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy, recorder *audit.Recorder) {
+	roleCtrl := rbacController.NewRoleController(policy, recorder)
+
+	// Middleware dipasang per route (bukan di group /api/admin) agar tidak ikut berjalan
+	// di route admin milik fitur lain yang memakai permission berbeda
+	authMiddleware := authMw.AuthMiddleware(db, tokens, policy)
+	canManage := authMw.RequirePermission(constants.PermRolesManage)
+
+	admin := app.Group("/api/admin")
+	admin.Get("/permissions", authMiddleware, canManage, roleCtrl.GetPermissions)
+	admin.Get("/roles", authMiddleware, canManage, roleCtrl.GetRoles)
+	admin.Post("/roles", authMiddleware, canManage, roleCtrl.CreateRole)
+	admin.Get("/roles/:name", authMiddleware, canManage, roleCtrl.GetRole)
+	admin.Put("/roles/:name", authMiddleware, canManage, roleCtrl.UpdateRole)
+	admin.Delete("/roles/:name", authMiddleware, canManage, roleCtrl.DeleteRole)
+}
@@ -0,0 +1,436 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	modelMasjid "masjidku/internals/features/masjids/masjid/models"
+	"masjidku/internals/features/users/rbac/models"
+	modelUser "masjidku/internals/features/users/user/models"
+)
+
+// policyTTL adalah umur snapshot role -> permission sebelum dimuat ulang dari database.
+// Perubahan dari replika ini langsung berlaku; dari replika lain paling lambat setelah TTL.
+const policyTTL = time.Minute
+
+var (
+	ErrRoleNotFound      = errors.New("role tidak ditemukan")
+	ErrRoleExists        = errors.New("role sudah ada")
+	ErrBuiltinRole       = errors.New("role bawaan tidak dapat dihapus")
+	ErrRoleInUse         = errors.New("role masih dipakai oleh user atau anggota masjid")
+	ErrInvalidRoleName   = errors.New("nama role hanya boleh huruf kecil, angka dan underscore (2-20 karakter)")
+	ErrUnknownPermission = errors.New("permission tidak dikenal")
+	ErrUnknownParent     = errors.New("parent role tidak dikenal")
+	ErrRoleCycle         = errors.New("pewarisan role membentuk siklus")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+
+// RoleDefinition adalah role beserta permission langsung, parent, dan permission efektif (termasuk warisan)
+type RoleDefinition struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Builtin     bool     `json:"builtin"`
+	Permissions []string `json:"permissions"`
+	Parents     []string `json:"parents"`
+	Effective   []string `json:"effective_permissions"`
+}
+
+// RoleInput adalah isi pembuatan / perubahan role dari admin API
+type RoleInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	Parents     []string `json:"parents"`
+}
+
+// Policy menjawab "apakah role X punya permission Y" dari snapshot di memori
+// yang dibangun dari tabel roles, role_permissions dan role_parents.
+type Policy struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	snap     *snapshot
+	loadedAt time.Time
+	loading  sync.Mutex
+}
+
+type snapshot struct {
+	roles       map[string]*RoleDefinition
+	effective   map[string]map[string]bool
+	permissions map[string]bool
+}
+
+func NewPolicy(db *gorm.DB) *Policy {
+	return &Policy{db: db}
+}
+
+// Load membangun ulang snapshot dari database
+func (p *Policy) Load(ctx context.Context) error {
+	var (
+		roles   []models.RoleModel
+		perms   []models.PermissionModel
+		grants  []models.RolePermissionModel
+		parents []models.RoleParentModel
+	)
+	db := p.db.WithContext(ctx)
+	if err := db.Order("name").Find(&roles).Error; err != nil {
+		return err
+	}
+	if err := db.Find(&perms).Error; err != nil {
+		return err
+	}
+	if err := db.Find(&grants).Error; err != nil {
+		return err
+	}
+	if err := db.Find(&parents).Error; err != nil {
+		return err
+	}
+
+	snap := &snapshot{
+		roles:       make(map[string]*RoleDefinition, len(roles)),
+		effective:   make(map[string]map[string]bool, len(roles)),
+		permissions: make(map[string]bool, len(perms)),
+	}
+	for _, perm := range perms {
+		snap.permissions[perm.Name] = true
+	}
+	for _, role := range roles {
+		snap.roles[role.Name] = &RoleDefinition{
+			Name:        role.Name,
+			Description: role.Description,
+			Builtin:     role.Builtin,
+			Permissions: []string{},
+			Parents:     []string{},
+		}
+	}
+	for _, g := range grants {
+		if def, ok := snap.roles[g.RoleName]; ok {
+			def.Permissions = append(def.Permissions, g.PermissionName)
+		}
+	}
+	for _, rp := range parents {
+		if def, ok := snap.roles[rp.RoleName]; ok {
+			def.Parents = append(def.Parents, rp.ParentName)
+		}
+	}
+	for name, def := range snap.roles {
+		sort.Strings(def.Permissions)
+		sort.Strings(def.Parents)
+		set := map[string]bool{}
+		collect(snap.roles, name, set, map[string]bool{})
+		snap.effective[name] = set
+		def.Effective = sortedKeys(set)
+	}
+
+	p.mu.Lock()
+	p.snap = snap
+	p.loadedAt = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+// collect mengumpulkan permission role beserta semua leluhurnya; visited menjaga dari siklus
+// (siklus seharusnya sudah ditolak saat CreateRole / UpdateRole, tapi data bisa diubah langsung di database)
+func collect(roles map[string]*RoleDefinition, name string, into, visited map[string]bool) {
+	if visited[name] {
+		return
+	}
+	visited[name] = true
+	def, ok := roles[name]
+	if !ok {
+		return
+	}
+	for _, perm := range def.Permissions {
+		into[perm] = true
+	}
+	for _, parent := range def.Parents {
+		collect(roles, parent, into, visited)
+	}
+}
+
+func (p *Policy) current() *snapshot {
+	p.mu.RLock()
+	snap, stale := p.snap, time.Since(p.loadedAt) >= policyTTL
+	p.mu.RUnlock()
+	if !stale {
+		return snap
+	}
+
+	// Snapshot lama tetap dipakai selama satu goroutine memuat ulang di background
+	if snap != nil {
+		if p.loading.TryLock() {
+			go func() {
+				defer p.loading.Unlock()
+				p.reload()
+			}()
+		}
+		return snap
+	}
+
+	// Belum pernah dimuat: tunggu sampai selesai
+	p.loading.Lock()
+	defer p.loading.Unlock()
+	p.mu.RLock()
+	snap = p.snap
+	p.mu.RUnlock()
+	if snap == nil {
+		p.reload()
+		p.mu.RLock()
+		snap = p.snap
+		p.mu.RUnlock()
+	}
+	return snap
+}
+
+func (p *Policy) reload() {
+	if err := p.Load(context.Background()); err != nil {
+		log.Printf("[RBAC ERROR] Gagal memuat role & permission: %v", err)
+	}
+}
+
+// Can bernilai true jika role memiliki permission (langsung atau warisan)
+func (p *Policy) Can(role, permission string) bool {
+	snap := p.current()
+	if snap == nil || role == "" {
+		return false
+	}
+	return snap.effective[role][permission]
+}
+
+// GrantsAny bernilai true jika role memiliki salah satu permission (langsung atau warisan)
+func (p *Policy) GrantsAny(role string, permissions ...string) bool {
+	snap := p.current()
+	if snap == nil || role == "" {
+		return false
+	}
+	for _, perm := range permissions {
+		if snap.effective[role][perm] {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions mengembalikan permission efektif role, terurut
+func (p *Policy) Permissions(role string) []string {
+	snap := p.current()
+	if snap == nil {
+		return nil
+	}
+	return sortedKeys(snap.effective[role])
+}
+
+// RoleExists bernilai true jika role terdefinisi di tabel roles
+func (p *Policy) RoleExists(role string) bool {
+	snap := p.current()
+	if snap == nil {
+		return false
+	}
+	_, ok := snap.roles[role]
+	return ok
+}
+
+// Role mengembalikan definisi satu role
+func (p *Policy) Role(name string) (*RoleDefinition, error) {
+	snap := p.current()
+	if snap == nil {
+		return nil, errors.New("policy belum dimuat")
+	}
+	def, ok := snap.roles[name]
+	if !ok {
+		return nil, ErrRoleNotFound
+	}
+	copied := *def
+	return &copied, nil
+}
+
+// Roles mengembalikan semua role, urut nama
+func (p *Policy) Roles() []RoleDefinition {
+	snap := p.current()
+	if snap == nil {
+		return nil
+	}
+	out := make([]RoleDefinition, 0, len(snap.roles))
+	for _, def := range snap.roles {
+		out = append(out, *def)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ListPermissions mengembalikan semua permission yang terdaftar
+func (p *Policy) ListPermissions(ctx context.Context) ([]models.PermissionModel, error) {
+	var perms []models.PermissionModel
+	err := p.db.WithContext(ctx).Order("name").Find(&perms).Error
+	return perms, err
+}
+
+// CreateRole membuat role baru (non-builtin)
+func (p *Policy) CreateRole(ctx context.Context, input RoleInput) (*RoleDefinition, error) {
+	if !roleNamePattern.MatchString(input.Name) {
+		return nil, ErrInvalidRoleName
+	}
+	return p.saveRole(ctx, input, true)
+}
+
+// UpdateRole mengganti deskripsi, permission langsung dan parent role yang sudah ada
+func (p *Policy) UpdateRole(ctx context.Context, input RoleInput) (*RoleDefinition, error) {
+	return p.saveRole(ctx, input, false)
+}
+
+func (p *Policy) saveRole(ctx context.Context, input RoleInput, create bool) (*RoleDefinition, error) {
+	// Validasi terhadap data terbaru, bukan snapshot yang mungkin sudah berumur
+	if err := p.Load(ctx); err != nil {
+		return nil, err
+	}
+	p.mu.RLock()
+	snap := p.snap
+	p.mu.RUnlock()
+
+	_, exists := snap.roles[input.Name]
+	switch {
+	case create && exists:
+		return nil, ErrRoleExists
+	case !create && !exists:
+		return nil, ErrRoleNotFound
+	}
+
+	input.Permissions = dedupe(input.Permissions)
+	input.Parents = dedupe(input.Parents)
+	for _, perm := range input.Permissions {
+		if !snap.permissions[perm] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, perm)
+		}
+	}
+	for _, parent := range input.Parents {
+		if _, ok := snap.roles[parent]; !ok || parent == input.Name {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownParent, parent)
+		}
+		if inherits(snap.roles, parent, input.Name, map[string]bool{}) {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrRoleCycle, input.Name, parent)
+		}
+	}
+
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role := models.RoleModel{Name: input.Name, Description: input.Description}
+		if create {
+			if err := tx.Create(&role).Error; err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Model(&models.RoleModel{}).Where("name = ?", input.Name).
+				Updates(map[string]interface{}{"description": input.Description, "updated_at": time.Now()}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("role_name = ?", input.Name).Delete(&models.RolePermissionModel{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("role_name = ?", input.Name).Delete(&models.RoleParentModel{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, perm := range input.Permissions {
+			if err := tx.Create(&models.RolePermissionModel{RoleName: input.Name, PermissionName: perm}).Error; err != nil {
+				return err
+			}
+		}
+		for _, parent := range input.Parents {
+			if err := tx.Create(&models.RoleParentModel{RoleName: input.Name, ParentName: parent}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Load(ctx); err != nil {
+		return nil, err
+	}
+	return p.Role(input.Name)
+}
+
+// DeleteRole menghapus role custom yang tidak lagi dipakai user maupun anggota masjid
+func (p *Policy) DeleteRole(ctx context.Context, name string) error {
+	db := p.db.WithContext(ctx)
+
+	var role models.RoleModel
+	if err := db.First(&role, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	if role.Builtin {
+		return ErrBuiltinRole
+	}
+
+	var users, members int64
+	if err := db.Model(&modelUser.UserModel{}).Where("role = ?", name).Count(&users).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&modelMasjid.MasjidMemberModel{}).Where("role = ?", name).Count(&members).Error; err != nil {
+		return err
+	}
+	if users+members > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := db.Delete(&role).Error; err != nil {
+		return err
+	}
+	return p.Load(ctx)
+}
+
+// inherits bernilai true jika role `from` (langsung atau tidak) mewarisi `target`
+func inherits(roles map[string]*RoleDefinition, from, target string, visited map[string]bool) bool {
+	if from == target {
+		return true
+	}
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	def, ok := roles[from]
+	if !ok {
+		return false
+	}
+	for _, parent := range def.Parents {
+		if inherits(roles, parent, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"masjidku/internals/constants"
+)
+
+// newTestPolicy membangun Policy dari definisi role tanpa database
+func newTestPolicy(defs ...*RoleDefinition) *Policy {
+	snap := &snapshot{
+		roles:       map[string]*RoleDefinition{},
+		effective:   map[string]map[string]bool{},
+		permissions: map[string]bool{},
+	}
+	for _, def := range defs {
+		snap.roles[def.Name] = def
+	}
+	for name := range snap.roles {
+		set := map[string]bool{}
+		collect(snap.roles, name, set, map[string]bool{})
+		snap.effective[name] = set
+	}
+	return &Policy{snap: snap, loadedAt: time.Now()}
+}
+
+func TestGrantsAny(t *testing.T) {
+	p := newTestPolicy(
+		&RoleDefinition{Name: constants.RoleAdmin, Permissions: []string{constants.PermUsersManage, constants.PermRolesManage}},
+		&RoleDefinition{Name: "pengurus", Permissions: []string{constants.PermMembersRead}},
+		&RoleDefinition{Name: "super_pengurus", Parents: []string{"pengurus", constants.RoleAdmin}},
+		&RoleDefinition{Name: "cucu_admin", Parents: []string{"super_pengurus"}},
+	)
+
+	tests := []struct {
+		name string
+		role string
+		want bool
+	}{
+		{"admin langsung", constants.RoleAdmin, true},
+		{"role biasa", "pengurus", false},
+		{"custom mewarisi admin", "super_pengurus", true},
+		{"warisan bertingkat", "cucu_admin", true},
+		{"role tidak dikenal", "tidak_ada", false},
+		{"role kosong", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.GrantsAny(tt.role, constants.PlatformPermissions...); got != tt.want {
+				t.Errorf("GrantsAny(%q) = %v, want %v", tt.role, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	"masjidku/internals/features/users/user/models"
+	"masjidku/internals/features/users/user/service"
+	authMw "masjidku/internals/middlewares/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataExportController menangani ekspor data milik user yang login (portabilitas data)
+type DataExportController struct {
+	DB      *gorm.DB
+	Exports *service.DataExport
+}
+
+func NewDataExportController(db *gorm.DB, exports *service.DataExport) *DataExportController {
+	return &DataExportController{DB: db, Exports: exports}
+}
+
+// POST /api/users/me/export - arsip disusun di background; link unduhan dikirim ke email
+// dan bisa diambil lewat GET /api/users/me/export/:id
+func (dc *DataExportController) RequestExport(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var user models.UserModel
+	if err := dc.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return userLookupError(err)
+	}
+
+	export, created, err := dc.Exports.Request(c.UserContext(), &user, audit.FromRequest(c))
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("request data export: %w", err))
+	}
+
+	c.Location("/api/users/me/export/" + export.ID.String())
+	message := "Ekspor data sedang disiapkan, link unduhan akan dikirim ke email Anda"
+	if !created {
+		message = "Ekspor data sebelumnya masih berlaku"
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": message,
+		"data":    dc.view(export),
+	})
+}
+
+// GET /api/users/me/export/:id - status ekspor, beserta download_url jika sudah siap
+func (dc *DataExportController) GetExport(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidID)
+	}
+
+	export, err := dc.Exports.Find(c.UserContext(), userID, exportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeNotFound)
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cari data export: %w", err))
+	}
+	return c.JSON(fiber.Map{"data": dc.view(export)})
+}
+
+// exportView menambahkan link unduhan (dibuat ulang setiap request, berlaku sampai expires_at)
+type exportView struct {
+	*models.UserDataExportModel
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+func (dc *DataExportController) view(export *models.UserDataExportModel) exportView {
+	v := exportView{UserDataExportModel: export}
+	if export.Status == models.ExportStatusReady {
+		v.DownloadURL, _ = dc.Exports.DownloadURL(export)
+	}
+	return v
+}
@@ -1,13 +1,23 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	"masjidku/internals/etag"
+	authService "masjidku/internals/features/users/auth/service"
+	"masjidku/internals/features/users/user/dto"
 	"masjidku/internals/features/users/user/models"
+	"masjidku/internals/features/users/user/service"
+	authMw "masjidku/internals/middlewares/auth"
+	"masjidku/internals/query"
 
 	"gorm.io/gorm"
 )
@@ -15,7 +25,11 @@ import (
 // * Kita membuat sebuah struct bernama UserController, yang memiliki satu property bernama DB. (Property adalah variabel yang terdapat dalam sebuah struct).
 // & Property DB ini adalah pointer ke objek database (gorm.DB), yang akan digunakan untuk mengakses database.
 type UserController struct {
-	DB *gorm.DB
+	DB            *gorm.DB
+	Audit         *audit.Recorder
+	Preconditions etag.Preconditions // If-Match untuk update / delete
+	Deletion      *service.AccountDeletion
+	Verifier      *authService.EmailVerifier // link verifikasi untuk email baru
 }
 
 //^ Bayangkan UserController ini seperti seorang kasir toko.
@@ -25,49 +39,68 @@ type UserController struct {
 
 // *  Fungsi NewUserController adalah "constructor"
 // Constructor ini digunakan untuk membuat objek UserController dengan database yang bisa disesuaikan.
-func NewUserController(db *gorm.DB) *UserController {
-	return &UserController{DB: db}
+func NewUserController(db *gorm.DB, recorder *audit.Recorder, preconditions etag.Preconditions, deletion *service.AccountDeletion, verifier *authService.EmailVerifier) *UserController {
+	return &UserController{DB: db, Audit: recorder, Preconditions: preconditions, Deletion: deletion, Verifier: verifier}
 }
 
 // 1. Saat Anda mempekerjakan kasir baru (UserController), Anda harus memberi mereka akses ke database toko (DB).
 // 2. NewUserController(db) adalah cara memberi kasir akses ke database saat mereka mulai bekerja.
 
+// usersQuery adalah whitelist sort / filter / pencarian untuk GET /api/users
+var usersQuery = query.Spec{
+	Key: "id",
+	Sorts: map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"user_name":  "user_name",
+		"email":      "email",
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]query.Filter{
+		"role":           {Column: "role", Op: query.Eq},
+		"email":          {Column: "email", Op: query.Prefix},
+		"created_after":  {Column: "created_at", Op: query.Gte, Parse: query.ParseTime},
+		"created_before": {Column: "created_at", Op: query.Lte, Parse: query.ParseTime},
+	},
+	Search: []string{"user_name"},
+}
+
 // GET all users
+// ?page=&per_page= | ?cursor=, ?sort=-created_at, ?role=, ?email=<prefix>, ?created_after=, ?q=<nama>
 func (uc *UserController) GetUsers(c *fiber.Ctx) error {
+	params, err := query.Parse(c, usersQuery)
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("%s", err.Error())
+	}
+
 	var users []models.UserModel
-	if err := uc.DB.Find(&users).Error; err != nil {
-		log.Println("[ERROR] Failed to fetch users:", err)
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to retrieve users"})
+	meta, err := params.Find(uc.DB.Model(&models.UserModel{}), &users)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("fetch users: %w", err))
 	}
 
 	log.Printf("[SUCCESS] Retrieved %d users\n", len(users))
-	return c.JSON(fiber.Map{
-		"message": "Users fetched successfully",
-		"total":   len(users),
-		"data":    users,
-	})
+	return c.JSON(query.Response("Users fetched successfully", dto.ToUserAdminList(users), meta))
 }
 
 // GET user by ID
 func (uc *UserController) GetProfile(c *fiber.Ctx) error {
-	userIDStr, ok := c.Locals("user_id").(string)
+	userID, ok := authMw.UserID(c)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
 	}
 
 	var user models.UserModel
-	if err := uc.DB.First(&user, userID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	if err := uc.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return userLookupError(err)
+	}
+	if etag.NotModified(c, etag.Tag(user.ID, user.Version)) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
 
 	return c.JSON(fiber.Map{
 		"message": "User profile fetched successfully",
-		"data":    user,
+		"data":    dto.ToUserSelf(&user),
 	})
 }
 
@@ -79,29 +112,27 @@ func (uc *UserController) CreateUser(c *fiber.Ctx) error {
 	// Coba parse sebagai array terlebih dahulu
 	if err := c.BodyParser(&multipleUsers); err == nil && len(multipleUsers) > 0 {
 		if err := uc.DB.Create(&multipleUsers).Error; err != nil {
-			log.Println("[ERROR] Failed to create multiple users:", err)
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to create multiple users"})
+			return apperror.Internal(fmt.Errorf("create multiple users: %w", err))
 		}
 		return c.Status(201).JSON(fiber.Map{
 			"message": "Users created successfully",
-			"data":    multipleUsers,
+			"data":    dto.ToUserAdminList(multipleUsers),
 		})
 	}
 
 	// Jika gagal diparse sebagai array, parse sebagai satu user
 	if err := c.BodyParser(&singleUser); err != nil {
 		log.Println("[ERROR] Invalid input format:", err)
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid input format"})
+		return apperror.BadRequest(apperror.CodeInvalidBody)
 	}
 
 	if err := uc.DB.Create(&singleUser).Error; err != nil {
-		log.Println("[ERROR] Failed to create user:", err)
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create user"})
+		return apperror.Internal(fmt.Errorf("create user: %w", err))
 	}
 
 	return c.Status(201).JSON(fiber.Map{
 		"message": "User created successfully",
-		"data":    singleUser,
+		"data":    dto.ToUserAdmin(&singleUser),
 	})
 }
 
@@ -115,71 +146,140 @@ type UpdateUserInput struct {
 
 // UpdateProfile - Update user dari token
 func (uc *UserController) UpdateProfile(c *fiber.Ctx) error {
-	userIDRaw := c.Locals("user_id")
-	if userIDRaw == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-
-	userIDStr, ok := userIDRaw.(string)
+	userID, ok := authMw.UserID(c)
 	if !ok {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Invalid user ID in token"})
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID format"})
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
 	}
 
 	var user models.UserModel
 	if err := uc.DB.First(&user, "id = ?", userID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		return userLookupError(err)
+	}
+	if err := uc.Preconditions.Check(c, etag.Tag(user.ID, user.Version)); err != nil {
+		return err
 	}
 
 	var input UpdateUserInput
 	if err := c.BodyParser(&input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		return apperror.BadRequest(apperror.CodeInvalidBody)
 	}
 
-	// Validasi input manual atau pakai validator.v10
-	validate := validator.New()
-	if err := validate.Struct(&input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	// Validasi dengan validator bersama (pesan per field sesuai Accept-Language)
+	if err := apperror.Validate(&input); err != nil {
+		return err
 	}
 
 	// Update field yang diizinkan
 	user.UserName = input.UserName
+	emailChanged := !strings.EqualFold(user.Email, input.Email)
+	if emailChanged {
+		// Email baru harus diverifikasi ulang; link dikirim setelah update tersimpan
+		user.EmailVerifiedAt = nil
+	}
 	user.Email = input.Email
 	user.DonationName = input.DonationName
 	user.OriginalName = input.OriginalName
 
-	if err := uc.DB.Save(&user).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update user"})
+	// Hanya kolom yang diubah endpoint ini, dan hanya jika version belum berubah sejak dibaca
+	err := etag.Update(uc.DB, &user, &user.Version, "user_name", "email", "email_verified_at", "donation_name", "original_name")
+	if errors.Is(err, etag.ErrStale) {
+		return etag.PreconditionFailed()
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return apperror.Conflict(apperror.CodeEmailTaken)
+		}
+		return apperror.Internal(fmt.Errorf("update user: %w", err))
+	}
+	c.Set(fiber.HeaderETag, etag.Tag(user.ID, user.Version))
+
+	// 📌 Gagal kirim email tidak menggagalkan update; user bisa minta kirim ulang
+	if emailChanged {
+		if err := uc.Verifier.Send(c.UserContext(), &user); err != nil {
+			log.Printf("[ERROR] Failed to send verification email: UserID=%v: %v", user.ID, err)
+		}
 	}
 
 	return c.JSON(fiber.Map{
 		"message": "User updated successfully",
-		"data": fiber.Map{
-			"id":            user.ID,
-			"user_name":     user.UserName,
-			"email":         user.Email,
-			"donation_name": user.DonationName,
-			"original_name": user.OriginalName,
-			"updated_at":    user.UpdatedAt,
-		},
+		"data":    dto.ToUserSelf(&user),
 	})
 }
 
-// DELETE user by ID
+// DELETE /api/users/me - user menghapus akunnya sendiri.
+// Akun langsung nonaktif (semua sesi dicabut) dan baru dihapus permanen setelah masa tenggang.
+func (uc *UserController) DeleteMe(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+
+	var user models.UserModel
+	if err := uc.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return userLookupError(err)
+	}
+	return uc.scheduleDeletion(c, &user, "Akun dijadwalkan untuk dihapus")
+}
+
+// DELETE user by ID (admin) - sama seperti DeleteMe: soft delete + masa tenggang
 func (uc *UserController) DeleteUser(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	if err := uc.DB.Delete(&models.UserModel{}, id).Error; err != nil {
-		log.Println("[ERROR] Failed to delete user:", err)
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete user"})
+	var user models.UserModel
+	if err := uc.DB.First(&user, "id = ?", id).Error; err != nil {
+		return userLookupError(err)
+	}
+	return uc.scheduleDeletion(c, &user, "User deleted successfully")
+}
+
+// POST /api/users/:id/restore (admin) - memulihkan akun yang masih dalam masa tenggang
+func (uc *UserController) RestoreUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, err := uc.Deletion.FindDeleted(c.UserContext(), "id = ?", id)
+	if err != nil {
+		return userLookupError(err)
+	}
+	err = uc.Deletion.Restore(c.UserContext(), user, audit.FromRequest(c))
+	if errors.Is(err, service.ErrNotRestorable) {
+		return apperror.Conflict(apperror.CodeAccountNotRestorable)
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("restore user: %w", err))
+	}
+
+	log.Printf("[SUCCESS] User with ID %s restored\n", id)
+	return c.JSON(fiber.Map{
+		"message": "User restored successfully",
+		"data":    dto.ToUserAdmin(user),
+	})
+}
+
+// scheduleDeletion memeriksa If-Match lalu menjadwalkan penghapusan akun user
+func (uc *UserController) scheduleDeletion(c *fiber.Ctx, user *models.UserModel, message string) error {
+	if err := uc.Preconditions.Check(c, etag.Tag(user.ID, user.Version)); err != nil {
+		return err
 	}
 
-	log.Printf("[SUCCESS] User with ID %s deleted\n", id)
+	purgeAt, err := uc.Deletion.Schedule(c.UserContext(), user, audit.FromRequest(c))
+	if errors.Is(err, service.ErrStale) {
+		return etag.PreconditionFailed()
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("delete user: %w", err))
+	}
+
+	log.Printf("[SUCCESS] User with ID %s scheduled for deletion at %s\n", user.ID, purgeAt.Format(time.RFC3339))
 	return c.JSON(fiber.Map{
-		"message": "User deleted successfully",
+		"message":  message,
+		"purge_at": purgeAt,
 	})
 }
+
+// userLookupError: user tidak ada -> 404 user_not_found, error lain -> 500
+func userLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeUserNotFound)
+	}
+	return apperror.Internal(fmt.Errorf("cari user: %w", err))
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
+	"masjidku/internals/etag"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	"masjidku/internals/features/users/user/dto"
+	"masjidku/internals/features/users/user/models"
+	"masjidku/internals/features/users/user/service"
+	authMw "masjidku/internals/middlewares/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserModerationController adalah endpoint admin platform untuk role global, suspend dan ban
+type UserModerationController struct {
+	DB            *gorm.DB
+	Moderation    *service.Moderation
+	Policy        *rbacService.Policy
+	Preconditions etag.Preconditions // If-Match terhadap ETag user
+}
+
+func NewUserModerationController(db *gorm.DB, moderation *service.Moderation, policy *rbacService.Policy, preconditions etag.Preconditions) *UserModerationController {
+	return &UserModerationController{DB: db, Moderation: moderation, Policy: policy, Preconditions: preconditions}
+}
+
+type changeRoleInput struct {
+	Role string `json:"role" validate:"required,max=20"`
+}
+
+type suspendInput struct {
+	Until  time.Time `json:"until" validate:"required,gt"` // RFC 3339, harus di masa depan
+	Reason string    `json:"reason" validate:"required,max=255"`
+}
+
+type banInput struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// PUT /api/users/:id/role
+func (mc *UserModerationController) ChangeRole(c *fiber.Ctx) error {
+	var input changeRoleInput
+	if err := parseInput(c, &input); err != nil {
+		return err
+	}
+	if !mc.Policy.RoleExists(input.Role) {
+		return apperror.BadRequest(apperror.CodeRoleNotFound)
+	}
+	return mc.moderate(c, "User role updated successfully", func(user *models.UserModel, entry audit.Entry) error {
+		return mc.Moderation.ChangeRole(c.UserContext(), user, input.Role, entry)
+	})
+}
+
+// POST /api/users/:id/suspend - akun ditolak sampai until dan semua sesinya dicabut
+func (mc *UserModerationController) Suspend(c *fiber.Ctx) error {
+	var input suspendInput
+	if err := parseInput(c, &input); err != nil {
+		return err
+	}
+	return mc.moderate(c, "User suspended successfully", func(user *models.UserModel, entry audit.Entry) error {
+		return mc.Moderation.Suspend(c.UserContext(), user, input.Until, input.Reason, entry)
+	})
+}
+
+// POST /api/users/:id/ban - akun ditolak tanpa batas waktu dan semua sesinya dicabut
+func (mc *UserModerationController) Ban(c *fiber.Ctx) error {
+	var input banInput
+	if err := parseInput(c, &input); err != nil {
+		return err
+	}
+	return mc.moderate(c, "User banned successfully", func(user *models.UserModel, entry audit.Entry) error {
+		return mc.Moderation.Ban(c.UserContext(), user, input.Reason, entry)
+	})
+}
+
+// POST /api/users/:id/reinstate - mencabut suspend dan ban
+func (mc *UserModerationController) Reinstate(c *fiber.Ctx) error {
+	return mc.moderate(c, "User reinstated successfully", func(user *models.UserModel, entry audit.Entry) error {
+		return mc.Moderation.Reinstate(c.UserContext(), user, entry)
+	})
+}
+
+// moderate memuat user pada URL, menolak aksi terhadap akun sendiri, memeriksa If-Match,
+// lalu menjalankan action dan mengirim data user terbaru
+func (mc *UserModerationController) moderate(c *fiber.Ctx, message string, action func(*models.UserModel, audit.Entry) error) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidID)
+	}
+	// Admin tidak bisa menurunkan role atau memblokir dirinya sendiri (mencegah platform tanpa admin)
+	if self, _ := authMw.UserID(c); self == id {
+		return apperror.Forbidden(apperror.CodeCannotModerateSelf)
+	}
+
+	var user models.UserModel
+	if err := mc.DB.First(&user, "id = ?", id).Error; err != nil {
+		return userLookupError(err)
+	}
+	if err := mc.Preconditions.Check(c, etag.Tag(user.ID, user.Version)); err != nil {
+		return err
+	}
+
+	err = action(&user, audit.FromRequest(c))
+	if errors.Is(err, service.ErrStale) {
+		return etag.PreconditionFailed()
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("moderate user: %w", err))
+	}
+	c.Set(fiber.HeaderETag, etag.Tag(user.ID, user.Version))
+
+	log.Printf("[SUCCESS] %s: %s\n", message, user.ID)
+	return c.JSON(fiber.Map{
+		"message": message,
+		"data":    dto.ToUserAdmin(&user),
+	})
+}
+
+// parseInput membaca body JSON lalu memvalidasinya dengan validator bersama
+func parseInput(c *fiber.Ctx, input interface{}) error {
+	if err := c.BodyParser(input); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	return apperror.Validate(input)
+}
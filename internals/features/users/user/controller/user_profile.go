@@ -1,10 +1,19 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
-	"strconv"
+	"strings"
 
+	"masjidku/internals/apperror"
+	"masjidku/internals/etag"
+	"masjidku/internals/features/users/user/dto"
 	"masjidku/internals/features/users/user/models"
+	"masjidku/internals/mergepatch"
+	authMw "masjidku/internals/middlewares/auth"
+	"masjidku/internals/query"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -12,111 +21,318 @@ import (
 )
 
 type UsersProfileController struct {
-	DB *gorm.DB
+	DB            *gorm.DB
+	Preconditions etag.Preconditions // If-Match untuk PUT / PATCH / DELETE
 }
 
-func NewUsersProfileController(db *gorm.DB) *UsersProfileController {
-	return &UsersProfileController{DB: db}
+func NewUsersProfileController(db *gorm.DB, preconditions etag.Preconditions) *UsersProfileController {
+	return &UsersProfileController{DB: db, Preconditions: preconditions}
 }
 
+// profilesQuery adalah whitelist sort / filter / pencarian untuk GET /api/users-profiles
+var profilesQuery = query.Spec{
+	Key: "id",
+	Sorts: map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]query.Filter{
+		"user_id":        {Column: "user_id", Op: query.Eq, Parse: query.ParseUUID},
+		"gender":         {Column: "gender", Op: query.Eq, Parse: query.OneOf(string(models.Male), string(models.Female))},
+		"created_after":  {Column: "created_at", Op: query.Gte, Parse: query.ParseTime},
+		"created_before": {Column: "created_at", Op: query.Lte, Parse: query.ParseTime},
+	},
+	Search: []string{"full_name"},
+}
+
+// GET all profiles
+// ?page=&per_page= | ?cursor=, ?sort=-created_at, ?gender=, ?user_id=, ?created_after=, ?q=<nama>
 func (upc *UsersProfileController) GetProfiles(c *fiber.Ctx) error {
 	log.Println("Fetching all user profiles")
+	params, err := query.Parse(c, profilesQuery)
+	if err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidQuery).WithDetail("%s", err.Error())
+	}
+
 	var profiles []models.UsersProfileModel
-	if err := upc.DB.Find(&profiles).Error; err != nil {
-		log.Println("Error fetching user profiles:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch user profiles"})
+	meta, err := params.Find(upc.DB.Model(&models.UsersProfileModel{}), &profiles)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("fetch user profiles: %w", err))
 	}
-	return c.JSON(profiles)
+	return c.JSON(query.Response("User profiles fetched successfully", dto.ToProfileAdminList(profiles), meta))
 }
 
-func (upc *UsersProfileController) GetProfile(c *fiber.Ctx) error {
-	id := c.Params("id")
-	log.Println("Fetching user profile with ID:", id)
+// =========================== PROFIL SENDIRI (/api/users/me/profile) ===========================
+// Pemilik profil selalu user dari token; user_id di body tidak pernah dipakai.
+
+// GetMyProfile mengembalikan profil milik user yang login
+func (upc *UsersProfileController) GetMyProfile(c *fiber.Ctx) error {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
 	var profile models.UsersProfileModel
-	if err := upc.DB.First(&profile, id).Error; err != nil {
-		log.Println("User profile not found:", err)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User profile not found"})
+	if err := upc.DB.Where("user_id = ?", userID).Order("id").First(&profile).Error; err != nil {
+		return profileLookupError(err)
+	}
+	if etag.NotModified(c, profileTag(&profile)) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
-	return c.JSON(profile)
+	return c.JSON(dto.ToProfileSelf(&profile))
 }
 
-func (upc *UsersProfileController) CreateProfile(c *fiber.Ctx) error {
-	log.Println("Creating or updating user profile")
+// PutMyProfile mengganti seluruh field profil (field yang tidak dikirim dikosongkan);
+// profil dibuat jika belum ada
+func (upc *UsersProfileController) PutMyProfile(c *fiber.Ctx) error {
+	profile, err := upc.myProfile(c)
+	if err != nil {
+		return err
+	}
+	if err := upc.checkPrecondition(c, profile); err != nil {
+		return err
+	}
+	var input dto.ProfileInput
+	if err := decodeJSON(c.Body(), &input); err != nil {
+		return err
+	}
+	return upc.save(c, profile, input, selfView)
+}
+
+// PatchMyProfile mengubah sebagian field profil dengan JSON Merge Patch (RFC 7396):
+// field yang tidak dikirim tetap, null mengosongkan field; profil dibuat jika belum ada
+func (upc *UsersProfileController) PatchMyProfile(c *fiber.Ctx) error {
+	profile, err := upc.myProfile(c)
+	if err != nil {
+		return err
+	}
+	if err := upc.checkPrecondition(c, profile); err != nil {
+		return err
+	}
+	input, err := patchProfile(c, profile)
+	if err != nil {
+		return err
+	}
+	return upc.save(c, profile, input, selfView)
+}
 
-	// Parse request body
-	var input models.UsersProfileModel
-	if err := c.BodyParser(&input); err != nil {
-		log.Println("Invalid request body:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+// myProfile memuat profil user yang login, atau profil baru (belum tersimpan) jika belum ada
+func (upc *UsersProfileController) myProfile(c *fiber.Ctx) (*models.UsersProfileModel, error) {
+	userID, ok := authMw.UserID(c)
+	if !ok {
+		return nil, apperror.Unauthorized(apperror.CodeUnauthorized)
+	}
+	var profile models.UsersProfileModel
+	err := upc.DB.Where("user_id = ?", userID).Order("id").First(&profile).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &models.UsersProfileModel{UserID: userID}, nil
+	case err != nil:
+		return nil, apperror.Internal(fmt.Errorf("cari user profile: %w", err))
 	}
+	return &profile, nil
+}
+
+// =========================== ADMIN (/api/users-profiles/:id) ===========================
 
-	// Validasi wajib user_id
-	if input.UserID == uuid.Nil {
-		log.Println("Missing user_id")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "user_id is required"})
+func (upc *UsersProfileController) GetProfile(c *fiber.Ctx) error {
+	profile, err := upc.profileByID(c)
+	if err != nil {
+		return err
+	}
+	if etag.NotModified(c, profileTag(profile)) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
+	return c.JSON(dto.ToProfileAdmin(profile))
+}
 
-	// Cari apakah user_id sudah ada di database
-	var existingProfile models.UsersProfileModel
-	result := upc.DB.Where("user_id = ?", input.UserID).First(&existingProfile)
+// profileCreateInput adalah body POST /api/users-profiles (admin membuat profil untuk user lain)
+type profileCreateInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	dto.ProfileInput
+}
 
-	if result.RowsAffected > 0 {
-		// Update profil yang sudah ada
-		if err := upc.DB.Model(&existingProfile).Updates(input).Error; err != nil {
-			log.Println("Error updating user profile:", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update user profile"})
-		}
-		log.Println("User profile updated:", input.UserID)
-		return c.JSON(existingProfile)
+// CreateProfile membuat profil untuk user_id di body; jika user sudah punya profil, profil itu diganti
+func (upc *UsersProfileController) CreateProfile(c *fiber.Ctx) error {
+	var input profileCreateInput
+	if err := decodeJSON(c.Body(), &input); err != nil {
+		return err
+	}
+	if err := apperror.Validate(&input); err != nil {
+		return err
 	}
 
-	// Buat profil baru jika user_id belum ada
-	if err := upc.DB.Create(&input).Error; err != nil {
-		log.Println("Error creating user profile:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create user profile"})
+	var user models.UserModel
+	if err := upc.DB.Select("id").First(&user, "id = ?", input.UserID).Error; err != nil {
+		return userLookupError(err)
 	}
 
-	log.Println("User profile created:", input.UserID)
-	return c.Status(fiber.StatusCreated).JSON(input)
+	profile := models.UsersProfileModel{UserID: input.UserID}
+	err := upc.DB.Where("user_id = ?", input.UserID).Order("id").First(&profile).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Internal(fmt.Errorf("cari user profile: %w", err))
+	}
+	if err := upc.checkPrecondition(c, &profile); err != nil {
+		return err
+	}
+	return upc.save(c, &profile, input.ProfileInput, adminView)
 }
 
+// UpdateProfile mengganti seluruh field profil (PUT)
 func (upc *UsersProfileController) UpdateProfile(c *fiber.Ctx) error {
-	id := c.Params("id")
-	log.Println("Updating user profile with ID:", id)
-	var profile models.UsersProfileModel
+	profile, err := upc.profileByID(c)
+	if err != nil {
+		return err
+	}
+	if err := upc.checkPrecondition(c, profile); err != nil {
+		return err
+	}
+	var input dto.ProfileInput
+	if err := decodeJSON(c.Body(), &input); err != nil {
+		return err
+	}
+	return upc.save(c, profile, input, adminView)
+}
 
-	idInt, err := strconv.Atoi(id)
+// PatchProfile mengubah sebagian field profil (JSON Merge Patch)
+func (upc *UsersProfileController) PatchProfile(c *fiber.Ctx) error {
+	profile, err := upc.profileByID(c)
 	if err != nil {
-		log.Println("Invalid ID format:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+		return err
+	}
+	if err := upc.checkPrecondition(c, profile); err != nil {
+		return err
 	}
+	input, err := patchProfile(c, profile)
+	if err != nil {
+		return err
+	}
+	return upc.save(c, profile, input, adminView)
+}
 
-	if err := upc.DB.First(&profile, idInt).Error; err != nil {
-		log.Println("User profile not found:", err)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User profile not found"})
+func (upc *UsersProfileController) DeleteProfile(c *fiber.Ctx) error {
+	profile, err := upc.profileByID(c)
+	if err != nil {
+		return err
+	}
+	if err := upc.Preconditions.Check(c, profileTag(profile)); err != nil {
+		return err
 	}
+	log.Println("Deleting user profile with ID:", profile.ID)
+
+	err = etag.Delete(upc.DB, profile, profile.Version)
+	if errors.Is(err, etag.ErrStale) {
+		return etag.PreconditionFailed()
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("delete user profile: %w", err))
+	}
+	return c.JSON(fiber.Map{"message": "User profile deleted successfully"})
+}
 
-	if err := c.BodyParser(&profile); err != nil {
-		log.Println("Invalid request body:", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+func (upc *UsersProfileController) profileByID(c *fiber.Ctx) (*models.UsersProfileModel, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return nil, apperror.BadRequest(apperror.CodeInvalidID)
+	}
+	var profile models.UsersProfileModel
+	if err := upc.DB.First(&profile, id).Error; err != nil {
+		return nil, profileLookupError(err)
 	}
+	return &profile, nil
+}
 
-	profile.ID = uint(idInt) // Pastikan ID tidak berubah dari request body
+// =========================== HELPER ===========================
 
-	if err := upc.DB.Save(&profile).Error; err != nil {
-		log.Println("Error updating user profile:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update user profile"})
+// save memvalidasi input, menyalinnya ke profil lalu menyimpan (201 jika profil baru)
+func (upc *UsersProfileController) save(c *fiber.Ctx, profile *models.UsersProfileModel, input dto.ProfileInput, view func(*models.UsersProfileModel) interface{}) error {
+	if err := apperror.Validate(&input); err != nil {
+		return err
+	}
+	input.ApplyTo(profile)
+
+	created := profile.ID == 0
+	var err error
+	if created {
+		profile.Version = 1
+		err = upc.DB.Create(profile).Error
+	} else {
+		err = etag.Update(upc.DB, profile, &profile.Version, profileColumns...)
+	}
+	if errors.Is(err, etag.ErrStale) {
+		return etag.PreconditionFailed()
+	}
+	// ux_users_profile_user_id: request lain sudah lebih dulu membuat profil user ini
+	if err != nil && created && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return apperror.Conflict(apperror.CodeConflict).WithDetail("User sudah punya profil")
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("simpan user profile: %w", err))
 	}
-	return c.JSON(profile)
+	c.Set(fiber.HeaderETag, profileTag(profile))
+	if created {
+		log.Println("User profile created:", profile.UserID)
+		return c.Status(fiber.StatusCreated).JSON(view(profile))
+	}
+	log.Println("User profile updated:", profile.UserID)
+	return c.JSON(view(profile))
 }
 
-func (upc *UsersProfileController) DeleteProfile(c *fiber.Ctx) error {
-	id := c.Params("id")
-	log.Println("Deleting user profile with ID:", id)
+// profileColumns adalah kolom yang diisi dari ProfileInput
+var profileColumns = []string{
+	"donation_name", "full_name", "date_of_birth", "gender",
+	"phone_number", "bio", "location", "occupation",
+}
 
-	if err := upc.DB.Delete(&models.UsersProfileModel{}, id).Error; err != nil {
-		log.Println("Error deleting user profile:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete user profile"})
+func profileTag(p *models.UsersProfileModel) string {
+	return etag.Tag(p.ID, p.Version)
+}
+
+// checkPrecondition memeriksa If-Match; profil yang belum ada tidak punya ETag untuk dicocokkan
+func (upc *UsersProfileController) checkPrecondition(c *fiber.Ctx, p *models.UsersProfileModel) error {
+	if p.ID == 0 {
+		return upc.Preconditions.CheckNew(c)
 	}
-	return c.JSON(fiber.Map{"message": "User profile deleted successfully"})
+	return upc.Preconditions.Check(c, profileTag(p))
+}
+
+func selfView(p *models.UsersProfileModel) interface{}  { return dto.ToProfileSelf(p) }
+func adminView(p *models.UsersProfileModel) interface{} { return dto.ToProfileAdmin(p) }
+
+// patchProfile menerapkan body JSON Merge Patch ke kondisi profil saat ini.
+// Content-Type application/merge-patch+json atau application/json.
+func patchProfile(c *fiber.Ctx, profile *models.UsersProfileModel) (dto.ProfileInput, error) {
+	var input dto.ProfileInput
+	contentType := string(c.Request().Header.ContentType())
+	if !mergepatch.IsMergePatch(contentType) && !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		return input, apperror.New(fiber.StatusUnsupportedMediaType, apperror.CodeUnsupportedMedia).
+			WithDetail("Gunakan Content-Type %s", mergepatch.MIMEType)
+	}
+
+	current, err := json.Marshal(dto.ToProfileInput(profile))
+	if err != nil {
+		return input, apperror.Internal(err)
+	}
+	patched, err := mergepatch.Apply(current, c.Body())
+	if err != nil {
+		return input, apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	return input, decodeJSON(patched, &input)
+}
+
+// decodeJSON membaca body JSON (objek) ke dest; field yang tidak dikenal (mis. user_id) diabaikan
+func decodeJSON(body []byte, dest interface{}) error {
+	if err := json.Unmarshal(body, dest); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidBody)
+	}
+	return nil
+}
+
+// profileLookupError: profil tidak ada -> 404 profile_not_found, error lain -> 500
+func profileLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeProfileNotFound)
+	}
+	return apperror.Internal(fmt.Errorf("cari user profile: %w", err))
 }
@@ -0,0 +1,156 @@
+package dto
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/features/users/user/models"
+)
+
+// sensitiveFields mengembalikan nama field JSON bertag `sensitive:"true"` pada model
+func sensitiveFields(t *testing.T, models ...interface{}) map[string]bool {
+	t.Helper()
+	fields := map[string]bool{}
+	for _, m := range models {
+		typ := reflect.TypeOf(m)
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.Tag.Get("sensitive") != "true" {
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				name = f.Name
+			}
+			fields[name] = true
+		}
+	}
+	if len(fields) == 0 {
+		t.Fatal("tidak ada field bertag sensitive di model; tag terhapus?")
+	}
+	return fields
+}
+
+// findKey mencari key JSON (di level mana pun) yang termasuk keys
+func findKey(v interface{}, keys map[string]bool) (string, bool) {
+	switch doc := v.(type) {
+	case map[string]interface{}:
+		for k, child := range doc {
+			if keys[k] {
+				return k, true
+			}
+			if key, found := findKey(child, keys); found {
+				return key, true
+			}
+		}
+	case []interface{}:
+		for _, child := range doc {
+			if key, found := findKey(child, keys); found {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+func fullUser() *models.UserModel {
+	now := time.Now()
+	googleID := "google-123"
+	name := "Fulan"
+	return &models.UserModel{
+		ID:                uuid.New(),
+		UserName:          "fulan",
+		Email:             "fulan@example.com",
+		Password:          "$2a$10$hashpasswordyangtidakbolehbocor",
+		GoogleID:          &googleID,
+		Role:              "user",
+		SecurityQuestion:  "Nama masjid pertama?",
+		SecurityAnswer:    "rahasia",
+		DonationName:      &name,
+		OriginalName:      &name,
+		EmailVerifiedAt:   &now,
+		SessionsRevokedAt: &now,
+		LockedUntil:       &now,
+		SuspendedUntil:    &now,
+		SuspensionReason:  "spam",
+		BannedAt:          &now,
+		BanReason:         "spam",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+func fullProfile() *models.UsersProfileModel {
+	now := time.Now()
+	dob := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	return &models.UsersProfileModel{
+		ID:           1,
+		UserID:       uuid.New(),
+		DonationName: "Fulan",
+		FullName:     "Fulan bin Fulan",
+		DateOfBirth:  &dob,
+		Gender:       "male",
+		PhoneNumber:  "+6281234567890",
+		Bio:          "bio",
+		Location:     "Bandung",
+		Occupation:   "guru",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		DeletedAt:    gorm.DeletedAt{Time: now, Valid: true},
+	}
+}
+
+// TestResponsesHaveNoSensitiveFields: semua DTO yang dikirim handler user / profil / auth
+// tidak boleh menyerialisasi field model yang bertag sensitive (hash password, jawaban keamanan)
+func TestResponsesHaveNoSensitiveFields(t *testing.T) {
+	keys := sensitiveFields(t, models.UserModel{}, models.UsersProfileModel{})
+
+	user, profile := fullUser(), fullProfile()
+	responses := map[string]interface{}{
+		"UserPublic":       ToUserPublic(user),
+		"UserSelf":         ToUserSelf(user),
+		"UserAdmin":        ToUserAdmin(user),
+		"UserAdminList":    ToUserAdminList([]models.UserModel{*user}),
+		"ProfilePublic":    ToProfilePublic(profile),
+		"ProfileSelf":      ToProfileSelf(profile),
+		"ProfileAdmin":     ToProfileAdmin(profile),
+		"ProfileAdminList": ToProfileAdminList([]models.UsersProfileModel{*profile}),
+		"ProfileInput":     ToProfileInput(profile),
+	}
+	for name, resp := range responses {
+		t.Run(name, func(t *testing.T) {
+			raw, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			var doc interface{}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if key, found := findKey(doc, keys); found {
+				t.Errorf("response berisi field sensitif %q: %s", key, raw)
+			}
+			for _, secret := range []string{user.Password, user.SecurityAnswer} {
+				if strings.Contains(string(raw), secret) {
+					t.Errorf("response berisi nilai rahasia %q: %s", secret, raw)
+				}
+			}
+		})
+	}
+}
+
+// TestSensitiveFieldsStillTagged menjaga agar test di atas tidak lolos karena tag hilang dari model
+func TestSensitiveFieldsStillTagged(t *testing.T) {
+	keys := sensitiveFields(t, models.UserModel{})
+	for _, name := range []string{"password", "security_answer"} {
+		if !keys[name] {
+			t.Errorf("field %q di UserModel harus bertag sensitive:\"true\"", name)
+		}
+	}
+}
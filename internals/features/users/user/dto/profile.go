@@ -0,0 +1,155 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/features/users/user/models"
+)
+
+// ProfilePublic adalah profil yang boleh dilihat user lain (tanpa tanggal lahir & nomor HP)
+type ProfilePublic struct {
+	UserID       uuid.UUID     `json:"user_id"`
+	FullName     string        `json:"full_name"`
+	DonationName string        `json:"donation_name"`
+	Gender       models.Gender `json:"gender,omitempty"`
+	Bio          string        `json:"bio"`
+	Location     string        `json:"location"`
+	Occupation   string        `json:"occupation"`
+}
+
+// ProfileSelf adalah profil lengkap untuk pemiliknya sendiri
+type ProfileSelf struct {
+	ID uint `json:"id"`
+	ProfilePublic
+	DateOfBirth *string   `json:"date_of_birth"` // YYYY-MM-DD
+	PhoneNumber string    `json:"phone_number"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProfileAdmin adalah profil untuk admin platform (termasuk status soft delete)
+type ProfileAdmin struct {
+	ProfileSelf
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func ToProfilePublic(p *models.UsersProfileModel) ProfilePublic {
+	return ProfilePublic{
+		UserID:       p.UserID,
+		FullName:     p.FullName,
+		DonationName: p.DonationName,
+		Gender:       p.Gender,
+		Bio:          p.Bio,
+		Location:     p.Location,
+		Occupation:   p.Occupation,
+	}
+}
+
+func ToProfileSelf(p *models.UsersProfileModel) ProfileSelf {
+	out := ProfileSelf{
+		ID:            p.ID,
+		ProfilePublic: ToProfilePublic(p),
+		PhoneNumber:   p.PhoneNumber,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+	if p.DateOfBirth != nil {
+		dob := p.DateOfBirth.Format("2006-01-02")
+		out.DateOfBirth = &dob
+	}
+	return out
+}
+
+func ToProfileAdmin(p *models.UsersProfileModel) ProfileAdmin {
+	out := ProfileAdmin{ProfileSelf: ToProfileSelf(p)}
+	if p.DeletedAt.Valid {
+		out.DeletedAt = &p.DeletedAt.Time
+	}
+	return out
+}
+
+func ToProfileAdminList(profiles []models.UsersProfileModel) []ProfileAdmin {
+	out := make([]ProfileAdmin, len(profiles))
+	for i := range profiles {
+		out[i] = ToProfileAdmin(&profiles[i])
+	}
+	return out
+}
+
+// ProfileInput adalah field profil yang boleh diubah pemiliknya lewat PUT / PATCH.
+// id dan user_id sengaja tidak ada: pemilik profil selalu diambil dari token / URL.
+type ProfileInput struct {
+	DonationName string        `json:"donation_name" validate:"max=50"`
+	FullName     string        `json:"full_name" validate:"max=50"`
+	DateOfBirth  *string       `json:"date_of_birth" validate:"omitempty,date_of_birth"` // YYYY-MM-DD
+	Gender       models.Gender `json:"gender" validate:"omitempty,oneof=male female"`
+	PhoneNumber  string        `json:"phone_number" validate:"omitempty,phone_number"`
+	Bio          string        `json:"bio" validate:"max=300"`
+	Location     string        `json:"location" validate:"max=50"`
+	Occupation   string        `json:"occupation" validate:"max=20"`
+}
+
+func init() {
+	must := func(err error) {
+		if err != nil {
+			panic(err)
+		}
+	}
+	must(apperror.RegisterValidation("phone_number", func(fl validator.FieldLevel) bool {
+		_, ok := models.NormalizePhoneNumber(fl.Field().String())
+		return ok
+	}, map[string]string{
+		"id": "{0} harus nomor telepon format E.164 (+6281234567890) atau nomor Indonesia (081234567890)",
+		"en": "{0} must be a phone number in E.164 (+6281234567890) or Indonesian (081234567890) format",
+	}))
+	must(apperror.RegisterValidation("date_of_birth", func(fl validator.FieldLevel) bool {
+		if fl.Field().String() == "" {
+			return true // "" sama dengan null: tanggal lahir dikosongkan
+		}
+		_, ok := models.ParseDateOfBirth(fl.Field().String())
+		return ok
+	}, map[string]string{
+		"id": "{0} harus tanggal YYYY-MM-DD antara 1900-01-01 dan hari ini",
+		"en": "{0} must be a YYYY-MM-DD date between 1900-01-01 and today",
+	}))
+}
+
+// ToProfileInput adalah kondisi profil saat ini, dasar untuk JSON Merge Patch
+func ToProfileInput(p *models.UsersProfileModel) ProfileInput {
+	self := ToProfileSelf(p)
+	return ProfileInput{
+		DonationName: p.DonationName,
+		FullName:     p.FullName,
+		DateOfBirth:  self.DateOfBirth,
+		Gender:       p.Gender,
+		PhoneNumber:  p.PhoneNumber,
+		Bio:          p.Bio,
+		Location:     p.Location,
+		Occupation:   p.Occupation,
+	}
+}
+
+// ApplyTo menyalin input yang sudah divalidasi ke model (nomor HP disimpan dalam bentuk E.164)
+func (in ProfileInput) ApplyTo(p *models.UsersProfileModel) {
+	p.DonationName = in.DonationName
+	p.FullName = in.FullName
+	p.Gender = in.Gender
+	p.Bio = in.Bio
+	p.Location = in.Location
+	p.Occupation = in.Occupation
+
+	p.DateOfBirth = nil
+	if in.DateOfBirth != nil {
+		if dob, ok := models.ParseDateOfBirth(*in.DateOfBirth); ok {
+			p.DateOfBirth = &dob
+		}
+	}
+	p.PhoneNumber = ""
+	if phone, ok := models.NormalizePhoneNumber(in.PhoneNumber); ok {
+		p.PhoneNumber = phone
+	}
+}
@@ -0,0 +1,86 @@
+// Package dto berisi bentuk response untuk user & profil. Controller tidak pernah
+// mengirim model GORM apa adanya; selalu lewat mapper di sini sesuai siapa yang melihat:
+//   - Public: dilihat user lain (tanpa email / data pribadi)
+//   - Self:   dilihat pemilik akun
+//   - Admin:  dilihat admin platform (ditambah status keamanan akun)
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"masjidku/internals/features/users/user/models"
+)
+
+// UserPublic adalah data user yang boleh dilihat user lain
+type UserPublic struct {
+	ID       uuid.UUID `json:"id"`
+	UserName string    `json:"user_name"`
+}
+
+// UserSelf adalah data akun untuk pemiliknya sendiri
+type UserSelf struct {
+	ID            uuid.UUID `json:"id"`
+	UserName      string    `json:"user_name"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	GoogleLinked  bool      `json:"google_linked"`
+	DonationName  *string   `json:"donation_name,omitempty"`
+	OriginalName  *string   `json:"original_name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// UserAdmin adalah data user untuk admin platform
+type UserAdmin struct {
+	UserSelf
+	EmailVerifiedAt   *time.Time `json:"email_verified_at,omitempty"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at,omitempty"`
+	SuspendedUntil    *time.Time `json:"suspended_until,omitempty"`
+	SuspensionReason  string     `json:"suspension_reason,omitempty"`
+	BannedAt          *time.Time `json:"banned_at,omitempty"`
+	BanReason         string     `json:"ban_reason,omitempty"`
+}
+
+func ToUserPublic(u *models.UserModel) UserPublic {
+	return UserPublic{ID: u.ID, UserName: u.UserName}
+}
+
+func ToUserSelf(u *models.UserModel) UserSelf {
+	return UserSelf{
+		ID:            u.ID,
+		UserName:      u.UserName,
+		Email:         u.Email,
+		Role:          u.Role,
+		EmailVerified: u.EmailVerifiedAt != nil,
+		GoogleLinked:  u.GoogleID != nil,
+		DonationName:  u.DonationName,
+		OriginalName:  u.OriginalName,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+	}
+}
+
+func ToUserAdmin(u *models.UserModel) UserAdmin {
+	return UserAdmin{
+		UserSelf:          ToUserSelf(u),
+		EmailVerifiedAt:   u.EmailVerifiedAt,
+		LockedUntil:       u.LockedUntil,
+		SessionsRevokedAt: u.SessionsRevokedAt,
+		SuspendedUntil:    u.SuspendedUntil,
+		SuspensionReason:  u.SuspensionReason,
+		BannedAt:          u.BannedAt,
+		BanReason:         u.BanReason,
+	}
+}
+
+func ToUserAdminList(users []models.UserModel) []UserAdmin {
+	out := make([]UserAdmin, len(users))
+	for i := range users {
+		out[i] = ToUserAdmin(&users[i])
+	}
+	return out
+}
@@ -1,31 +1,38 @@
 package models
 
 import (
-	"errors"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	// "gorm.io/gorm"
-)
+	"gorm.io/gorm"
 
-// Validator instance
-var validate = validator.New()
+	"masjidku/internals/apperror"
+)
 
 // UserModel merepresentasikan tabel users di database
 type UserModel struct {
-	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	UserName         string    `gorm:"size:50;not null" json:"user_name" validate:"required,min=3,max=50"`
-	Email            string    `gorm:"size:255;unique;not null" json:"email" validate:"required,email"`
-	Password         string    `gorm:"not null" json:"password" validate:"required,min=8"`
-	GoogleID         *string   `gorm:"size:255;unique" json:"google_id,omitempty"`
-	Role             string    `gorm:"type:varchar(20);not null;default:'user'" json:"role" validate:"required,oneof=owner user teacher treasurer admin"`
-	SecurityQuestion string    `gorm:"not null" json:"security_question"`
-	SecurityAnswer   string    `gorm:"size:255;not null" json:"security_answer"`
-	DonationName     *string   `gorm:"size:100" json:"donation_name,omitempty"` // ✅ Ditambahkan
-	OriginalName     *string   `gorm:"size:100" json:"original_name,omitempty"` // ✅ Ditambahkan
-	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserName          string         `gorm:"size:50;not null" json:"user_name" validate:"required,min=3,max=50"`
+	Email             string         `gorm:"size:255;unique;not null" json:"email" validate:"required,email"`
+	Password          string         `gorm:"not null" json:"password" validate:"required,min=8" sensitive:"true"` // hanya untuk input, lihat dto
+	GoogleID          *string        `gorm:"size:255;unique" json:"google_id,omitempty"`
+	Role              string         `gorm:"type:varchar(20);not null;default:'user'" json:"role" validate:"required,oneof=user teacher treasurer staff owner admin"` // lihat constants.Role*
+	SecurityQuestion  string         `gorm:"not null" json:"security_question"`
+	SecurityAnswer    string         `gorm:"size:255;not null" json:"security_answer" sensitive:"true"`
+	DonationName      *string        `gorm:"size:100" json:"donation_name,omitempty"` // ✅ Ditambahkan
+	OriginalName      *string        `gorm:"size:100" json:"original_name,omitempty"` // ✅ Ditambahkan
+	EmailVerifiedAt   *time.Time     `json:"email_verified_at,omitempty"`
+	SessionsRevokedAt *time.Time     `json:"-"`                           // access token dengan iat sebelum waktu ini ditolak
+	LockedUntil       *time.Time     `json:"-"`                           // dikunci sementara karena terlalu banyak login gagal
+	Version           int64          `gorm:"not null;default:1" json:"-"` // optimistic locking, lihat package etag
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`              // akun dihapus (masa tenggang), lihat service.AccountDeletion
+	PurgeAt           *time.Time     `json:"-"`                           // setelah waktu ini akun dihapus permanen
+	SuspendedUntil    *time.Time     `json:"-"`                           // disuspend admin sampai waktu ini, lihat Blocked
+	SuspensionReason  string         `gorm:"size:255" json:"-"`           // ditampilkan ke user saat ditolak
+	BannedAt          *time.Time     `json:"-"`                           // di-ban admin tanpa batas waktu
+	BanReason         string         `gorm:"size:255" json:"-"`           // ditampilkan ke user saat ditolak
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 // TableName memastikan nama tabel sesuai dengan skema database
@@ -37,53 +44,32 @@ func (UserModel) TableName() string {
 func (u *UserModel) SetDefaultValues() {
 	if u.Role == "" {
 		u.Role = "user"
-	}  
+	}
 }
 
-// Validate memeriksa apakah input sesuai aturan yang telah didefinisikan
+// Validate memeriksa apakah input sesuai aturan yang telah didefinisikan.
+// Error berupa *apperror.Error validation_failed dengan pesan per field (id / en).
 func (u *UserModel) Validate() error {
 	// Set default role sebelum validasi
 	u.SetDefaultValues()
 
-	err := validate.Struct(u)
-	if err != nil {
-		return formatValidationError(err)
-	}
-
-	return nil
+	return apperror.Validate(u)
 }
 
-// formatValidationError mengubah error validasi menjadi format yang lebih jelas
-func formatValidationError(err error) error {
-	if validationErrs, ok := err.(validator.ValidationErrors); ok {
-		errorMessages := make(map[string]string)
-		for _, fieldErr := range validationErrs {
-			switch fieldErr.Tag() {
-			case "required":
-				errorMessages[fieldErr.Field()] = fieldErr.Field() + " wajib diisi."
-			case "email":
-				errorMessages[fieldErr.Field()] = "Format email tidak valid."
-			case "min":
-				errorMessages[fieldErr.Field()] = fieldErr.Field() + " harus minimal " + fieldErr.Param() + " karakter."
-			case "max":
-				errorMessages[fieldErr.Field()] = fieldErr.Field() + " harus kurang dari " + fieldErr.Param() + " karakter."
-			case "oneof":
-				errorMessages[fieldErr.Field()] = fieldErr.Field() + " harus salah satu dari " + fieldErr.Param() + "."
-			default:
-				errorMessages[fieldErr.Field()] = "Format tidak valid."
-			}
-		}
-		return errors.New(formatErrorMessage(errorMessages))
-	}
-
-	return err
+// Blocked bernilai true jika akun di-ban atau masih disuspend pada waktu now
+func (u *UserModel) Blocked(now time.Time) bool {
+	return u.BannedAt != nil || (u.SuspendedUntil != nil && u.SuspendedUntil.After(now))
 }
 
-// formatErrorMessage mengubah map error menjadi string JSON-like
-func formatErrorMessage(errors map[string]string) string {
-	var msg string
-	for field, errorMsg := range errors {
-		msg += field + ": " + errorMsg + "\n"
+// BlockedError adalah error 403 untuk akun yang di-ban / disuspend (nil jika tidak diblokir).
+// Dipakai di semua jalur login, refresh token dan AuthMiddleware.
+func (u *UserModel) BlockedError(now time.Time) error {
+	switch {
+	case u.BannedAt != nil:
+		return apperror.Forbidden(apperror.CodeAccountBanned).With("reason", u.BanReason)
+	case u.Blocked(now):
+		return apperror.Forbidden(apperror.CodeAccountSuspended).
+			With("suspended_until", u.SuspendedUntil.UTC()).With("reason", u.SuspensionReason)
 	}
-	return msg
+	return nil
 }
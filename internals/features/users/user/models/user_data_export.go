@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status ekspor data user
+const (
+	ExportStatusPending = "pending" // arsip sedang disusun
+	ExportStatusReady   = "ready"   // arsip bisa diunduh sampai ExpiresAt
+	ExportStatusFailed  = "failed"
+	ExportStatusExpired = "expired" // arsip sudah dihapus dari storage
+)
+
+// UserDataExportModel adalah satu permintaan ekspor data (POST /api/users/me/export)
+type UserDataExportModel struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	Status        string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	FileKey       string     `gorm:"size:255" json:"-"` // key di storage, lihat package storage
+	SizeBytes     int64      `gorm:"not null;default:0" json:"size_bytes"`
+	FailureReason string     `gorm:"size:255" json:"-"` // hanya untuk log / admin
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName memastikan nama tabel sesuai dengan skema database
+func (UserDataExportModel) TableName() string {
+	return "user_data_exports"
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +18,29 @@ const (
 	Female Gender = "female"
 )
 
+// Value menyimpan gender kosong sebagai NULL (kolom gender punya CHECK male/female)
+func (g Gender) Value() (driver.Value, error) {
+	if g == "" {
+		return nil, nil
+	}
+	return string(g), nil
+}
+
+// Scan membaca gender; NULL menjadi ""
+func (g *Gender) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*g = ""
+	case string:
+		*g = Gender(v)
+	case []byte:
+		*g = Gender(v)
+	default:
+		return fmt.Errorf("gender: tipe %T tidak didukung", value)
+	}
+	return nil
+}
+
 type UsersProfileModel struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
 	UserID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
@@ -25,6 +52,7 @@ type UsersProfileModel struct {
 	Bio          string         `gorm:"size:300" json:"bio"`
 	Location     string         `gorm:"size:50" json:"location"`
 	Occupation   string         `gorm:"size:20" json:"occupation"`
+	Version      int64          `gorm:"not null;default:1" json:"-"` // optimistic locking, lihat package etag
 	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -34,3 +62,41 @@ type UsersProfileModel struct {
 func (UsersProfileModel) TableName() string {
 	return "users_profile"
 }
+
+var (
+	e164Pattern    = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	idPhonePattern = regexp.MustCompile(`^(?:\+62|62|0)[1-9][0-9]{7,12}$`)
+	phoneSeparator = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+)
+
+// NormalizePhoneNumber menerima format E.164 (+<kode negara><nomor>) atau nomor Indonesia
+// (08xx, 628xx, +628xx, boleh dengan spasi / tanda hubung) dan mengembalikan bentuk E.164.
+// ok=false jika nomor tidak valid.
+func NormalizePhoneNumber(raw string) (string, bool) {
+	s := phoneSeparator.Replace(strings.TrimSpace(raw))
+	switch {
+	case idPhonePattern.MatchString(s):
+		switch {
+		case strings.HasPrefix(s, "0"):
+			s = "+62" + s[1:]
+		case strings.HasPrefix(s, "62"):
+			s = "+" + s
+		}
+		return s, true
+	case e164Pattern.MatchString(s):
+		return s, true
+	}
+	return "", false
+}
+
+// minDateOfBirth: tanggal lahir sebelum ini hampir pasti salah input
+var minDateOfBirth = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ParseDateOfBirth membaca YYYY-MM-DD; tanggal di masa depan atau sebelum 1900 ditolak
+func ParseDateOfBirth(raw string) (time.Time, bool) {
+	dob, err := time.Parse("2006-01-02", raw)
+	if err != nil || dob.Before(minDateOfBirth) || dob.After(time.Now()) {
+		return time.Time{}, false
+	}
+	return dob, true
+}
@@ -1,33 +1,73 @@
 package route
 
 import (
-	authController "masjidku/internals/middlewares/auth"
+	"masjidku/internals/audit"
+	"masjidku/internals/configs"
+	"masjidku/internals/constants"
+	"masjidku/internals/etag"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
 	userController "masjidku/internals/features/users/user/controller"
+	userService "masjidku/internals/features/users/user/service"
+	"masjidku/internals/mailer"
+	authController "masjidku/internals/middlewares/auth"
+	"masjidku/internals/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 // SetupRoutes mengatur routing untuk user & user profile
-func UserRoutes(app *fiber.App, db *gorm.DB) {
+func UserRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy, recorder *audit.Recorder, mail mailer.Mailer, store storage.Storage) {
 
-	// ✅ Group dengan Middleware Auth
-	api := app.Group("/api", authController.AuthMiddleware(db))
+	// ✅ Middleware Auth dipasang per group agar tidak ikut berjalan di route /api milik fitur lain
+	authMiddleware := authController.AuthMiddleware(db, tokens, policy)
+	// If-Match (ETag) untuk update / delete user & profil
+	preconditions := etag.Preconditions{Strict: cfg.App.RequireIfMatch}
 
 	// 🔹 Users
-	userCtrl := userController.NewUserController(db)
-	userRoutes := api.Group("/users")
-	userRoutes.Get("/", userCtrl.GetUsers)
+	deletion := userService.NewAccountDeletion(db, tokens, recorder, cfg.Auth.AccountDeletionGrace)
+	userCtrl := userController.NewUserController(db, recorder, preconditions, deletion, authService.NewEmailVerifier(cfg, mail))
+	userRoutes := app.Group("/api/users", authMiddleware)
+	// 🎭 Admin yang login sebagai user tidak boleh ganti email, hapus akun atau mengambil ekspor data
+	ownerOnly := authController.DenyImpersonation()
+	userRoutes.Get("/", authController.RequirePermission(constants.PermUsersRead), userCtrl.GetUsers)
 	userRoutes.Get("/profile", userCtrl.GetProfile)
-	userRoutes.Put("/profile", userCtrl.UpdateProfile)
-	userRoutes.Delete("/:id", userCtrl.DeleteUser)
-
-	// 🔹 Users Profile
-	userProfileCtrl := userController.NewUsersProfileController(db)
-	usersProfileRoutes := api.Group("/users-profiles")
-	usersProfileRoutes.Get("/", userProfileCtrl.GetProfiles)
-	usersProfileRoutes.Get("/:id", userProfileCtrl.GetProfile)
-	usersProfileRoutes.Post("/", userProfileCtrl.CreateProfile)
-	usersProfileRoutes.Put("/:id", userProfileCtrl.UpdateProfile)
-	usersProfileRoutes.Delete("/:id", userProfileCtrl.DeleteProfile)
+	userRoutes.Put("/profile", ownerOnly, userCtrl.UpdateProfile)
+
+	// 🔹 Profil milik user yang login (pemilik selalu diambil dari token)
+	userProfileCtrl := userController.NewUsersProfileController(db, preconditions)
+	userRoutes.Get("/me/profile", userProfileCtrl.GetMyProfile)
+	userRoutes.Put("/me/profile", userProfileCtrl.PutMyProfile)
+	userRoutes.Patch("/me/profile", userProfileCtrl.PatchMyProfile)
+
+	// 🔹 Hapus akun: soft delete + masa tenggang (lihat service.AccountDeletion); /me harus sebelum /:id
+	canDelete := authController.RequirePermission(constants.PermUsersDelete)
+	userRoutes.Delete("/me", ownerOnly, userCtrl.DeleteMe)
+	userRoutes.Delete("/:id", canDelete, userCtrl.DeleteUser)
+	userRoutes.Post("/:id/restore", canDelete, userCtrl.RestoreUser)
+
+	// 🔹 Moderasi akun (admin): role global, suspend & ban
+	moderationCtrl := userController.NewUserModerationController(db, userService.NewModeration(db, tokens, recorder), policy, preconditions)
+	canBan := authController.RequirePermission(constants.PermUsersBan)
+	userRoutes.Put("/:id/role", authController.RequirePermission(constants.PermUsersRoles), moderationCtrl.ChangeRole)
+	userRoutes.Post("/:id/suspend", canBan, moderationCtrl.Suspend)
+	userRoutes.Post("/:id/ban", canBan, moderationCtrl.Ban)
+	userRoutes.Post("/:id/reinstate", canBan, moderationCtrl.Reinstate)
+
+	// 🔹 Ekspor data milik user (arsip ZIP dari semua modul yang terdaftar di package dataexport)
+	exportCtrl := userController.NewDataExportController(db, userService.NewDataExport(db, store, mail, recorder, cfg.Auth.DataExportTTL))
+	userRoutes.Post("/me/export", ownerOnly, exportCtrl.RequestExport)
+	userRoutes.Get("/me/export/:id", ownerOnly, exportCtrl.GetExport)
+
+	// 🔹 Users Profile (admin)
+	canRead := authController.RequirePermission(constants.PermUsersRead)
+	canManage := authController.RequirePermission(constants.PermUsersManage)
+	usersProfileRoutes := app.Group("/api/users-profiles", authMiddleware)
+	usersProfileRoutes.Get("/", canRead, userProfileCtrl.GetProfiles)
+	usersProfileRoutes.Get("/:id", canRead, userProfileCtrl.GetProfile)
+	usersProfileRoutes.Post("/", canManage, userProfileCtrl.CreateProfile)
+	usersProfileRoutes.Put("/:id", canManage, userProfileCtrl.UpdateProfile)
+	usersProfileRoutes.Patch("/:id", canManage, userProfileCtrl.PatchProfile)
+	usersProfileRoutes.Delete("/:id", canManage, userProfileCtrl.DeleteProfile)
 }
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"masjidku/internals/audit"
+	modelDonation "masjidku/internals/features/donations/donation/models"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	authService "masjidku/internals/features/users/auth/service"
+	"masjidku/internals/features/users/user/models"
+)
+
+var (
+	// ErrStale: akun sudah diubah request lain sejak dibaca (version berbeda)
+	ErrStale = errors.New("akun sudah diubah request lain")
+	// ErrNotRestorable: akun tidak sedang dalam masa tenggang penghapusan
+	ErrNotRestorable = errors.New("akun tidak bisa dipulihkan")
+)
+
+// purgeBatchSize adalah jumlah akun yang dihapus permanen per putaran scheduler
+const purgeBatchSize = 100
+
+// AccountDeletion mengatur siklus hapus akun:
+//  1. Schedule: akun, profil dan sesi dinonaktifkan sekarang (soft delete), purge_at = sekarang + masa tenggang
+//  2. Restore: selama masa tenggang akun bisa dipulihkan beserta profilnya (sesi lama tetap dicabut)
+//  3. Purge: setelah purge_at akun dihapus permanen; donasinya dianonimkan tetapi nominalnya tetap
+//     untuk laporan keuangan masjid
+type AccountDeletion struct {
+	db     *gorm.DB
+	tokens *authService.TokenService
+	audit  *audit.Recorder
+	grace  time.Duration
+}
+
+func NewAccountDeletion(db *gorm.DB, tokens *authService.TokenService, recorder *audit.Recorder, grace time.Duration) *AccountDeletion {
+	return &AccountDeletion{db: db, tokens: tokens, audit: recorder, grace: grace}
+}
+
+// Schedule menonaktifkan akun user dan menjadwalkan penghapusan permanen. user harus dibaca
+// sebelumnya (version dipakai sebagai optimistic lock, ErrStale jika sudah berubah).
+// entry berisi actor & info request; action dan target diisi di sini.
+func (d *AccountDeletion) Schedule(ctx context.Context, user *models.UserModel, entry audit.Entry) (time.Time, error) {
+	// Dibulatkan ke mikrodetik (presisi kolom) karena dipakai untuk mencocokkan profil saat Restore
+	now := time.Now().Truncate(time.Microsecond)
+	purgeAt := now.Add(d.grace)
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(user).Where("version = ?", user.Version).
+			Updates(map[string]interface{}{"deleted_at": now, "purge_at": purgeAt})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStale
+		}
+
+		// 📌 Profil ikut di-soft delete dengan waktu yang sama agar bisa dipulihkan bersama akun
+		if err := tx.Model(&models.UsersProfileModel{}).Where("user_id = ?", user.ID).
+			Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if _, err := d.tokens.RevokeUserSessions(tx, user.ID, nil, modelAuth.RevokeReasonAccountDelete); err != nil {
+			return err
+		}
+
+		entry.Action = audit.ActionUserDelete
+		entry.TargetType = audit.TargetUser
+		entry.TargetID = user.ID.String()
+		entry.Metadata = map[string]interface{}{"purge_at": purgeAt.UTC().Format(time.RFC3339)}
+		return d.audit.RecordTx(tx, entry)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	user.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	user.PurgeAt = &purgeAt
+	return purgeAt, nil
+}
+
+// FindDeleted memuat akun yang sedang dalam masa tenggang (soft delete, belum di-purge)
+func (d *AccountDeletion) FindDeleted(ctx context.Context, query interface{}, args ...interface{}) (*models.UserModel, error) {
+	var user models.UserModel
+	err := d.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").
+		Where(query, args...).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Restore memulihkan akun (dari FindDeleted) beserta profil yang ikut dihapus bersamanya
+func (d *AccountDeletion) Restore(ctx context.Context, user *models.UserModel, entry audit.Entry) error {
+	if !user.DeletedAt.Valid || user.PurgeAt == nil || !user.PurgeAt.After(time.Now()) {
+		return ErrNotRestorable
+	}
+
+	deletedAt := user.DeletedAt.Time
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Model(user).
+			Where("deleted_at IS NOT NULL AND purge_at > ? AND version = ?", time.Now(), user.Version).
+			Updates(map[string]interface{}{"deleted_at": nil, "purge_at": nil, "version": gorm.Expr("version + 1")})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotRestorable
+		}
+
+		if err := tx.Unscoped().Model(&models.UsersProfileModel{}).
+			Where("user_id = ? AND deleted_at = ?", user.ID, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		entry.Action = audit.ActionUserRestore
+		entry.TargetType = audit.TargetUser
+		entry.TargetID = user.ID.String()
+		return d.audit.RecordTx(tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	user.PurgeAt = nil
+	user.Version++
+	return nil
+}
+
+// Purge menghapus permanen akun yang masa tenggangnya sudah lewat dan mengembalikan jumlahnya.
+// Kegagalan satu akun tidak menghentikan akun lainnya; error pertama dikembalikan.
+func (d *AccountDeletion) Purge(ctx context.Context) (int, error) {
+	var ids []uuid.UUID
+	err := d.db.WithContext(ctx).Unscoped().Model(&models.UserModel{}).
+		Where("deleted_at IS NOT NULL AND purge_at <= ?", time.Now()).
+		Order("purge_at").Limit(purgeBatchSize).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	var firstErr error
+	for _, id := range ids {
+		if err := d.purgeOne(ctx, id); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		purged++
+	}
+	return purged, firstErr
+}
+
+func (d *AccountDeletion) purgeOne(ctx context.Context, userID uuid.UUID) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci baris & pastikan masih terjadwal (bisa saja baru dipulihkan / diproses replika lain)
+		var user models.UserModel
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			Where("id = ? AND deleted_at IS NOT NULL AND purge_at <= ?", userID, time.Now()).
+			Take(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// 📌 Donasi tetap ada (nominal & status untuk laporan masjid), identitas donatur dihapus
+		if err := tx.Model(&modelDonation.DonationModel{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{
+				"user_id":      nil,
+				"donor_name":   modelDonation.AnonymousDonorName,
+				"donor_email":  "",
+				"message":      "",
+				"is_anonymous": true,
+			}).Error; err != nil {
+			return err
+		}
+		// refresh_tokens tidak punya foreign key ke users; tabel lain ikut terhapus (ON DELETE CASCADE / SET NULL)
+		if err := tx.Where("user_id = ?", userID).Delete(&modelAuth.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&user).Error; err != nil {
+			return err
+		}
+
+		return d.audit.RecordTx(tx, audit.Entry{
+			Action:     audit.ActionUserPurge,
+			TargetType: audit.TargetUser,
+			TargetID:   userID.String(),
+		})
+	})
+}
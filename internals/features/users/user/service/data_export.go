@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/audit"
+	"masjidku/internals/dataexport"
+	"masjidku/internals/features/users/user/models"
+	"masjidku/internals/mailer"
+	"masjidku/internals/storage"
+)
+
+const (
+	// exportReuseWindow: permintaan ulang dalam jangka ini mendapat ekspor yang sama (tidak menyusun arsip baru)
+	exportReuseWindow = time.Hour
+	// exportStaleAfter: ekspor yang masih pending selama ini dianggap terputus (mis. server restart)
+	exportStaleAfter = time.Hour
+)
+
+// DataExport menyusun arsip data milik user secara asinkron (lihat package dataexport),
+// menyimpannya di storage dan mengirim link unduhan yang kedaluwarsa setelah ttl ke email user
+type DataExport struct {
+	db      *gorm.DB
+	storage storage.Storage
+	mail    mailer.Mailer
+	audit   *audit.Recorder
+	ttl     time.Duration
+}
+
+func NewDataExport(db *gorm.DB, store storage.Storage, mail mailer.Mailer, recorder *audit.Recorder, ttl time.Duration) *DataExport {
+	return &DataExport{db: db, storage: store, mail: mail, audit: recorder, ttl: ttl}
+}
+
+// Request membuat permintaan ekspor lalu menyusun arsipnya di background. Jika user masih punya
+// ekspor yang sedang diproses / baru saja selesai, ekspor itu yang dikembalikan (created = false).
+func (s *DataExport) Request(ctx context.Context, user *models.UserModel, entry audit.Entry) (export *models.UserDataExportModel, created bool, err error) {
+	var latest models.UserDataExportModel
+	res := s.db.WithContext(ctx).Where("user_id = ?", user.ID).Order("created_at DESC").Limit(1).Find(&latest)
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected > 0 && (latest.Status == models.ExportStatusPending ||
+		(latest.Status == models.ExportStatusReady && time.Since(latest.CreatedAt) < exportReuseWindow)) {
+		return &latest, false, nil
+	}
+
+	export = &models.UserDataExportModel{UserID: user.ID, Status: models.ExportStatusPending}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(export).Error; err != nil {
+			return err
+		}
+		entry.Action = audit.ActionUserExport
+		entry.TargetType = audit.TargetUser
+		entry.TargetID = user.ID.String()
+		entry.Metadata = map[string]interface{}{"export_id": export.ID}
+		return s.audit.RecordTx(tx, entry)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 📌 Dijalankan di luar request; context request sudah selesai saat arsip disusun
+	go s.run(context.Background(), *export, *user)
+	return export, true, nil
+}
+
+// Find memuat ekspor milik user
+func (s *DataExport) Find(ctx context.Context, userID, exportID uuid.UUID) (*models.UserDataExportModel, error) {
+	var export models.UserDataExportModel
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", exportID, userID).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// DownloadURL membuat link unduhan untuk ekspor yang sudah siap (berlaku sampai ExpiresAt)
+func (s *DataExport) DownloadURL(export *models.UserDataExportModel) (string, error) {
+	if export.Status != models.ExportStatusReady || export.ExpiresAt == nil {
+		return "", fmt.Errorf("ekspor %s belum siap diunduh", export.ID)
+	}
+	return s.storage.SignedURL(export.FileKey, *export.ExpiresAt)
+}
+
+func (s *DataExport) run(ctx context.Context, export models.UserDataExportModel, user models.UserModel) {
+	key := fmt.Sprintf("exports/%s/%s.zip", user.ID, export.ID)
+	size, err := s.build(ctx, &user, key)
+	if err != nil {
+		log.Printf("[ERROR] Data export %s failed: %v", export.ID, err)
+		if err := s.db.Model(&export).Updates(map[string]interface{}{
+			"status":         models.ExportStatusFailed,
+			"failure_reason": truncate(err.Error(), 255),
+		}).Error; err != nil {
+			log.Printf("[ERROR] Failed to mark data export %s as failed: %v", export.ID, err)
+		}
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	export.Status = models.ExportStatusReady
+	export.FileKey = key
+	export.SizeBytes = size
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	if err := s.db.Model(&export).Select("status", "file_key", "size_bytes", "completed_at", "expires_at").Updates(&export).Error; err != nil {
+		log.Printf("[ERROR] Failed to save data export %s: %v", export.ID, err)
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("[ERROR] Failed to delete data export file %s: %v", key, err)
+		}
+		return
+	}
+	log.Printf("[SUCCESS] Data export %s ready (%d bytes)", export.ID, size)
+
+	s.notify(ctx, &export, &user)
+}
+
+// build menyusun arsip di file sementara lalu mengunggahnya ke storage
+func (s *DataExport) build(ctx context.Context, user *models.UserModel, key string) (int64, error) {
+	tmp, err := os.CreateTemp("", "masjidku-export-*.zip")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := dataexport.Build(ctx, s.db, user.ID, tmp); err != nil {
+		return 0, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err := s.storage.Put(ctx, key, tmp); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// notify mengirim link unduhan ke email user; gagal kirim hanya dicatat (link tetap bisa diambil lewat API)
+func (s *DataExport) notify(ctx context.Context, export *models.UserDataExportModel, user *models.UserModel) {
+	link, err := s.DownloadURL(export)
+	if err != nil {
+		log.Printf("[ERROR] Failed to create data export link: %v", err)
+		return
+	}
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Salinan data akun Masjidku Anda sudah siap",
+		Body: fmt.Sprintf("Assalamu'alaikum %s,\n\n"+
+			"Salinan data akun Anda yang diminta pada %s sudah siap. Unduh arsipnya lewat link berikut "+
+			"(berlaku sampai %s):\n\n%s\n\n"+
+			"Jika Anda tidak pernah meminta salinan data, segera ganti password akun Anda.\n",
+			user.UserName, export.CreatedAt.Format("02 Jan 2006 15:04 MST"), export.ExpiresAt.Format("02 Jan 2006 15:04 MST"), link),
+	}
+	if err := s.mail.Send(ctx, msg); err != nil {
+		log.Printf("[ERROR] Failed to send data export email: %v", err)
+	}
+}
+
+// CleanupDataExports menghapus arsip yang sudah kedaluwarsa atau milik akun yang sudah dihapus dari storage,
+// dan menandai ekspor yang terputus di tengah jalan sebagai gagal. Mengembalikan jumlah arsip yang dihapus.
+func CleanupDataExports(ctx context.Context, db *gorm.DB, store storage.Storage) (int, error) {
+	if err := db.WithContext(ctx).Model(&models.UserDataExportModel{}).
+		Where("status = ? AND created_at < ?", models.ExportStatusPending, time.Now().Add(-exportStaleAfter)).
+		Updates(map[string]interface{}{"status": models.ExportStatusFailed, "failure_reason": "interrupted"}).Error; err != nil {
+		return 0, err
+	}
+
+	var expired []models.UserDataExportModel
+	if err := db.WithContext(ctx).Where("status = ?", models.ExportStatusReady).
+		Where("expires_at <= ? OR user_id IN (SELECT id FROM users WHERE deleted_at IS NOT NULL)", time.Now()).
+		Find(&expired).Error; err != nil {
+		return 0, err
+	}
+	removed := 0
+	var errs []error
+	for i := range expired {
+		if err := store.Delete(ctx, expired[i].FileKey); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := db.WithContext(ctx).Model(&expired[i]).
+			Updates(map[string]interface{}{"status": models.ExportStatusExpired, "file_key": ""}).Error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
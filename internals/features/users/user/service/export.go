@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/dataexport"
+	"masjidku/internals/features/users/user/dto"
+	"masjidku/internals/features/users/user/models"
+)
+
+func init() {
+	dataexport.Register("users", exportUser)
+}
+
+// exportUser: data akun (tanpa password / jawaban keamanan, sama seperti GET /api/users/profile) dan profil
+func exportUser(ctx context.Context, db *gorm.DB, userID uuid.UUID, archive *dataexport.Archive) error {
+	var user models.UserModel
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+	if err := archive.JSON("account.json", dto.ToUserSelf(&user)); err != nil {
+		return err
+	}
+
+	var profile models.UsersProfileModel
+	err := db.Where("user_id = ?", userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return archive.JSON("profile.json", dto.ToProfileSelf(&profile))
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"masjidku/internals/audit"
+	modelAuth "masjidku/internals/features/users/auth/models"
+	authService "masjidku/internals/features/users/auth/service"
+	"masjidku/internals/features/users/user/models"
+)
+
+// Moderation adalah aksi admin platform terhadap akun user: ubah role global,
+// suspend sampai waktu tertentu, ban, dan mencabut suspend / ban. Semua aksi memakai
+// version user sebagai optimistic lock (ErrStale) dan dicatat di audit log dalam transaksi yang sama.
+type Moderation struct {
+	db     *gorm.DB
+	tokens *authService.TokenService
+	audit  *audit.Recorder
+}
+
+func NewModeration(db *gorm.DB, tokens *authService.TokenService, recorder *audit.Recorder) *Moderation {
+	return &Moderation{db: db, tokens: tokens, audit: recorder}
+}
+
+// ChangeRole mengganti role global user. Role baru berlaku di request berikutnya karena
+// AuthMiddleware membaca role dari database, bukan dari access token.
+func (m *Moderation) ChangeRole(ctx context.Context, user *models.UserModel, role string, entry audit.Entry) error {
+	entry.Action = audit.ActionUserRoleChange
+	entry.Before = map[string]interface{}{"role": user.Role}
+	entry.After = map[string]interface{}{"role": role}
+	return m.update(ctx, user, map[string]interface{}{"role": role}, false, entry, func() {
+		user.Role = role
+	})
+}
+
+// Suspend memblokir akun sampai until dan mencabut semua sesinya
+func (m *Moderation) Suspend(ctx context.Context, user *models.UserModel, until time.Time, reason string, entry audit.Entry) error {
+	entry.Action = audit.ActionUserSuspend
+	entry.Metadata = map[string]interface{}{"until": until.UTC().Format(time.RFC3339), "reason": reason}
+	return m.update(ctx, user, map[string]interface{}{"suspended_until": until, "suspension_reason": reason}, true, entry, func() {
+		user.SuspendedUntil = &until
+		user.SuspensionReason = reason
+	})
+}
+
+// Ban memblokir akun tanpa batas waktu dan mencabut semua sesinya
+func (m *Moderation) Ban(ctx context.Context, user *models.UserModel, reason string, entry audit.Entry) error {
+	now := time.Now()
+	entry.Action = audit.ActionUserBan
+	entry.Metadata = map[string]interface{}{"reason": reason}
+	return m.update(ctx, user, map[string]interface{}{"banned_at": now, "ban_reason": reason}, true, entry, func() {
+		user.BannedAt = &now
+		user.BanReason = reason
+	})
+}
+
+// Reinstate mencabut suspend dan ban; user harus login ulang karena sesinya sudah dicabut
+func (m *Moderation) Reinstate(ctx context.Context, user *models.UserModel, entry audit.Entry) error {
+	entry.Action = audit.ActionUserReinstate
+	columns := map[string]interface{}{
+		"suspended_until": nil, "suspension_reason": "", "banned_at": nil, "ban_reason": "",
+	}
+	return m.update(ctx, user, columns, false, entry, func() {
+		user.SuspendedUntil, user.SuspensionReason = nil, ""
+		user.BannedAt, user.BanReason = nil, ""
+	})
+}
+
+// update menyimpan columns jika version user belum berubah, opsional mencabut semua sesi,
+// lalu mencatat audit. apply dipanggil setelah commit untuk memperbarui user di memori.
+func (m *Moderation) update(ctx context.Context, user *models.UserModel, columns map[string]interface{}, revoke bool, entry audit.Entry, apply func()) error {
+	columns["version"] = gorm.Expr("version + 1")
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(user).Where("version = ?", user.Version).Updates(columns)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStale
+		}
+		if revoke {
+			if _, err := m.tokens.RevokeUserSessions(tx, user.ID, nil, modelAuth.RevokeReasonSuspended); err != nil {
+				return err
+			}
+		}
+
+		entry.TargetType = audit.TargetUser
+		entry.TargetID = user.ID.String()
+		return m.audit.RecordTx(tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	apply()
+	user.Version++
+	return nil
+}
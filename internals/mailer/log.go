@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogMailer tidak mengirim email sungguhan: isi email ditulis ke log dan,
+// jika OutputDir diisi, disimpan sebagai file .eml untuk dibuka saat development.
+type LogMailer struct {
+	OutputDir string
+}
+
+func NewLogMailer(outputDir string) *LogMailer {
+	return &LogMailer{OutputDir: outputDir}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("[MAIL] To=%s Subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+
+	if m.OutputDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.OutputDir, 0o755); err != nil {
+		return fmt.Errorf("gagal membuat folder mail: %w", err)
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(m.OutputDir, name), buildMessage("dev@masjidku.local", msg), 0o644)
+}
+
+func sanitize(s string) string {
+	out := []rune{}
+	for _, r := range s {
+		if r == '@' || r == '.' || r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,34 @@
+// Package mailer menyediakan abstraksi pengiriman email. Implementasi SMTP dipakai
+// di production, sedangkan LogMailer menulis email ke log / folder untuk development.
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"masjidku/internals/configs"
+)
+
+// Message adalah email yang akan dikirim
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// Mailer mengirim email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New membuat mailer sesuai konfigurasi MAIL_DRIVER (smtp | log)
+func New(cfg configs.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "", "log":
+		return NewLogMailer(cfg.OutputDir), nil
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER %q tidak dikenal", cfg.Driver)
+	}
+}
@@ -0,0 +1,60 @@
+// Package mergepatch menerapkan JSON Merge Patch (RFC 7396): member patch menimpa
+// dokumen, null menghapus member, objek digabung secara rekursif, selain itu diganti utuh.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// MIMEType adalah content type untuk request PATCH berformat merge patch
+const MIMEType = "application/merge-patch+json"
+
+// Apply menerapkan patch ke doc dan mengembalikan dokumen hasilnya
+func Apply(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if len(bytes.TrimSpace(doc)) > 0 {
+		if err := decode(doc, &target); err != nil {
+			return nil, err
+		}
+	}
+	var p interface{}
+	if err := decode(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p))
+}
+
+// IsMergePatch: Content-Type request adalah application/merge-patch+json
+// (application/json juga diterima oleh handler PATCH untuk kemudahan client)
+func IsMergePatch(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return strings.EqualFold(mediaType, MIMEType)
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = merge(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// decode memakai json.Number agar angka besar tidak kehilangan presisi
+func decode(raw []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,106 @@
+package mergepatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestApplyRFC7396 memakai contoh di RFC 7396 Appendix A
+func TestApplyRFC7396(t *testing.T) {
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.doc+" + "+tc.patch, func(t *testing.T) {
+			got, err := Apply([]byte(tc.doc), []byte(tc.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSONEqual(t, got, tc.want)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name, doc, patch, want string
+	}{
+		{"dokumen kosong", ``, `{"a":1}`, `{"a":1}`},
+		{"patch kosong tidak mengubah", `{"a":1,"b":[1,2]}`, `{}`, `{"a":1,"b":[1,2]}`},
+		{"angka besar tetap presisi", `{"id":9007199254740993}`, `{"x":12345678901234567890}`,
+			`{"id":9007199254740993,"x":12345678901234567890}`},
+		{"hapus member yang tidak ada", `{"a":1}`, `{"b":null}`, `{"a":1}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Apply([]byte(tc.doc), []byte(tc.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSONEqual(t, got, tc.want)
+		})
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	cases := []struct {
+		name, doc, patch string
+	}{
+		{"patch rusak", `{"a":1}`, `{"a":`},
+		{"patch kosong", `{"a":1}`, ``},
+		{"dokumen rusak", `{"a"`, `{"a":1}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Apply([]byte(tc.doc), []byte(tc.patch)); err == nil {
+				t.Error("Apply harus gagal")
+			}
+		})
+	}
+}
+
+func TestIsMergePatch(t *testing.T) {
+	cases := map[string]bool{
+		"application/merge-patch+json":                  true,
+		"Application/Merge-Patch+JSON":                  true,
+		" application/merge-patch+json ; charset=utf-8": true,
+		"application/json":                              false,
+		"application/json-patch+json":                   false,
+		"":                                              false,
+	}
+	for contentType, want := range cases {
+		if got := IsMergePatch(contentType); got != want {
+			t.Errorf("IsMergePatch(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w interface{}
+	if err := decode(got, &g); err != nil {
+		t.Fatalf("hasil bukan JSON: %s", got)
+	}
+	if err := decode([]byte(want), &w); err != nil {
+		t.Fatalf("want bukan JSON: %s", want)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("Apply = %s, want %s", got, want)
+	}
+}
@@ -2,24 +2,27 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 	"log"
 
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/google/uuid"
 
-	"masjidku/internals/configs"
-	modelAuth "masjidku/internals/features/users/auth/models"
+	"masjidku/internals/apperror"
+	modelMasjid "masjidku/internals/features/masjids/masjid/models"
+	"masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	modelUser "masjidku/internals/features/users/user/models"
 
 	"gorm.io/gorm"
 )
 
-
-// 🔥 Middleware untuk proteksi route
-func AuthMiddleware(db *gorm.DB) fiber.Handler {
+// 🔥 Middleware untuk proteksi route.
+// Hasilnya satu *Principal di context (lihat PrincipalFrom / UserID), dipakai oleh
+// MasjidContext, Require/RequireRole dan semua handler.
+func AuthMiddleware(db *gorm.DB, tokens *service.TokenService, policy *rbacService.Policy) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
 		// 🚨 Skip middleware untuk Midtrans webhook
@@ -29,76 +32,89 @@ func AuthMiddleware(db *gorm.DB) fiber.Handler {
 		}
 
 		authHeader := c.Get("Authorization")
-		log.Println("[DEBUG] Authorization Header:", authHeader)
 		if authHeader == "" {
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - No token provided"})
+			return apperror.Unauthorized(apperror.CodeTokenMissing)
 		}
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - Invalid token format"})
+			return apperror.Unauthorized(apperror.CodeTokenMalformed)
 		}
 		tokenString := tokenParts[1]
-		var existingToken modelAuth.TokenBlacklist
-		err := db.Where("token = ?", tokenString).First(&existingToken).Error
-		if err == nil {
-			log.Println("[WARNING] Token ditemukan di blacklist, akses ditolak.")
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - Token is blacklisted"})
-		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("[ERROR] Database error saat cek token blacklist:", err)
-			return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
-		}
-		secretKey := configs.JWTSecret
-		if secretKey == "" {
-			log.Println("[ERROR] JWT_SECRET tidak ditemukan di environment")
-			return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error - Missing JWT Secret"})
-		}
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secretKey), nil
-		})
-		if err != nil || !token.Valid {
+		claims, err := tokens.ParseAccess(tokenString)
+		if err != nil {
 			log.Println("[ERROR] Token tidak valid:", err)
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - Invalid token"})
+			return apperror.Unauthorized(apperror.CodeTokenInvalid)
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			log.Println("[ERROR] Token claims tidak valid")
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - Invalid token claims"})
-		}
-		exp, exists := claims["exp"].(float64)
-		if !exists {
-			log.Println("[ERROR] Token tidak memiliki exp")
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - Token has no expiration"})
-		}
-		log.Println("[DEBUG] Token Claims:", claims)
 
-		idStr, exists := claims["id"].(string)
-		if !exists {
-			log.Println("[ERROR] User ID not found in token claims")
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - No user ID in token"})
+		// Token yang dicabut (logout, sesi dicabut) dicek lewat revocation store (bloom + LRU)
+		revoked, err := tokens.IsAccessRevoked(c.UserContext(), claims)
+		if err != nil {
+			return apperror.Internal(fmt.Errorf("cek pencabutan token: %w", err))
+		}
+		if revoked {
+			log.Println("[WARNING] Token sudah dicabut, akses ditolak.")
+			return apperror.Unauthorized(apperror.CodeTokenRevoked)
 		}
 
-		userID, err := uuid.Parse(idStr)
+		userID, err := claims.UserID()
 		if err != nil {
 			log.Println("[ERROR] Failed to parse UUID from token:", err)
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - Invalid user ID format"})
+			return apperror.Unauthorized(apperror.CodeTokenInvalid)
 		}
 
-		c.Locals("user_id", userID)
-		log.Println("[SUCCESS] User ID stored in context:", userID)
+		// User dimuat ulang setiap request: status blokir dan role selalu dari database, bukan dari token
+		var user modelUser.UserModel
+		if err := db.Select("id", "role", "sessions_revoked_at", "suspended_until", "suspension_reason", "banned_at", "ban_reason").
+			First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.Unauthorized(apperror.CodeUserNotFound)
+			}
+			return apperror.Internal(fmt.Errorf("cek user: %w", err))
+		}
+		// Token yang terbit sebelum sesi user dicabut (mis. setelah reset password) ditolak
+		if user.SessionsRevokedAt != nil {
+			if claims.IssuedAt.Unix() < user.SessionsRevokedAt.Unix() {
+				log.Println("[WARNING] Token terbit sebelum sesi dicabut, akses ditolak.")
+				return apperror.Unauthorized(apperror.CodeSessionRevoked)
+			}
+		}
+		if err := user.BlockedError(time.Now()); err != nil {
+			return err
+		}
 
-		if role, ok := claims["role"].(string); ok {
-			c.Locals("role", role)
+		principal := &Principal{
+			UserID:   userID,
+			UserName: claims.UserName,
+			Role:     user.Role, // perubahan role oleh admin langsung berlaku
+			TokenID:  claims.ID,
+			MFA:      claims.MFA,
+			Claims:   claims,
+			policy:   policy,
 		}
-		if userName, ok := claims["user_name"].(string); ok {
-			c.Locals("user_name", userName)
+		if sessionID, err := claims.FamilyID(); err == nil {
+			principal.SessionID = sessionID
+		}
+		// 🎭 Token impersonation: admin (claim act) bertindak atas nama user; ditandai di response
+		if claims.Impersonated() {
+			impersonator, err := loadImpersonator(db, policy, claims)
+			if err != nil {
+				return err
+			}
+			principal.Impersonator = impersonator
+			c.Set(HeaderImpersonatedBy, impersonator.UserID.String())
+			log.Printf("[INFO] Impersonation: admin %s bertindak sebagai user %s", impersonator.UserID, userID)
 		}
 
-		expTime := time.Unix(int64(exp), 0)
-		log.Printf("[INFO] Token Expiration Time: %v", expTime)
-		if time.Now().Unix() > int64(exp) {
-			log.Println("[ERROR] Token sudah expired")
-			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized - Token expired"})
+		// Keanggotaan masjid aktif, dipakai MasjidContext untuk role per masjid
+		if err := db.Model(&modelMasjid.MasjidMemberModel{}).Select("masjid_id", "role").
+			Where("user_id = ? AND status = ?", userID, modelMasjid.MemberStatusActive).
+			Scan(&principal.Memberships).Error; err != nil {
+			return apperror.Internal(fmt.Errorf("memuat keanggotaan masjid: %w", err))
 		}
+
+		setPrincipal(c, principal)
+		log.Println("[SUCCESS] User ID stored in context:", userID)
+
 		log.Println("[SUCCESS] Token valid, lanjutkan request")
 		return c.Next()
 	}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/constants"
+	"masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	modelUser "masjidku/internals/features/users/user/models"
+)
+
+// HeaderImpersonatedBy dikirim di setiap response request yang memakai token impersonation,
+// berisi UUID admin yang sebenarnya melakukan request
+const HeaderImpersonatedBy = "X-Impersonated-By"
+
+// Impersonator adalah admin platform yang login sebagai user lain (claim "act" pada access token)
+type Impersonator struct {
+	UserID   uuid.UUID
+	UserName string
+	Role     string // role global admin saat request
+}
+
+// Impersonated bernilai true jika request dilakukan admin atas nama user (Principal.UserID)
+func (p *Principal) Impersonated() bool {
+	return p.Impersonator != nil
+}
+
+// RealUserID adalah user yang sebenarnya melakukan request: admin saat impersonation, selain itu UserID
+func (p *Principal) RealUserID() uuid.UUID {
+	if p.Impersonator != nil {
+		return p.Impersonator.UserID
+	}
+	return p.UserID
+}
+
+// RealUserID mengambil ID user yang sebenarnya melakukan request (lihat Principal.RealUserID)
+func RealUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	p, ok := PrincipalFrom(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return p.RealUserID(), true
+}
+
+// DenyImpersonation menolak request yang memakai token impersonation. Dipasang setelah
+// AuthMiddleware pada aksi sensitif yang hanya boleh dilakukan pemilik akun sendiri
+// (ganti password, 2FA, sesi, hapus akun, ekspor data, pembayaran).
+func DenyImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if p, ok := PrincipalFrom(c); ok && p.Impersonated() {
+			return apperror.Forbidden(apperror.CodeImpersonationForbidden)
+		}
+		return c.Next()
+	}
+}
+
+// loadImpersonator memuat admin dari claim act dan memastikan ia masih boleh melakukan impersonation:
+// akun tidak diblokir, sesinya tidak dicabut dan role-nya masih punya permission users:impersonate
+func loadImpersonator(db *gorm.DB, policy *rbacService.Policy, claims *service.AccessClaims) (*Impersonator, error) {
+	actorID, err := claims.ActorID()
+	if err != nil {
+		return nil, apperror.Unauthorized(apperror.CodeTokenInvalid)
+	}
+
+	var actor modelUser.UserModel
+	if err := db.Select("id", "user_name", "role", "sessions_revoked_at", "suspended_until", "suspension_reason", "banned_at", "ban_reason").
+		First(&actor, "id = ?", actorID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.Unauthorized(apperror.CodeTokenRevoked)
+		}
+		return nil, apperror.Internal(fmt.Errorf("cek admin impersonation: %w", err))
+	}
+	if actor.SessionsRevokedAt != nil && claims.IssuedAt.Unix() < actor.SessionsRevokedAt.Unix() {
+		return nil, apperror.Unauthorized(apperror.CodeSessionRevoked)
+	}
+	if actor.Blocked(time.Now()) {
+		return nil, apperror.Unauthorized(apperror.CodeTokenRevoked)
+	}
+	if policy == nil || !policy.Can(actor.Role, constants.PermUsersImpersonate) {
+		return nil, apperror.Unauthorized(apperror.CodeTokenRevoked)
+	}
+	return &Impersonator{UserID: actor.ID, UserName: actor.UserName, Role: actor.Role}, nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/constants"
+	modelMasjid "masjidku/internals/features/masjids/masjid/models"
+)
+
+// 🕌 MasjidContext me-resolve masjid dari URL (/:slug atau /:id) beserta role user di masjid tersebut.
+// Dipasang setelah AuthMiddleware (atau tanpa auth untuk route publik). Hasilnya disimpan di:
+//   - c.Locals("masjid_id")   -> uuid.UUID
+//   - c.Locals("masjid_slug") -> string
+//   - Principal.CurrentMasjidRole() -> role user di masjid ("" jika bukan anggota aktif)
+//
+// Jika masjid mewajibkan 2FA untuk pengurus (require_staff_mfa), anggota selain
+// role "user" yang sesinya tidak melalui login 2FA ditolak dengan code "mfa_required".
+func MasjidContext(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Params("slug")
+		if key == "" {
+			key = c.Params("id")
+		}
+		if key == "" {
+			return apperror.BadRequest(apperror.CodeMasjidRequired)
+		}
+
+		var masjid modelMasjid.MasjidModel
+		query := db.Where("slug = ?", key)
+		if id, err := uuid.Parse(key); err == nil {
+			query = db.Where("id = ?", id)
+		}
+		if err := query.First(&masjid).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.NotFound(apperror.CodeMasjidNotFound)
+			}
+			return apperror.Internal(fmt.Errorf("mencari masjid: %w", err))
+		}
+
+		c.Locals("masjid_id", masjid.ID)
+		c.Locals("masjid_slug", masjid.Slug)
+
+		principal, ok := PrincipalFrom(c)
+		if !ok {
+			return c.Next()
+		}
+
+		principal.enterMasjid(masjid.ID)
+		role := principal.CurrentMasjidRole()
+		if masjid.RequireStaffMFA && role != "" && role != constants.RoleUser && !principal.MFA {
+			return apperror.Forbidden(apperror.CodeMFARequired)
+		}
+
+		return c.Next()
+	}
+}
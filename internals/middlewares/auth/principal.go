@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+)
+
+const principalKey = "principal"
+
+// Membership adalah keanggotaan aktif user di satu masjid
+type Membership struct {
+	MasjidID uuid.UUID
+	Role     string
+}
+
+// Principal adalah identitas pemanggil yang sudah diautentikasi oleh AuthMiddleware.
+// Semua handler dan middleware otorisasi membaca identitas lewat Principal (bukan c.Locals satu per satu).
+type Principal struct {
+	UserID      uuid.UUID
+	UserName    string
+	Role        string       // role global di tabel users
+	TokenID     string       // jti access token
+	SessionID   uuid.UUID    // family refresh token; uuid.Nil jika token tidak punya sid
+	MFA         bool         // true jika sesi dibuat lewat login 2FA
+	Memberships []Membership // keanggotaan masjid yang aktif
+	Claims      *service.AccessClaims
+	// Impersonator diisi jika admin login sebagai user ini (token dengan claim act); UserID tetap user yang di-impersonate
+	Impersonator *Impersonator
+
+	// Diisi MasjidContext untuk route di dalam satu masjid
+	masjidID   uuid.UUID
+	masjidRole string
+
+	policy *rbacService.Policy
+}
+
+// PrincipalFrom mengambil Principal dari context; ok=false untuk request tamu
+func PrincipalFrom(c *fiber.Ctx) (*Principal, bool) {
+	p, ok := c.Locals(principalKey).(*Principal)
+	return p, ok && p != nil
+}
+
+// UserID mengambil ID user yang login dari context
+func UserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	p, ok := PrincipalFrom(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return p.UserID, true
+}
+
+func setPrincipal(c *fiber.Ctx, p *Principal) {
+	c.Locals(principalKey, p)
+}
+
+// MasjidRole mengembalikan role user di masjid tertentu ("" jika bukan anggota aktif)
+func (p *Principal) MasjidRole(masjidID uuid.UUID) string {
+	for _, m := range p.Memberships {
+		if m.MasjidID == masjidID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// IsMemberOf bernilai true jika user anggota aktif masjid tersebut
+func (p *Principal) IsMemberOf(masjidID uuid.UUID) bool {
+	return p.MasjidRole(masjidID) != ""
+}
+
+// InMasjid bernilai true jika request berada dalam konteks masjid (MasjidContext sudah dijalankan)
+func (p *Principal) InMasjid() bool {
+	return p.masjidID != uuid.Nil
+}
+
+// CurrentMasjidRole adalah role user di masjid pada URL ("" jika bukan anggota atau di luar konteks masjid)
+func (p *Principal) CurrentMasjidRole() string {
+	return p.masjidRole
+}
+
+// EffectiveRole adalah role yang dipakai untuk otorisasi: role di masjid jika dalam
+// konteks masjid, selain itu role global
+func (p *Principal) EffectiveRole() string {
+	if p.InMasjid() {
+		return p.masjidRole
+	}
+	return p.Role
+}
+
+// Roles mengembalikan role global beserta role di masjid pada URL (jika ada)
+func (p *Principal) Roles() []string {
+	roles := []string{p.Role}
+	if p.masjidRole != "" && p.masjidRole != p.Role {
+		roles = append(roles, p.masjidRole)
+	}
+	return roles
+}
+
+// HasRole mengecek EffectiveRole terhadap daftar role
+func (p *Principal) HasRole(roles ...string) bool {
+	role := p.EffectiveRole()
+	if role == "" {
+		return false
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Can mengecek permission milik EffectiveRole (termasuk permission warisan parent role)
+func (p *Principal) Can(permission string) bool {
+	return p.policy != nil && p.policy.Can(p.EffectiveRole(), permission)
+}
+
+// Permissions mengembalikan semua permission efektif milik EffectiveRole
+func (p *Principal) Permissions() []string {
+	if p.policy == nil {
+		return nil
+	}
+	return p.policy.Permissions(p.EffectiveRole())
+}
+
+// HasSession bernilai true jika access token terikat ke satu sesi (family refresh token)
+func (p *Principal) HasSession() bool {
+	return p.SessionID != uuid.Nil
+}
+
+func (p *Principal) enterMasjid(masjidID uuid.UUID) {
+	p.masjidID = masjidID
+	p.masjidRole = p.MasjidRole(masjidID)
+}
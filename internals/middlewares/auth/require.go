@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"masjidku/internals/apperror"
+)
+
+// Requirement adalah satu syarat otorisasi terhadap Principal.
+// Requirement bisa digabung dengan AllOf / AnyOf lalu dipasang lewat Require.
+type Requirement func(p *Principal) bool
+
+// HasRole: role efektif (role di masjid jika dalam konteks masjid) salah satu dari roles
+func HasRole(roles ...string) Requirement {
+	return func(p *Principal) bool { return p.HasRole(roles...) }
+}
+
+// HasGlobalRole: role global user (tanpa melihat konteks masjid) salah satu dari roles
+func HasGlobalRole(roles ...string) Requirement {
+	return func(p *Principal) bool {
+		for _, r := range roles {
+			if r == p.Role {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasPermission: role efektif memiliki semua permission (lihat tabel roles / role_permissions)
+func HasPermission(permissions ...string) Requirement {
+	return func(p *Principal) bool {
+		for _, perm := range permissions {
+			if !p.Can(perm) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MasjidMember: user anggota aktif masjid pada URL
+func MasjidMember() Requirement {
+	return func(p *Principal) bool { return p.InMasjid() && p.CurrentMasjidRole() != "" }
+}
+
+// WithMFA: sesi dibuat lewat login 2FA
+func WithMFA() Requirement {
+	return func(p *Principal) bool { return p.MFA }
+}
+
+// AllOf lolos jika semua requirement lolos
+func AllOf(reqs ...Requirement) Requirement {
+	return func(p *Principal) bool {
+		for _, req := range reqs {
+			if !req(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf lolos jika salah satu requirement lolos
+func AnyOf(reqs ...Requirement) Requirement {
+	return func(p *Principal) bool {
+		for _, req := range reqs {
+			if req(p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Require menolak request yang tidak memenuhi semua requirement.
+// Harus dipasang setelah AuthMiddleware (dan MasjidContext untuk requirement berbasis masjid);
+// request tanpa Principal ditolak 401, bukan panic.
+func Require(reqs ...Requirement) fiber.Handler {
+	check := AllOf(reqs...)
+	return func(c *fiber.Ctx) error {
+		p, ok := PrincipalFrom(c)
+		if !ok {
+			return apperror.Unauthorized(apperror.CodeUnauthorized)
+		}
+		if !check(p) {
+			return apperror.Forbidden(apperror.CodeForbidden)
+		}
+		return c.Next()
+	}
+}
+
+// RequireRole adalah singkatan Require(HasRole(roles...))
+func RequireRole(roles ...string) fiber.Handler {
+	return Require(HasRole(roles...))
+}
+
+// RequirePermission adalah singkatan Require(HasPermission(permissions...)).
+// Di dalam konteks masjid yang dicek role user di masjid tersebut, di luar itu role global.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return Require(HasPermission(permissions...))
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"masjidku/internals/apperror"
+	modelUser "masjidku/internals/features/users/user/models"
+)
+
+// RequireVerifiedEmail menolak request dari user yang emailnya belum diverifikasi.
+// Jika `when` diisi, pengecekan hanya dilakukan saat salah satu kondisi bernilai true
+// (mis. donasi di atas nominal tertentu). Harus dipasang setelah AuthMiddleware;
+// request tanpa user (tamu) dianggap belum terverifikasi.
+func RequireVerifiedEmail(db *gorm.DB, when ...func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(when) > 0 {
+			required := false
+			for _, cond := range when {
+				if cond(c) {
+					required = true
+					break
+				}
+			}
+			if !required {
+				return c.Next()
+			}
+		}
+
+		userID, ok := UserID(c)
+		if !ok {
+			return apperror.Forbidden(apperror.CodeVerifiedLoginRequired)
+		}
+
+		var user modelUser.UserModel
+		if err := db.Select("id", "email_verified_at").First(&user, "id = ?", userID).Error; err != nil {
+			log.Println("[ERROR] Failed to check email verification:", err)
+			return apperror.Unauthorized(apperror.CodeUnauthorized)
+		}
+		if user.EmailVerifiedAt == nil {
+			return apperror.Forbidden(apperror.CodeEmailNotVerified)
+		}
+
+		return c.Next()
+	}
+}
@@ -1,20 +0,0 @@
-package middlewares
-
-import (
-	"github.com/gofiber/fiber/v2"
-	jwtware "github.com/gofiber/jwt/v3"
-)
-
-func JWTProtected(secret string) fiber.Handler {
-	return jwtware.New(jwtware.Config{
-		SigningKey:  []byte(secret),
-		TokenLookup: "header:Authorization",
-		AuthScheme:  "Bearer",
-		ContextKey:  "user", // user disimpan di c.Locals("user")
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"message": "Unauthorized: Token tidak valid",
-			})
-		},
-	})
-}
@@ -1,25 +0,0 @@
-package middlewares
-
-import (
-	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-)
-
-// RoleChecker mengecek apakah role user cocok dengan role yang diizinkan
-func RoleChecker(allowedRoles ...string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		userToken := c.Locals("user").(*jwt.Token)
-		claims := userToken.Claims.(jwt.MapClaims)
-		role := claims["role"].(string)
-
-		for _, allowed := range allowedRoles {
-			if role == allowed {
-				return c.Next()
-			}
-		}
-
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"message": "Akses ditolak: Role Anda tidak diizinkan",
-		})
-	}
-}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Op adalah operator filter
+type Op int
+
+const (
+	Eq     Op = iota // kolom = nilai
+	Prefix           // kolom diawali nilai (case-insensitive)
+	Gte              // kolom >= nilai
+	Lte              // kolom <= nilai
+)
+
+// Filter memetakan satu query param ke kondisi pada satu kolom
+type Filter struct {
+	Column string
+	Op     Op
+	Parse  func(string) (interface{}, error) // opsional: validasi / konversi nilai (default string apa adanya)
+}
+
+type appliedFilter struct {
+	Filter
+	value interface{}
+}
+
+func (f Filter) parse(raw string) (interface{}, error) {
+	if f.Parse == nil {
+		return raw, nil
+	}
+	return f.Parse(raw)
+}
+
+func (f appliedFilter) apply(db *gorm.DB) *gorm.DB {
+	switch f.Op {
+	case Prefix:
+		return db.Where(f.Column+" ILIKE ?", likeEscaper.Replace(f.value.(string))+"%")
+	case Gte:
+		return db.Where(f.Column+" >= ?", f.value)
+	case Lte:
+		return db.Where(f.Column+" <= ?", f.value)
+	default:
+		return db.Where(f.Column+" = ?", f.value)
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ParseTime menerima YYYY-MM-DD (awal hari, UTC) atau RFC3339
+func ParseTime(raw string) (interface{}, error) {
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, errors.New("format harus YYYY-MM-DD atau RFC3339")
+	}
+	return t, nil
+}
+
+// ParseUUID memvalidasi nilai UUID
+func ParseUUID(raw string) (interface{}, error) {
+	return uuid.Parse(raw)
+}
+
+// OneOf hanya menerima salah satu nilai yang disebutkan
+func OneOf(values ...string) func(string) (interface{}, error) {
+	return func(raw string) (interface{}, error) {
+		for _, v := range values {
+			if raw == v {
+				return raw, nil
+			}
+		}
+		return nil, errors.New("harus salah satu dari " + strings.Join(values, ", "))
+	}
+}
+
+// applySearch mencari setiap kata di ?q= sebagai prefix (full-text, konfigurasi 'simple'
+// agar nama tidak di-stemming). Ekspresi to_tsvector harus sama dengan index GIN di migrasi.
+func (p *Params) applySearch(db *gorm.DB) *gorm.DB {
+	words := strings.FieldsFunc(strings.ToLower(p.Search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return db
+	}
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+
+	columns := make([]string, len(p.spec.Search))
+	for i, col := range p.spec.Search {
+		columns[i] = "coalesce(" + col + ", '')"
+	}
+	document := "to_tsvector('simple', " + strings.Join(columns, " || ' ' || ") + ")"
+	return db.Where(document+" @@ to_tsquery('simple', ?)", strings.Join(words, " & "))
+}
@@ -0,0 +1,157 @@
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Meta adalah metadata pagination pada envelope standar
+type Meta struct {
+	Page       int    `json:"page,omitempty"` // kosong pada mode cursor
+	PerPage    int    `json:"per_page"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Response adalah envelope standar untuk endpoint listing
+func Response(message string, data interface{}, meta Meta) fiber.Map {
+	return fiber.Map{
+		"message": message,
+		"data":    data,
+		"meta":    meta,
+	}
+}
+
+// Find menerapkan filter, pencarian, urutan dan pagination ke db (yang sudah berisi
+// Model / kondisi dasar) lalu mengisi dest (*[]T). Dengan ?cursor= halaman diambil
+// setelah baris terakhir halaman sebelumnya (keyset), selain itu memakai offset ?page=.
+func (p *Params) Find(db *gorm.DB, dest interface{}) (Meta, error) {
+	meta := Meta{PerPage: p.PerPage}
+
+	scoped := db
+	for _, f := range p.filters {
+		scoped = f.apply(scoped)
+	}
+	if p.Search != "" {
+		scoped = p.applySearch(scoped)
+	}
+	scoped = scoped.Session(&gorm.Session{})
+
+	if err := scoped.Count(&meta.Total).Error; err != nil {
+		return meta, err
+	}
+	meta.TotalPages = int((meta.Total + int64(p.PerPage) - 1) / int64(p.PerPage))
+
+	find := scoped
+	for _, s := range p.sorts {
+		find = find.Order(clause.OrderByColumn{Column: clause.Column{Name: s.Column}, Desc: s.Desc})
+	}
+	if p.cursor != nil {
+		find = p.afterCursor(find)
+	} else {
+		meta.Page = p.Page
+		find = find.Offset((p.Page - 1) * p.PerPage)
+	}
+	// Ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya
+	if err := find.Limit(p.PerPage + 1).Find(dest).Error; err != nil {
+		return meta, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.Len() > p.PerPage {
+		rows.SetLen(p.PerPage)
+		meta.HasMore = true
+		cursor, err := p.nextCursor(db, dest, rows.Index(rows.Len()-1))
+		if err != nil {
+			return meta, err
+		}
+		meta.NextCursor = cursor
+	}
+	return meta, nil
+}
+
+// afterCursor: (a > v1) OR (a = v1 AND b > v2) OR ... sesuai arah setiap kolom
+func (p *Params) afterCursor(db *gorm.DB) *gorm.DB {
+	var (
+		ors  []string
+		args []interface{}
+	)
+	for i, s := range p.sorts {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, p.sorts[j].Column+" = ?")
+			args = append(args, p.cursor[j])
+		}
+		op := " > ?"
+		if s.Desc {
+			op = " < ?"
+		}
+		ands = append(ands, s.Column+op)
+		args = append(args, p.cursor[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return db.Where("("+strings.Join(ors, " OR ")+")", args...)
+}
+
+type cursorPayload struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// nextCursor menyimpan nilai kolom urutan dari baris terakhir halaman ini
+func (p *Params) nextCursor(db *gorm.DB, dest interface{}, last reflect.Value) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return "", err
+	}
+	for last.Kind() == reflect.Ptr {
+		last = last.Elem()
+	}
+
+	values := make([]interface{}, len(p.sorts))
+	for i, s := range p.sorts {
+		field := stmt.Schema.LookUpField(s.Column)
+		if field == nil {
+			return "", fmt.Errorf("query: kolom %s tidak ada di %s", s.Column, stmt.Schema.Name)
+		}
+		values[i], _ = field.ValueOf(context.Background(), last)
+	}
+
+	raw, err := json.Marshal(cursorPayload{Sort: p.sortKey, Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(cursor, sortKey string, n int) ([]interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid("cursor tidak valid")
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || len(payload.Values) != n {
+		return nil, invalid("cursor tidak valid")
+	}
+	if payload.Sort != sortKey {
+		return nil, invalid("cursor dibuat untuk sort yang berbeda")
+	}
+	for _, v := range payload.Values {
+		switch v.(type) {
+		case string, float64, bool:
+		default:
+			return nil, invalid("cursor tidak valid")
+		}
+	}
+	return payload.Values, nil
+}
@@ -0,0 +1,411 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ============================ fake database ============================
+// itemStore adalah driver database/sql minimal di memori untuk tabel items: cukup untuk
+// query yang dibuat Find (COUNT, ORDER BY, kondisi keyset dari afterCursor, LIMIT / OFFSET).
+
+type item struct {
+	ID   int64
+	Rank int64
+	Name string
+}
+
+type itemStore struct {
+	items   []item
+	queries []string
+}
+
+var (
+	orderColumn  = regexp.MustCompile(`"?(\w+)"?( DESC)?`)
+	keysetTerm   = regexp.MustCompile(`(\w+) (=|<|>) \$(\d+)`)
+	limitOffset  = regexp.MustCompile(`(LIMIT|OFFSET) \$(\d+)`)
+	itemColumns  = []string{"id", "rank", "name"}
+	errNoSupport = errors.New("query tidak didukung")
+)
+
+func (s *itemStore) Connect(context.Context) (driver.Conn, error) { return &itemConn{s}, nil }
+func (s *itemStore) Driver() driver.Driver                        { return nil }
+
+type itemConn struct{ s *itemStore }
+
+func (c *itemConn) Prepare(string) (driver.Stmt, error) { return nil, errNoSupport }
+func (c *itemConn) Close() error                        { return nil }
+func (c *itemConn) Begin() (driver.Tx, error)           { return nil, errNoSupport }
+
+func (c *itemConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.s.queries = append(c.s.queries, query)
+	if !strings.Contains(query, `FROM "items"`) {
+		return nil, fmt.Errorf("%w: %s", errNoSupport, query)
+	}
+
+	rows := make([]item, 0, len(c.s.items))
+	for _, it := range c.s.items {
+		ok, err := matchKeyset(query, args, it)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rows = append(rows, it)
+		}
+	}
+	if strings.HasPrefix(query, "SELECT count(*)") {
+		return &itemRows{columns: []string{"count"}, values: [][]driver.Value{{int64(len(rows))}}}, nil
+	}
+
+	if i := strings.Index(query, "ORDER BY "); i >= 0 {
+		order := query[i+len("ORDER BY "):]
+		if j := strings.Index(order, " LIMIT"); j >= 0 {
+			order = order[:j]
+		}
+		var keys [][]string
+		for _, part := range strings.Split(order, ",") {
+			keys = append(keys, orderColumn.FindStringSubmatch(strings.TrimSpace(part)))
+		}
+		sort.SliceStable(rows, func(a, b int) bool {
+			for _, k := range keys {
+				if c := compare(column(rows[a], k[1]), column(rows[b], k[1])); c != 0 {
+					return (c < 0) != (k[2] != "")
+				}
+			}
+			return false
+		})
+	}
+	limit, offset := len(rows), 0
+	for _, m := range limitOffset.FindAllStringSubmatch(query, -1) {
+		pos, _ := strconv.Atoi(m[2])
+		if n := int(args[pos-1].Value.(int64)); m[1] == "OFFSET" {
+			offset = n
+		} else {
+			limit = n
+		}
+	}
+	rows = rows[min(offset, len(rows)):]
+	rows = rows[:min(limit, len(rows))]
+
+	result := &itemRows{columns: itemColumns}
+	for _, it := range rows {
+		result.values = append(result.values, []driver.Value{it.ID, it.Rank, it.Name})
+	}
+	return result, nil
+}
+
+// matchKeyset mengevaluasi WHERE ((a < $1) OR (a = $2 AND id < $3)) dari afterCursor
+func matchKeyset(query string, args []driver.NamedValue, it item) (bool, error) {
+	i := strings.Index(query, "WHERE ")
+	if i < 0 {
+		return true, nil
+	}
+	where := query[i+len("WHERE "):]
+	if j := strings.Index(where, " ORDER BY"); j >= 0 {
+		where = where[:j]
+	}
+	for _, group := range strings.Split(where, " OR ") {
+		terms := keysetTerm.FindAllStringSubmatch(group, -1)
+		if len(terms) == 0 {
+			return false, fmt.Errorf("%w: WHERE %s", errNoSupport, where)
+		}
+		match := true
+		for _, term := range terms {
+			pos, _ := strconv.Atoi(term[3])
+			c := compare(column(it, term[1]), args[pos-1].Value)
+			switch term[2] {
+			case "=":
+				match = match && c == 0
+			case "<":
+				match = match && c < 0
+			case ">":
+				match = match && c > 0
+			}
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func column(it item, name string) interface{} {
+	switch name {
+	case "id":
+		return it.ID
+	case "rank":
+		return it.Rank
+	default:
+		return it.Name
+	}
+}
+
+// compare membandingkan nilai kolom dengan nilai lain (int64 dari tabel atau float64 dari cursor)
+func compare(a, b interface{}) int {
+	if s, ok := a.(string); ok {
+		return strings.Compare(s, fmt.Sprint(b))
+	}
+	x, y := toFloat(a), toFloat(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	f, _ := strconv.ParseFloat(fmt.Sprint(v), 64)
+	return f
+}
+
+type itemRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *itemRows) Columns() []string { return r.columns }
+func (r *itemRows) Close() error      { return nil }
+func (r *itemRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// ============================ helpers ============================
+
+var itemSpec = Spec{
+	Key:         "id",
+	Sorts:       map[string]string{"id": "id", "rank": "rank", "name": "name"},
+	DefaultSort: "-rank",
+}
+
+type page struct {
+	Data []item `json:"data"`
+	Meta Meta   `json:"meta"`
+}
+
+// newItemApp memasang GET /items yang memakai Parse + Find di atas itemStore
+func newItemApp(t *testing.T, items []item) (*fiber.App, *itemStore) {
+	t.Helper()
+	store := &itemStore{items: items}
+	sqlDB := sql.OpenDB(store)
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/items", func(c *fiber.Ctx) error {
+		params, err := Parse(c, itemSpec)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		var rows []item
+		meta, err := params.Find(db.Model(&item{}), &rows)
+		if err != nil {
+			return err
+		}
+		return c.JSON(Response("ok", rows, meta))
+	})
+	return app, store
+}
+
+func getPage(t *testing.T, app *fiber.App, target string) page {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", target, resp.StatusCode, body)
+	}
+	var p page
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+	return p
+}
+
+func encodeRaw(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func encodeCursor(t *testing.T, payload cursorPayload) string {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func ids(items []item) []int64 {
+	out := make([]int64, len(items))
+	for i, it := range items {
+		out[i] = it.ID
+	}
+	return out
+}
+
+// ============================ tests ============================
+
+// TestFindCursorWalk: menelusuri semua halaman lewat next_cursor harus mengembalikan setiap
+// baris tepat sekali, dalam urutan yang sama dengan sort, termasuk saat nilai sort kembar
+// (dipecah oleh Key)
+func TestFindCursorWalk(t *testing.T) {
+	// rank kembar di beberapa batas halaman; id sengaja tidak urut dengan posisi
+	items := []item{
+		{7, 3, "g"}, {2, 3, "b"}, {9, 3, "i"}, {4, 2, "d"}, {1, 2, "a"},
+		{8, 2, "h"}, {3, 2, "c"}, {5, 1, "e"}, {6, 1, "f"}, {10, 1, "j"},
+	}
+
+	cases := []struct {
+		sort    string
+		perPage int
+		want    []int64
+	}{
+		{"", 3, []int64{9, 7, 2, 8, 4, 3, 1, 10, 6, 5}}, // default -rank, lalu -id
+		{"-rank", 4, []int64{9, 7, 2, 8, 4, 3, 1, 10, 6, 5}},
+		{"rank", 3, []int64{5, 6, 10, 1, 3, 4, 8, 2, 7, 9}}, // rank naik, lalu id naik
+		{"rank,-id", 2, []int64{10, 6, 5, 8, 4, 3, 1, 9, 7, 2}},
+		{"name", 4, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"-id", 3, []int64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}},
+		{"rank", 10, []int64{5, 6, 10, 1, 3, 4, 8, 2, 7, 9}},
+		{"rank", 100, []int64{5, 6, 10, 1, 3, 4, 8, 2, 7, 9}},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("sort=%s/per_page=%d", tc.sort, tc.perPage), func(t *testing.T) {
+			app, _ := newItemApp(t, items)
+
+			var got []int64
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > len(items) {
+					t.Fatalf("cursor tidak berhenti, sudah %d halaman: %v", pages, got)
+				}
+				target := fmt.Sprintf("/items?per_page=%d&sort=%s", tc.perPage, tc.sort)
+				if cursor != "" {
+					target += "&cursor=" + cursor
+				}
+				p := getPage(t, app, target)
+				if p.Meta.Total != int64(len(items)) || p.Meta.Page != 0 && cursor != "" {
+					t.Errorf("meta = %+v", p.Meta)
+				}
+				got = append(got, ids(p.Data)...)
+				if p.Meta.HasMore != (p.Meta.NextCursor != "") {
+					t.Fatalf("has_more = %v tetapi next_cursor = %q", p.Meta.HasMore, p.Meta.NextCursor)
+				}
+				if !p.Meta.HasMore {
+					break
+				}
+				cursor = p.Meta.NextCursor
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("urutan = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestFindCursorRoundTrip: next_cursor menyimpan nilai kolom sort baris terakhir
+// (termasuk Key) dan terikat pada sort yang dipakai
+func TestFindCursorRoundTrip(t *testing.T) {
+	items := []item{{1, 5, "a"}, {2, 5, "b"}, {3, 4, "c"}}
+
+	cases := []struct {
+		sort       string
+		wantSort   string
+		wantValues []interface{}
+	}{
+		{"-rank", "-rank", []interface{}{float64(5), float64(1)}}, // 2 lalu 1 (id ikut descending)
+		{"rank,name", "rank,name", []interface{}{float64(5), "a", float64(1)}},
+		{"name", "name", []interface{}{"b", float64(2)}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.sort, func(t *testing.T) {
+			app, _ := newItemApp(t, items)
+			p := getPage(t, app, "/items?per_page=2&sort="+tc.sort)
+			if !p.Meta.HasMore {
+				t.Fatalf("harus ada halaman berikutnya: %+v", p.Meta)
+			}
+
+			raw, err := base64.RawURLEncoding.DecodeString(p.Meta.NextCursor)
+			if err != nil {
+				t.Fatalf("cursor bukan base64url: %v", err)
+			}
+			var payload cursorPayload
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				t.Fatalf("cursor bukan JSON: %v", err)
+			}
+			if payload.Sort != tc.wantSort || fmt.Sprint(payload.Values) != fmt.Sprint(tc.wantValues) {
+				t.Errorf("cursor = %+v, want sort %q values %v", payload, tc.wantSort, tc.wantValues)
+			}
+
+			values, err := decodeCursor(p.Meta.NextCursor, tc.wantSort, len(tc.wantValues))
+			if err != nil || fmt.Sprint(values) != fmt.Sprint(tc.wantValues) {
+				t.Errorf("decodeCursor = %v, %v", values, err)
+			}
+		})
+	}
+}
+
+func TestFindOffset(t *testing.T) {
+	items := []item{{1, 1, "a"}, {2, 1, "b"}, {3, 1, "c"}, {4, 1, "d"}, {5, 1, "e"}}
+	app, store := newItemApp(t, items)
+
+	cases := []struct {
+		target     string
+		want       []int64
+		wantMore   bool
+		wantTotalP int
+	}{
+		{"/items?sort=id&per_page=2", []int64{1, 2}, true, 3},
+		{"/items?sort=id&per_page=2&page=2", []int64{3, 4}, true, 3},
+		{"/items?sort=id&per_page=2&page=3", []int64{5}, false, 3},
+		{"/items?sort=id&per_page=2&page=4", []int64{}, false, 3},
+		// Nilai sort kembar: urutan tetap stabil karena Key (id) ikut sebagai pemecah seri
+		{"/items?sort=-rank&per_page=5", []int64{5, 4, 3, 2, 1}, false, 1},
+	}
+	for _, tc := range cases {
+		p := getPage(t, app, tc.target)
+		if fmt.Sprint(ids(p.Data)) != fmt.Sprint(tc.want) || p.Meta.HasMore != tc.wantMore || p.Meta.TotalPages != tc.wantTotalP {
+			t.Errorf("%s: data = %v, meta = %+v; want %v, has_more %v, total_pages %d",
+				tc.target, ids(p.Data), p.Meta, tc.want, tc.wantMore, tc.wantTotalP)
+		}
+	}
+
+	last := store.queries[len(store.queries)-1]
+	if !strings.Contains(last, `ORDER BY "rank" DESC,"id" DESC`) {
+		t.Errorf("query terakhir harus diurutkan dengan pemecah seri id: %s", last)
+	}
+}
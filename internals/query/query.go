@@ -0,0 +1,153 @@
+// Package query membaca parameter listing standar dari query string
+// (?page=&per_page=, ?cursor=, ?sort=, filter yang di-whitelist, ?q= pencarian full-text)
+// lalu menerapkannya ke query GORM dan mengembalikan metadata pagination.
+//
+// Setiap endpoint mendefinisikan Spec; hanya kolom yang terdaftar di Spec yang bisa
+// dipakai untuk sort / filter, sehingga nilai dari client tidak pernah menjadi nama kolom.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// Spec adalah whitelist parameter listing untuk satu endpoint
+type Spec struct {
+	Key         string            // kolom unik pemecah seri urutan (biasanya "id")
+	Sorts       map[string]string // nama di ?sort= -> kolom
+	DefaultSort string            // mis. "-created_at"
+	Filters     map[string]Filter // nama query param -> filter
+	Search      []string          // kolom untuk ?q= (full-text, prefix per kata)
+	MaxPerPage  int               // default MaxPerPage
+}
+
+// Params adalah hasil Parse yang siap diterapkan lewat Find
+type Params struct {
+	Page    int
+	PerPage int
+	Search  string
+
+	spec    *Spec
+	sorts   []sortField
+	sortKey string        // bentuk normal ?sort=, diikat ke cursor
+	cursor  []interface{} // nil = pagination offset (page)
+	filters []appliedFilter
+}
+
+type sortField struct {
+	Column string
+	Desc   bool
+}
+
+// Error adalah kesalahan parameter dari client (400)
+type Error struct {
+	msg string
+}
+
+func (e *Error) Error() string { return e.msg }
+
+func invalid(format string, args ...interface{}) error {
+	return &Error{msg: fmt.Sprintf(format, args...)}
+}
+
+// IsInvalid bernilai true untuk error karena parameter client tidak valid
+func IsInvalid(err error) bool {
+	var e *Error
+	return errors.As(err, &e)
+}
+
+// Parse membaca parameter listing dari request sesuai spec. Error yang dikembalikan
+// selalu *Error (parameter tidak valid, kirim 400).
+func Parse(c *fiber.Ctx, spec Spec) (*Params, error) {
+	maxPerPage := spec.MaxPerPage
+	if maxPerPage <= 0 {
+		maxPerPage = MaxPerPage
+	}
+	p := &Params{Page: 1, PerPage: DefaultPerPage, spec: &spec}
+	if p.PerPage > maxPerPage {
+		p.PerPage = maxPerPage
+	}
+
+	if q := c.Query("page"); q != "" {
+		page, err := strconv.Atoi(q)
+		if err != nil || page < 1 {
+			return nil, invalid("page harus angka >= 1")
+		}
+		p.Page = page
+	}
+	if q := c.Query("per_page"); q != "" {
+		perPage, err := strconv.Atoi(q)
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			return nil, invalid("per_page harus 1-%d", maxPerPage)
+		}
+		p.PerPage = perPage
+	}
+
+	if err := p.parseSort(c.Query("sort", spec.DefaultSort)); err != nil {
+		return nil, err
+	}
+	if q := c.Query("cursor"); q != "" {
+		values, err := decodeCursor(q, p.sortKey, len(p.sorts))
+		if err != nil {
+			return nil, err
+		}
+		p.cursor = values
+	}
+
+	for name, filter := range spec.Filters {
+		raw := strings.TrimSpace(c.Query(name))
+		if raw == "" {
+			continue
+		}
+		value, err := filter.parse(raw)
+		if err != nil {
+			return nil, invalid("filter %s tidak valid: %v", name, err)
+		}
+		p.filters = append(p.filters, appliedFilter{Filter: filter, value: value})
+	}
+
+	if len(spec.Search) > 0 {
+		p.Search = strings.TrimSpace(c.Query("q"))
+	}
+	return p, nil
+}
+
+// parseSort membaca "-created_at,user_name" (awalan "-" = descending) lalu
+// menambahkan spec.Key sebagai pemecah seri agar urutan (dan cursor) selalu stabil
+func (p *Params) parseSort(raw string) error {
+	seen := map[string]bool{}
+	var names []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, desc := strings.TrimPrefix(part, "-"), strings.HasPrefix(part, "-")
+		column, ok := p.spec.Sorts[name]
+		if !ok {
+			return invalid("sort %q tidak didukung", name)
+		}
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		p.sorts = append(p.sorts, sortField{Column: column, Desc: desc})
+		names = append(names, part)
+	}
+
+	if key := p.spec.Key; key != "" && !seen[key] {
+		desc := len(p.sorts) > 0 && p.sorts[len(p.sorts)-1].Desc
+		p.sorts = append(p.sorts, sortField{Column: key, Desc: desc})
+	}
+	p.sortKey = strings.Join(names, ",")
+	return nil
+}
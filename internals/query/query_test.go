@@ -0,0 +1,125 @@
+package query
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var testSpec = Spec{
+	Key: "id",
+	Sorts: map[string]string{
+		"id":         "id",
+		"rank":       "rank",
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]Filter{
+		"user_id":       {Column: "user_id", Op: Eq, Parse: ParseUUID},
+		"gender":        {Column: "gender", Op: Eq, Parse: OneOf("male", "female")},
+		"name":          {Column: "name", Op: Prefix},
+		"created_after": {Column: "created_at", Op: Gte, Parse: ParseTime},
+	},
+	Search:     []string{"name"},
+	MaxPerPage: 50,
+}
+
+// parse menjalankan Parse untuk query string target di dalam handler Fiber
+func parse(t *testing.T, spec Spec, target string) (*Params, error) {
+	t.Helper()
+	var (
+		params *Params
+		err    error
+	)
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		params, err = Parse(c, spec)
+		return nil
+	})
+	if _, testErr := app.Test(httptest.NewRequest("GET", target, nil)); testErr != nil {
+		t.Fatalf("app.Test: %v", testErr)
+	}
+	return params, err
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name        string
+		target      string
+		wantPage    int
+		wantPerPage int
+		wantSorts   []sortField
+		wantFilters int
+		wantSearch  string
+	}{
+		{"default", "/", 1, DefaultPerPage, []sortField{{"created_at", true}, {"id", true}}, 0, ""},
+		{"page & per_page", "/?page=3&per_page=50", 3, 50, []sortField{{"created_at", true}, {"id", true}}, 0, ""},
+		{"sort naik", "/?sort=name", 1, DefaultPerPage, []sortField{{"name", false}, {"id", false}}, 0, ""},
+		{"sort ganda", "/?sort=-rank,name", 1, DefaultPerPage, []sortField{{"rank", true}, {"name", false}, {"id", false}}, 0, ""},
+		{"sort duplikat", "/?sort=rank,-rank", 1, DefaultPerPage, []sortField{{"rank", false}, {"id", false}}, 0, ""},
+		{"key eksplisit", "/?sort=-id", 1, DefaultPerPage, []sortField{{"id", true}}, 0, ""},
+		{"filter", "/?gender=female&name=ahm&created_after=2026-01-01&unknown=x", 1, DefaultPerPage,
+			[]sortField{{"created_at", true}, {"id", true}}, 3, ""},
+		{"filter kosong diabaikan", "/?gender=%20", 1, DefaultPerPage, []sortField{{"created_at", true}, {"id", true}}, 0, ""},
+		{"pencarian", "/?q=%20fulan%20", 1, DefaultPerPage, []sortField{{"created_at", true}, {"id", true}}, 0, "fulan"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := parse(t, testSpec, tc.target)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if p.Page != tc.wantPage || p.PerPage != tc.wantPerPage {
+				t.Errorf("page = %d, per_page = %d; want %d, %d", p.Page, p.PerPage, tc.wantPage, tc.wantPerPage)
+			}
+			if !reflect.DeepEqual(p.sorts, tc.wantSorts) {
+				t.Errorf("sorts = %v, want %v", p.sorts, tc.wantSorts)
+			}
+			if len(p.filters) != tc.wantFilters {
+				t.Errorf("filters = %d, want %d", len(p.filters), tc.wantFilters)
+			}
+			if p.Search != tc.wantSearch {
+				t.Errorf("search = %q, want %q", p.Search, tc.wantSearch)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	cursor := encodeCursor(t, cursorPayload{Sort: "-created_at", Values: []interface{}{"2026-01-01T00:00:00Z", float64(1)}})
+
+	cases := []struct {
+		name   string
+		target string
+	}{
+		{"page bukan angka", "/?page=dua"},
+		{"page nol", "/?page=0"},
+		{"per_page melebihi batas", "/?per_page=51"},
+		{"per_page nol", "/?per_page=0"},
+		{"sort tidak di whitelist", "/?sort=password"},
+		{"sort kolom mentah", "/?sort=-users.password"},
+		{"sort sebagian tidak dikenal", "/?sort=rank,email"},
+		{"filter uuid", "/?user_id=bukan-uuid"},
+		{"filter oneof", "/?gender=other"},
+		{"filter waktu", "/?created_after=kemarin"},
+		{"cursor bukan base64", "/?cursor=%25%25"},
+		{"cursor bukan json", "/?cursor=" + encodeRaw("bukan json")},
+		{"cursor jumlah nilai salah", "/?cursor=" + encodeCursor(t, cursorPayload{Sort: "-created_at", Values: []interface{}{"x"}})},
+		{"cursor nilai objek", "/?cursor=" + encodeCursor(t, cursorPayload{Sort: "-created_at", Values: []interface{}{map[string]interface{}{}, float64(1)}})},
+		{"cursor sort lain", "/?sort=name&cursor=" + cursor},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := parse(t, testSpec, tc.target)
+			if err == nil {
+				t.Fatalf("Parse harus gagal, dapat %+v", p)
+			}
+			if !IsInvalid(err) {
+				t.Errorf("error harus *Error (400), dapat %T: %v", err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval adalah jarak pembersihan key yang sudah tidak punya hit di MemoryStore
+const sweepInterval = time.Minute
+
+// MemoryStore menyimpan hit di memori proses. Cocok untuk satu replika; dengan beberapa
+// replika batasnya berlaku per replika, gunakan RedisStore.
+type MemoryStore struct {
+	mu      sync.Mutex
+	hits    map[string][]time.Time
+	windows map[string]time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{hits: map[string][]time.Time{}, windows: map[string]time.Duration{}}
+	go s.sweep()
+	return s
+}
+
+func (s *MemoryStore) Hit(ctx context.Context, key string, now time.Time, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hits := append(s.prune(key, now, window), now)
+	s.hits[key] = hits
+	s.windows[key] = window
+	return len(hits), hits[0], nil
+}
+
+func (s *MemoryStore) TryHit(ctx context.Context, key string, now time.Time, window time.Duration, limit int) (int, time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hits := s.prune(key, now, window)
+	if len(hits) >= limit {
+		if len(hits) == 0 {
+			return 0, time.Time{}, false, nil
+		}
+		return len(hits), hits[0], false, nil
+	}
+	hits = append(hits, now)
+	s.hits[key] = hits
+	s.windows[key] = window
+	return len(hits), hits[0], true, nil
+}
+
+func (s *MemoryStore) Peek(ctx context.Context, key string, now time.Time, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hits := s.prune(key, now, window)
+	if len(hits) == 0 {
+		return 0, time.Time{}, nil
+	}
+	return len(hits), hits[0], nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.hits, key)
+	delete(s.windows, key)
+	return nil
+}
+
+// prune membuang hit yang sudah di luar window; hit selalu urut waktu
+func (s *MemoryStore) prune(key string, now time.Time, window time.Duration) []time.Time {
+	hits := s.hits[key]
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	if i == len(hits) {
+		delete(s.hits, key)
+		delete(s.windows, key)
+		return nil
+	}
+	hits = hits[i:]
+	s.hits[key] = hits
+	return hits
+}
+
+func (s *MemoryStore) sweep() {
+	for range time.Tick(sweepInterval) {
+		now := time.Now()
+		s.mu.Lock()
+		for key, window := range s.windows {
+			s.prune(key, now, window)
+		}
+		s.mu.Unlock()
+	}
+}
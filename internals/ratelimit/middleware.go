@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"log"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"masjidku/internals/apperror"
+)
+
+// KeyFunc menentukan identitas yang dibatasi (IP, akun, token, ...). Key kosong = tidak dibatasi.
+type KeyFunc func(c *fiber.Ctx) string
+
+// MiddlewareConfig adalah konfigurasi Middleware
+type MiddlewareConfig struct {
+	Name    string // prefix key, mis. "login:ip"; wajib unik per rule
+	Rule    Rule
+	Key     KeyFunc // default ByIP
+	Message string  // pesan saat ditolak; kosong = pesan katalog too_many_requests (id / en)
+	// Required menolak request (400 invalid_body) jika Key kosong, alih-alih tidak dibatasi.
+	// Wajib untuk rule yang menjadi satu-satunya batas brute force (mis. per challenge token).
+	Required bool
+}
+
+// Middleware membatasi request dengan sliding window. Header X-RateLimit-* dan Retry-After
+// dikirim ke client. Jika store error, request tetap diteruskan (fail open) dan error dicatat.
+// Key kosong tidak dibatasi, kecuali cfg.Required.
+func (l *Limiter) Middleware(cfg MiddlewareConfig) fiber.Handler {
+	key := cfg.Key
+	if key == nil {
+		key = ByIP
+	}
+	return func(c *fiber.Ctx) error {
+		k := key(c)
+		if k == "" {
+			if cfg.Required {
+				return apperror.BadRequest(apperror.CodeInvalidBody)
+			}
+			return c.Next()
+		}
+
+		res, err := l.Allow(c.UserContext(), cfg.Name+":"+k, cfg.Rule)
+		if err != nil {
+			log.Printf("[RATELIMIT ERROR] %s: %v", cfg.Name, err)
+			return c.Next()
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		if !res.Allowed {
+			return TooManyRequests(c, cfg.Message, res)
+		}
+		return c.Next()
+	}
+}
+
+// TooManyRequests memasang header Retry-After (detik) dan mengembalikan error 429
+// (ditulis apperror.ErrorHandler, dengan member retry_after)
+func TooManyRequests(c *fiber.Ctx, message string, res Result) error {
+	retry := int(math.Ceil(res.RetryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retry))
+	e := apperror.TooManyRequests(apperror.CodeTooManyRequests).With("retry_after", retry)
+	if message != "" {
+		e.WithDetail("%s", message)
+	}
+	return e
+}
+
+// ByIP membatasi per alamat IP client
+func ByIP(c *fiber.Ctx) string {
+	return c.IP()
+}
+
+// ByBodyField membatasi per nilai field di body (lowercase, spasi dibuang), mis. "email"
+// atau "identifier". Body dibaca dengan c.BodyParser seperti di controller (JSON dengan key
+// case-insensitive, form, multipart, XML), sehingga key tidak bisa dikosongkan hanya dengan
+// mengganti Content-Type. Field di struct input controller harus bertag json & form yang sama.
+func ByBodyField(field string) KeyFunc {
+	tag := reflect.StructTag(`json:"` + field + `" form:"` + field + `" xml:"` + field + `"`)
+	typ := reflect.StructOf([]reflect.StructField{{Name: "Value", Type: reflect.TypeOf(""), Tag: tag}})
+	return func(c *fiber.Ctx) string {
+		body := reflect.New(typ)
+		if err := c.BodyParser(body.Interface()); err != nil {
+			return ""
+		}
+		return strings.ToLower(strings.TrimSpace(body.Elem().Field(0).String()))
+	}
+}
+
+// Join menggabungkan beberapa KeyFunc menjadi satu key, mis. Join(ByIP, ByBodyField("email"))
+func Join(keys ...KeyFunc) KeyFunc {
+	return func(c *fiber.Ctx) string {
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = key(c)
+		}
+		return strings.Join(parts, "|")
+	}
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"masjidku/internals/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestByBodyField(t *testing.T) {
+	multipartBody := func(field, value string) (string, string) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		_ = w.WriteField(field, value)
+		_ = w.Close()
+		return buf.String(), w.FormDataContentType()
+	}
+	mp, mpType := multipartBody("email", "Fulan@Example.com")
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"json", fiber.MIMEApplicationJSON, `{"email":" Fulan@Example.com "}`, "fulan@example.com"},
+		{"json key huruf besar", fiber.MIMEApplicationJSON, `{"EMAIL":"fulan@example.com"}`, "fulan@example.com"},
+		{"json charset", fiber.MIMEApplicationJSONCharsetUTF8, `{"email":"fulan@example.com"}`, "fulan@example.com"},
+		{"form", fiber.MIMEApplicationForm, "email=Fulan%40Example.com&x=1", "fulan@example.com"},
+		{"multipart", mpType, mp, "fulan@example.com"},
+		{"xml", fiber.MIMEApplicationXML, `<body><email>fulan@example.com</email></body>`, "fulan@example.com"},
+		{"field tidak ada", fiber.MIMEApplicationJSON, `{"identifier":"fulan"}`, ""},
+		{"bukan string", fiber.MIMEApplicationJSON, `{"email":42}`, ""},
+		{"json rusak", fiber.MIMEApplicationJSON, `{"email":`, ""},
+		{"content-type tidak dikenal", "text/plain", "email=fulan@example.com", ""},
+	}
+	key := ByBodyField("email")
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				got = key(c)
+				return nil
+			})
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			if _, err := app.Test(req); err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("ByBodyField = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	newApp := func(required bool) *fiber.App {
+		l := NewLimiter(NewMemoryStore())
+		app := fiber.New(fiber.Config{ErrorHandler: apperror.ErrorHandler})
+		app.Post("/", l.Middleware(MiddlewareConfig{
+			Name:     "test",
+			Rule:     Rule{Limit: 1, Window: time.Minute},
+			Key:      ByBodyField("token"),
+			Required: required,
+		}), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+		return app
+	}
+	post := func(app *fiber.App, body string) (int, string) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationForm)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter)
+	}
+
+	app := newApp(false)
+	if status, _ := post(app, "token=a"); status != fiber.StatusNoContent {
+		t.Fatalf("hit pertama: status %d", status)
+	}
+	if status, retry := post(app, "token=a"); status != fiber.StatusTooManyRequests || retry != "60" {
+		t.Errorf("hit kedua: status %d, Retry-After %q", status, retry)
+	}
+	for i := 0; i < 3; i++ {
+		if status, _ := post(app, "lain=a"); status != fiber.StatusNoContent {
+			t.Errorf("key kosong tanpa Required harus dilewatkan, status %d", status)
+		}
+	}
+	if status, _ := post(newApp(true), "lain=a"); status != fiber.StatusBadRequest {
+		t.Errorf("key kosong dengan Required: status %d, want 400", status)
+	}
+}
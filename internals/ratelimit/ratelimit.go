@@ -0,0 +1,113 @@
+// Package ratelimit menyediakan rate limiter sliding window (log) dengan store yang bisa
+// diganti (memory untuk satu replika, redis untuk banyak replika), beserta middleware Fiber.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"masjidku/internals/configs"
+)
+
+// Store menyimpan waktu hit per key. Implementasi wajib membuang hit yang sudah di luar window.
+type Store interface {
+	// Hit mencatat satu hit lalu mengembalikan jumlah hit dalam window (termasuk hit ini)
+	// dan waktu hit tertua yang masih di dalam window
+	Hit(ctx context.Context, key string, now time.Time, window time.Duration) (int, time.Time, error)
+	// Peek sama dengan Hit tetapi tidak mencatat hit baru
+	Peek(ctx context.Context, key string, now time.Time, window time.Duration) (int, time.Time, error)
+	// TryHit mencatat satu hit hanya jika jumlah hit dalam window masih di bawah limit.
+	// Pengecekan dan pencatatan wajib satu operasi atomik, sehingga request paralel tidak
+	// bisa sama-sama lolos. Mengembalikan jumlah hit (termasuk hit ini jika dicatat),
+	// waktu hit tertua, dan apakah hit dicatat.
+	TryHit(ctx context.Context, key string, now time.Time, window time.Duration, limit int) (int, time.Time, bool, error)
+	// Reset menghapus semua hit milik key
+	Reset(ctx context.Context, key string) error
+}
+
+// Rule adalah batas Limit hit per Window (sliding)
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Result adalah hasil pengecekan limiter
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // > 0 jika ditolak
+}
+
+// Limiter mengecek dan mencatat hit terhadap Rule
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// New membuat Limiter sesuai RATE_LIMIT_DRIVER (memory | redis)
+func New(cfg *configs.Config) (*Limiter, error) {
+	switch cfg.RateLimit.Driver {
+	case "memory":
+		return NewLimiter(NewMemoryStore()), nil
+	case "redis":
+		store, err := NewRedisStore(cfg.Redis.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewLimiter(store), nil
+	default:
+		return nil, fmt.Errorf("ratelimit: driver tidak dikenal %q", cfg.RateLimit.Driver)
+	}
+}
+
+// Allow mencatat hit jika masih di bawah batas. Hit yang ditolak tidak dicatat,
+// sehingga client yang menunggu RetryAfter pasti bisa mencoba lagi.
+func (l *Limiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := l.now()
+	count, oldest, ok, err := l.store.TryHit(ctx, key, now, rule.Window, rule.Limit)
+	if err != nil {
+		return Result{}, err
+	}
+	if !ok {
+		return Result{Limit: rule.Limit, RetryAfter: retryAfter(oldest, rule.Window, now)}, nil
+	}
+	return Result{Allowed: true, Limit: rule.Limit, Remaining: max(rule.Limit-count, 0)}, nil
+}
+
+// Check seperti Allow tetapi tidak mencatat hit (mis. untuk menghitung kegagalan saja lewat Hit)
+func (l *Limiter) Check(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := l.now()
+	count, oldest, err := l.store.Peek(ctx, key, now, rule.Window)
+	if err != nil {
+		return Result{}, err
+	}
+	if count >= rule.Limit {
+		return Result{Limit: rule.Limit, RetryAfter: retryAfter(oldest, rule.Window, now)}, nil
+	}
+	return Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit - count}, nil
+}
+
+// Hit mencatat satu hit tanpa pengecekan batas
+func (l *Limiter) Hit(ctx context.Context, key string, rule Rule) error {
+	_, _, err := l.store.Hit(ctx, key, l.now(), rule.Window)
+	return err
+}
+
+// Reset menghapus hit milik key (mis. setelah login berhasil)
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, key)
+}
+
+func retryAfter(oldest time.Time, window time.Duration, now time.Time) time.Duration {
+	wait := oldest.Add(window).Sub(now)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
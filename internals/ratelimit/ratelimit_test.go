@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestLimiter membuat Limiter dengan MemoryStore dan jam yang bisa digeser
+func newTestLimiter(start time.Time) (*Limiter, *time.Time) {
+	now := start
+	l := NewLimiter(NewMemoryStore())
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestAllowSlidingWindow(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	l, now := newTestLimiter(start)
+	rule := Rule{Limit: 3, Window: time.Minute}
+	ctx := context.Background()
+
+	steps := []struct {
+		at            time.Duration // sejak start
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{0, true, 2, 0},
+		{10 * time.Second, true, 1, 0},
+		{20 * time.Second, true, 0, 0},
+		{30 * time.Second, false, 0, 30 * time.Second},    // hit tertua (0s) keluar window pada 60s
+		{59500 * time.Millisecond, false, 0, time.Second}, // minimal 1 detik
+		{60 * time.Second, true, 0, 0},                    // hit 0s keluar; slot terisi lagi
+		{65 * time.Second, false, 0, 5 * time.Second},     // hit tertua sekarang 10s
+		{70 * time.Second, true, 0, 0},
+		{3 * time.Minute, true, 2, 0}, // semua hit sudah keluar window
+	}
+	for _, s := range steps {
+		*now = start.Add(s.at)
+		res, err := l.Allow(ctx, "k", rule)
+		if err != nil {
+			t.Fatalf("%v: Allow: %v", s.at, err)
+		}
+		if res.Allowed != s.wantAllowed || res.Remaining != s.wantRemaining || res.RetryAfter != s.wantRetry {
+			t.Errorf("%v: Allow = %+v, want allowed=%v remaining=%d retry=%v",
+				s.at, res, s.wantAllowed, s.wantRemaining, s.wantRetry)
+		}
+	}
+}
+
+func TestAllowRejectedHitsNotRecorded(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	l, now := newTestLimiter(start)
+	rule := Rule{Limit: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	if res, _ := l.Allow(ctx, "k", rule); !res.Allowed {
+		t.Fatal("hit pertama harus lolos")
+	}
+	// Client yang terus mencoba selama window tidak boleh memperpanjang hukumannya
+	for i := 1; i < 60; i++ {
+		*now = start.Add(time.Duration(i) * time.Second)
+		if res, _ := l.Allow(ctx, "k", rule); res.Allowed {
+			t.Fatalf("detik %d: harus ditolak", i)
+		}
+	}
+	*now = start.Add(time.Minute)
+	if res, _ := l.Allow(ctx, "k", rule); !res.Allowed {
+		t.Error("setelah RetryAfter lewat, hit harus lolos")
+	}
+}
+
+func TestAllowKeysIndependentAndReset(t *testing.T) {
+	l, _ := newTestLimiter(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	rule := Rule{Limit: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	l.Allow(ctx, "a", rule)
+	if res, _ := l.Allow(ctx, "b", rule); !res.Allowed {
+		t.Error("key lain tidak boleh ikut terbatas")
+	}
+	if res, _ := l.Allow(ctx, "a", rule); res.Allowed {
+		t.Fatal("hit kedua untuk a harus ditolak")
+	}
+	if err := l.Reset(ctx, "a"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if res, _ := l.Allow(ctx, "a", rule); !res.Allowed {
+		t.Error("setelah Reset, hit harus lolos")
+	}
+}
+
+func TestCheckAndHit(t *testing.T) {
+	l, _ := newTestLimiter(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	rule := Rule{Limit: 2, Window: time.Minute}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if res, _ := l.Check(ctx, "k", rule); !res.Allowed || res.Remaining != 2 {
+			t.Fatalf("Check tidak boleh mencatat hit: %+v", res)
+		}
+	}
+	l.Hit(ctx, "k", rule)
+	l.Hit(ctx, "k", rule)
+	if res, _ := l.Check(ctx, "k", rule); res.Allowed || res.RetryAfter != time.Minute {
+		t.Errorf("Check setelah 2 Hit = %+v", res)
+	}
+}
+
+// TestAllowConcurrent: cek & catat harus atomik, jadi dari banyak request paralel
+// hanya Limit yang lolos
+func TestAllowConcurrent(t *testing.T) {
+	l := NewLimiter(NewMemoryStore())
+	rule := Rule{Limit: 5, Window: time.Minute}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			res, err := l.Allow(context.Background(), "k", rule)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if got := allowed.Load(); got != int32(rule.Limit) {
+		t.Errorf("lolos %d request, want %d", got, rule.Limit)
+	}
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "masjidku:ratelimit:"
+
+// tryHitScript membuang hit lama, lalu mencatat hit baru hanya jika masih di bawah batas.
+// Dijalankan sebagai script agar cek & catat atomik di Redis.
+// KEYS[1] = key, ARGV = now (ms), window (ms), limit, member. Hasil = {dicatat, jumlah, tertua (ms)}
+var tryHitScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local recorded = 0
+if count < tonumber(ARGV[3]) then
+  redis.call('ZADD', KEYS[1], now, ARGV[4])
+  redis.call('PEXPIRE', KEYS[1], window)
+  count = count + 1
+  recorded = 1
+end
+local oldest = 0
+local first = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if #first > 0 then
+  oldest = tonumber(first[2])
+end
+return {recorded, count, oldest}
+`)
+
+// RedisStore menyimpan hit sebagai sorted set (score = waktu dalam milidetik),
+// sehingga batas berlaku bersama untuk semua replika
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: REDIS_URL tidak valid: %w", err)
+	}
+	return &RedisStore{client: redis.NewClient(opts)}, nil
+}
+
+func (s *RedisStore) Hit(ctx context.Context, key string, now time.Time, window time.Duration) (int, time.Time, error) {
+	return s.run(ctx, key, now, window, true)
+}
+
+func (s *RedisStore) Peek(ctx context.Context, key string, now time.Time, window time.Duration) (int, time.Time, error) {
+	return s.run(ctx, key, now, window, false)
+}
+
+func (s *RedisStore) TryHit(ctx context.Context, key string, now time.Time, window time.Duration, limit int) (int, time.Time, bool, error) {
+	nowMs := now.UnixMilli()
+	res, err := tryHitScript.Run(ctx, s.client, []string{redisKeyPrefix + key},
+		nowMs, window.Milliseconds(), limit, member(nowMs)).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, false, err
+	}
+	if len(res) != 3 {
+		return 0, time.Time{}, false, fmt.Errorf("ratelimit: hasil script tidak terduga %v", res)
+	}
+	var oldest time.Time
+	if res[1] > 0 {
+		oldest = time.UnixMilli(res[2])
+	}
+	return int(res[1]), oldest, res[0] == 1, nil
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, redisKeyPrefix+key).Err()
+}
+
+func (s *RedisStore) run(ctx context.Context, key string, now time.Time, window time.Duration, record bool) (int, time.Time, error) {
+	k := redisKeyPrefix + key
+	nowMs := now.UnixMilli()
+	cutoff := strconv.FormatInt(nowMs-window.Milliseconds(), 10)
+
+	pipe := s.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, k, "-inf", cutoff)
+	if record {
+		pipe.ZAdd(ctx, k, redis.Z{Score: float64(nowMs), Member: member(nowMs)})
+		pipe.PExpire(ctx, k, window)
+	}
+	card := pipe.ZCard(ctx, k)
+	first := pipe.ZRangeWithScores(ctx, k, 0, 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	count := int(card.Val())
+	if count == 0 || len(first.Val()) == 0 {
+		return count, time.Time{}, nil
+	}
+	return count, time.UnixMilli(int64(first.Val()[0].Score)), nil
+}
+
+// member harus unik agar dua hit di milidetik yang sama tetap terhitung dua
+func member(nowMs int64) string {
+	return strconv.FormatInt(nowMs, 10) + "-" + nonce()
+}
+
+func nonce() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,24 +1,59 @@
 package routes
 
 import (
-	
+	"log"
+
+	"masjidku/internals/audit"
+	"masjidku/internals/configs"
+	donationRoute "masjidku/internals/features/donations/donation/route"
+	masjidRoute "masjidku/internals/features/masjids/masjid/route"
+	prayerTimeRoute "masjidku/internals/features/prayertimes/prayertime/route"
+	auditRoute "masjidku/internals/features/users/audit/route"
 	userRoute "masjidku/internals/features/users/auth/route"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacRoute "masjidku/internals/features/users/rbac/route"
+	rbacService "masjidku/internals/features/users/rbac/service"
 	authRoute "masjidku/internals/features/users/user/route"
-
+	"masjidku/internals/mailer"
+	"masjidku/internals/ratelimit"
+	"masjidku/internals/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
-
 )
 
 // Register routes
-func SetupRoutes(app *fiber.App, db *gorm.DB) {
+func SetupRoutes(app *fiber.App, db *gorm.DB, cfg *configs.Config, tokens *authService.TokenService, policy *rbacService.Policy, store storage.Storage) {
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		log.Fatal("❌ Gagal inisialisasi mailer:", err)
+	}
+	limiter, err := ratelimit.New(cfg)
+	if err != nil {
+		log.Fatal("❌ Gagal inisialisasi rate limiter:", err)
+	}
+	recorder := audit.NewRecorder(db, cfg.Audit)
+
+	// 🎭 Setiap request admin yang login sebagai user lain dicatat di audit log
+	app.Use(recorder.ImpersonationTrail())
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Fiber & Supabase PostgreSQL connected successfully 🚀")
 	})
 
-	userRoute.AuthRoutes(app, db)
-	authRoute.UserRoutes(app, db)
+	// 🔗 Unduhan file lewat link bertanda tangan (driver storage local)
+	if local, ok := store.(*storage.Local); ok {
+		app.Get(storage.RoutePrefix+"*", local.Handler())
+	}
+
+	userRoute.AuthRoutes(app, db, cfg, tokens, policy, mail, limiter, recorder)
+	authRoute.UserRoutes(app, db, cfg, tokens, policy, recorder, mail, store)
+	// 🔓 Route publik di bawah /api/masjids harus didaftarkan sebelum MasjidRoutes,
+	// karena MasjidRoutes memasang AuthMiddleware untuk seluruh prefix /api/masjids
+	prayerTimeRoute.PrayerTimeRoutes(app, db, cfg, tokens, policy)
+	masjidRoute.MasjidRoutes(app, db, cfg, tokens, policy)
+	donationRoute.DonationRoutes(app, db, cfg, tokens, policy)
+	rbacRoute.RBACRoutes(app, db, cfg, tokens, policy, recorder)
+	auditRoute.AuditRoutes(app, db, cfg, tokens, policy)
 
 }
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoutePrefix adalah path unduhan file driver local
+const RoutePrefix = "/files/"
+
+// Local menyimpan file di folder Dir. Link unduhan ditandatangani HMAC-SHA256 atas key + expires,
+// sehingga tidak bisa diubah atau diperpanjang tanpa secret.
+type Local struct {
+	Dir     string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocal membuat storage di disk. baseURL adalah alamat publik API (kosong = link relatif).
+// Jika secret kosong, secret diturunkan dari JWT_SECRET dengan label khusus.
+func NewLocal(dir, baseURL, secret, jwtSecret string) *Local {
+	key := []byte(secret)
+	if len(key) == 0 {
+		derive := hmac.New(sha256.New, []byte(jwtSecret))
+		derive.Write([]byte("masjidku/storage-link"))
+		key = derive.Sum(nil)
+	}
+	return &Local{Dir: dir, baseURL: strings.TrimRight(baseURL, "/"), secret: key}
+}
+
+// Put menulis file lewat file sementara lalu rename, agar unduhan tidak pernah membaca file setengah jadi
+func (s *Local) Put(ctx context.Context, key string, r io.Reader) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return fmt.Errorf("storage: gagal membuat folder: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("storage: gagal membuat file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage: gagal menulis file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage: gagal menulis file: %w", err)
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *Local) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Local) SignedURL(key string, expiresAt time.Time) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	q := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.baseURL + RoutePrefix + key + "?" + q.Encode(), nil
+}
+
+// Handler melayani GET /files/* untuk link dari SignedURL; link kedaluwarsa / diubah ditolak 404
+// agar tidak membedakan file yang ada dan tidak ada
+func (s *Local) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, err := url.PathUnescape(c.Params("*"))
+		if err != nil {
+			return fiber.ErrNotFound
+		}
+		expires := c.Query("expires")
+		unix, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > unix ||
+			!hmac.Equal([]byte(c.Query("signature")), []byte(s.sign(key, expires))) {
+			return fiber.ErrNotFound
+		}
+		target, err := s.path(key)
+		if err != nil {
+			return fiber.ErrNotFound
+		}
+		if _, err := os.Stat(target); err != nil {
+			return fiber.ErrNotFound
+		}
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Download(target, path.Base(key))
+	}
+}
+
+func (s *Local) sign(key, expires string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path memetakan key ke file di dalam Dir; key absolut atau berisi ".." ditolak
+func (s *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if key == "" || clean != key {
+		return "", fmt.Errorf("storage: key %q tidak valid", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
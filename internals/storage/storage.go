@@ -0,0 +1,35 @@
+// Package storage menyimpan file yang dihasilkan aplikasi (mis. arsip ekspor data user)
+// dan membuat link unduhan yang kedaluwarsa. Driver local menyimpan file di disk dan
+// melayani unduhannya sendiri lewat route /files/*; driver lain cukup memenuhi interface Storage.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"masjidku/internals/configs"
+)
+
+// ErrNotFound: file dengan key tersebut tidak ada
+var ErrNotFound = errors.New("storage: file tidak ditemukan")
+
+// Storage menyimpan file berdasarkan key (path relatif, mis. "exports/<user>/<id>.zip")
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Delete(ctx context.Context, key string) error
+	// SignedURL membuat link unduhan yang hanya berlaku sampai expiresAt
+	SignedURL(key string, expiresAt time.Time) (string, error)
+}
+
+// New membuat storage sesuai konfigurasi STORAGE_DRIVER (saat ini: local)
+func New(cfg *configs.Config) (Storage, error) {
+	switch cfg.Storage.Driver {
+	case "", "local":
+		return NewLocal(cfg.Storage.Dir, cfg.Storage.PublicURL, cfg.Storage.SigningSecret, cfg.JWT.Secret), nil
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER %q tidak dikenal", cfg.Storage.Driver)
+	}
+}
@@ -1,33 +1,125 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"os"
+	"time"
+
+	"masjidku/internals/apperror"
+	"masjidku/internals/audit"
 	"masjidku/internals/configs"
 	"masjidku/internals/database"
+	"masjidku/internals/database/migrations"
+	"masjidku/internals/features/users/auth/revocation"
 	scheduler "masjidku/internals/features/users/auth/scheduler"
+	authService "masjidku/internals/features/users/auth/service"
+	rbacService "masjidku/internals/features/users/rbac/service"
+	userService "masjidku/internals/features/users/user/service"
 	routes "masjidku/internals/route"
+	"masjidku/internals/storage"
+	_ "time/tzdata" // zona waktu masjid (jadwal sholat) tetap bisa di-load di image tanpa tzdata
 
 	// "masjidku/internals/features/models"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 func main() {
 
-	// ✅ Muat file .env dulu
-	configs.LoadEnv()
-	// Inisialisasi Fiber
-	app := fiber.New()
+	// ✅ Muat konfigurasi (default, config.yaml, .env, ENV) sekali di awal
+	cfg := configs.MustLoad()
+
+	// Koneksi ke database
+	db := database.ConnectDB(cfg.Database)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("❌ Gagal mengambil koneksi SQL:", err)
+	}
+
+	// ✅ Subcommand: masjidku migrate up|down|status|force <version>
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrations.RunCommand(sqlDB, os.Args[2:]); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
+
+	// ✅ Migrasi otomatis saat boot (opsional)
+	if cfg.Database.MigrateOnBoot {
+		runner, err := migrations.NewRunner(sqlDB)
+		if err != nil {
+			log.Fatal("❌ Gagal memuat migrasi:", err)
+		}
+		if err := runner.Up(context.Background()); err != nil && !errors.Is(err, migrations.ErrNoChange) {
+			log.Fatal("❌ Migrasi gagal:", err)
+		}
+	}
+
+	// ✅ Signing key access token (RS256/EdDSA, dirotasi lewat `masjidku keys`)
+	keys, err := authService.NewKeySet(db, cfg.JWT)
+	if err != nil {
+		log.Fatal("❌ Gagal inisialisasi signing key:", err)
+	}
+
+	// ✅ Subcommand: masjidku keys list|generate|rotate|prune
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := authService.RunKeysCommand(keys, os.Args[2:]); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
+	if err := keys.EnsureSigningKey(context.Background()); err != nil {
+		log.Fatal("❌ Gagal memuat signing key:", err)
+	}
+	keys.StartRefresh(context.Background(), cfg.JWT.KeyRefreshInterval)
+
+	// ✅ Subcommand: masjidku audit verify
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		if err := audit.RunCommand(audit.NewRecorder(db, cfg.Audit), os.Args[2:]); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
+
+	// Inisialisasi Fiber; semua error handler ditulis sebagai problem+json (lihat apperror)
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.ErrorHandler})
+	// Setiap request mendapat X-Request-ID (dicatat di audit log)
+	app.Use(requestid.New())
+
+	// ✅ Penyimpanan token yang dicabut (cache LRU + bloom, sinkron antar replika)
+	revoked, err := revocation.New(context.Background(), db, cfg)
+	if err != nil {
+		log.Fatal("❌ Gagal inisialisasi revocation store:", err)
+	}
+	tokens := authService.NewTokenService(cfg.JWT, keys, revoked)
+
+	// ✅ Role & permission (RBAC) dari database
+	policy := rbacService.NewPolicy(db)
+	if err := policy.Load(context.Background()); err != nil {
+		log.Fatal("❌ Gagal memuat role & permission:", err)
+	}
 
-	// Koneksi ke Supabase
-	database.ConnectDB()
+	// ✅ Jalankan scheduler pembersihan token yang dicabut
+	scheduler.StartBlacklistCleanupScheduler(revoked, cfg.Revocation.CleanupInterval)
+	scheduler.StartLoginAttemptsCleanupScheduler(db, cfg.Auth.LoginAttemptsRetention, time.Hour)
+	// Akun yang dihapus user / admin di-purge setelah masa tenggang (ACCOUNT_DELETION_GRACE)
+	deletion := userService.NewAccountDeletion(db, tokens, audit.NewRecorder(db, cfg.Audit), cfg.Auth.AccountDeletionGrace)
+	scheduler.StartAccountPurgeScheduler(deletion, time.Hour)
 
-	// ✅ Jalankan scheduler harian
-	scheduler.StartBlacklistCleanupScheduler(database.DB)
+	// ✅ Penyimpanan file (arsip ekspor data user)
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatal("❌ Gagal inisialisasi storage:", err)
+	}
+	scheduler.StartDataExportCleanupScheduler(db, store, time.Hour)
 
 	// ✅ Panggil semua route dari folder routes
-	routes.SetupRoutes(app, database.DB)
+	routes.SetupRoutes(app, db, cfg, tokens, policy, store)
 
 	// Start server
-	log.Fatal(app.Listen(":3000"))
+	log.Fatal(app.Listen(cfg.App.ListenAddr))
 }